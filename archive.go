@@ -0,0 +1,364 @@
+package telemetry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// defaultArchiveInterval is how often archived telemetry is uploaded.
+	defaultArchiveInterval = 5 * time.Minute
+	// defaultArchiveMaxBytes is the uncompressed size at which archived
+	// telemetry is uploaded before the interval elapses.
+	defaultArchiveMaxBytes = 64 << 20
+	// archiveUploadTimeout bounds the uploads made on the interval.
+	archiveUploadTimeout = time.Minute
+)
+
+// ObjectStore uploads objects to a bucket, such as S3 (see
+// telemetryaws.NewS3Store) or Google Cloud Storage:
+//
+//	type gcsStore struct{ bucket *storage.BucketHandle }
+//
+//	func (s gcsStore) Upload(ctx context.Context, key string, data []byte) error {
+//		w := s.bucket.Object(key).NewWriter(ctx)
+//		if _, err := w.Write(data); err != nil {
+//			w.Close()
+//			return err
+//		}
+//		return w.Close()
+//	}
+type ObjectStore interface {
+	Upload(ctx context.Context, key string, data []byte) error
+}
+
+// ArchiveFormat is the format of the files of the archive exporters.
+type ArchiveFormat int
+
+const (
+	// ArchiveOTLP writes gzipped OTLP protobuf files, holding a TracesData,
+	// MetricsData or LogsData message, named *.binpb.gz.
+	ArchiveOTLP ArchiveFormat = iota
+	// ArchiveParquet writes gzip-compressed Parquet files, named *.parquet,
+	// with a row per span, log record or metric data point. Attributes,
+	// events, links and histogram buckets are JSON-encoded string columns.
+	ArchiveParquet
+)
+
+// ArchiveConfig configures the archive exporters.
+type ArchiveConfig struct {
+	// Store is where the files are uploaded.
+	Store ObjectStore
+	// Prefix is prepended to the object keys, e.g. "telemetry/checkout".
+	Prefix string
+	// Format is the format of the files, ArchiveOTLP by default.
+	Format ArchiveFormat
+	// Interval is how often buffered telemetry is uploaded, 5 minutes by
+	// default.
+	Interval time.Duration
+	// MaxBytes is the uncompressed size at which buffered telemetry is
+	// uploaded before the interval elapses, 64 MiB by default. Files whose
+	// upload failed are kept and uploaded again before the next ones, up to
+	// MaxBytes of compressed files; the oldest are dropped past that.
+	MaxBytes int
+}
+
+// ArchiveSpanExporter is a span exporter batching spans into gzipped OTLP
+// protobuf or Parquet files uploaded to an object store, for long-term
+// archival and offline analysis. The files are under
+// <prefix>/traces/<yyyy>/<mm>/<dd>/<hh>/. Pass it to WithSpanExporter.
+type ArchiveSpanExporter struct {
+	archive *archive[*tracepb.ResourceSpans]
+}
+
+// NewArchiveSpanExporter returns an exporter uploading spans as configured
+// by c.
+func NewArchiveSpanExporter(c ArchiveConfig) *ArchiveSpanExporter {
+	return &ArchiveSpanExporter{archive: newArchive(c, "traces", func(rs []*tracepb.ResourceSpans) proto.Message {
+		return &tracepb.TracesData{ResourceSpans: rs}
+	}, parquetSpans)}
+}
+
+// ExportSpans buffers spans, uploading them if the buffer is full.
+func (e *ArchiveSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	return e.archive.add(ctx, otlpTraces(spans).ResourceSpans)
+}
+
+// Shutdown uploads the buffered spans and stops the interval uploads.
+func (e *ArchiveSpanExporter) Shutdown(ctx context.Context) error {
+	return e.archive.shutdown(ctx)
+}
+
+// ArchiveLogExporter is a log exporter batching records into gzipped OTLP
+// protobuf or Parquet files uploaded to an object store. The files are under
+// <prefix>/logs/<yyyy>/<mm>/<dd>/<hh>/. Pass it to WithLogExporter.
+type ArchiveLogExporter struct {
+	archive *archive[*logspb.ResourceLogs]
+}
+
+// NewArchiveLogExporter returns an exporter uploading records as configured
+// by c.
+func NewArchiveLogExporter(c ArchiveConfig) *ArchiveLogExporter {
+	return &ArchiveLogExporter{archive: newArchive(c, "logs", func(rl []*logspb.ResourceLogs) proto.Message {
+		return &logspb.LogsData{ResourceLogs: rl}
+	}, parquetLogs)}
+}
+
+// Export buffers records, uploading them if the buffer is full.
+func (e *ArchiveLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	return e.archive.add(ctx, otlpLogs(records).ResourceLogs)
+}
+
+// ForceFlush uploads the buffered records.
+func (e *ArchiveLogExporter) ForceFlush(ctx context.Context) error {
+	return e.archive.flush(ctx)
+}
+
+// Shutdown uploads the buffered records and stops the interval uploads.
+func (e *ArchiveLogExporter) Shutdown(ctx context.Context) error {
+	return e.archive.shutdown(ctx)
+}
+
+// ArchiveMetricExporter is a metric exporter batching collections into
+// gzipped OTLP protobuf or Parquet files uploaded to an object store. The
+// files are under <prefix>/metrics/<yyyy>/<mm>/<dd>/<hh>/. Pass it to
+// WithMetricExporter.
+type ArchiveMetricExporter struct {
+	archive *archive[*metricspb.ResourceMetrics]
+}
+
+// NewArchiveMetricExporter returns an exporter uploading metrics as
+// configured by c.
+func NewArchiveMetricExporter(c ArchiveConfig) *ArchiveMetricExporter {
+	return &ArchiveMetricExporter{archive: newArchive(c, "metrics", func(rm []*metricspb.ResourceMetrics) proto.Message {
+		return &metricspb.MetricsData{ResourceMetrics: rm}
+	}, parquetMetrics)}
+}
+
+// Temporality returns the default temporality for kind.
+func (e *ArchiveMetricExporter) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	return sdkmetric.DefaultTemporalitySelector(kind)
+}
+
+// Aggregation returns the default aggregation for kind.
+func (e *ArchiveMetricExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+// Export buffers rm, uploading it if the buffer is full.
+func (e *ArchiveMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	return e.archive.add(ctx, []*metricspb.ResourceMetrics{otlpMetrics(rm)})
+}
+
+// ForceFlush uploads the buffered metrics.
+func (e *ArchiveMetricExporter) ForceFlush(ctx context.Context) error {
+	return e.archive.flush(ctx)
+}
+
+// Shutdown uploads the buffered metrics and stops the interval uploads.
+func (e *ArchiveMetricExporter) Shutdown(ctx context.Context) error {
+	return e.archive.shutdown(ctx)
+}
+
+// archive buffers the messages of a signal and uploads them as a file on an
+// interval or when the buffer is full.
+type archive[T proto.Message] struct {
+	store    ObjectStore
+	prefix   string
+	signal   string
+	maxBytes int
+	format   ArchiveFormat
+	wrap     func([]T) proto.Message
+	table    func([]T) *parquetTable
+	stop     chan struct{}
+	done     chan struct{}
+
+	mu      sync.Mutex
+	pending []T
+	size    int
+	closed  bool
+
+	// uploadMu serializes the uploads and guards the files waiting for one.
+	uploadMu sync.Mutex
+	files    []archiveFile
+	queued   int
+}
+
+// archiveFile is a compressed file waiting to be uploaded.
+type archiveFile struct {
+	key  string
+	data []byte
+}
+
+func newArchive[T proto.Message](c ArchiveConfig, signal string, wrap func([]T) proto.Message, table func([]T) *parquetTable) *archive[T] {
+	if c.Interval <= 0 {
+		c.Interval = defaultArchiveInterval
+	}
+	if c.MaxBytes <= 0 {
+		c.MaxBytes = defaultArchiveMaxBytes
+	}
+	a := &archive[T]{
+		store:    c.Store,
+		prefix:   c.Prefix,
+		signal:   signal,
+		maxBytes: c.MaxBytes,
+		format:   c.Format,
+		wrap:     wrap,
+		table:    table,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go a.run(c.Interval)
+	return a
+}
+
+// run uploads the buffer every interval until shutdown.
+func (a *archive[T]) run(interval time.Duration) {
+	defer close(a.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), archiveUploadTimeout)
+			if err := a.flush(ctx); err != nil {
+				otel.Handle(err)
+			}
+			cancel()
+		}
+	}
+}
+
+// add buffers msgs, uploading the buffer if it reached the maximum size.
+func (a *archive[T]) add(ctx context.Context, msgs []T) error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	for _, msg := range msgs {
+		a.pending = append(a.pending, msg)
+		a.size += proto.Size(msg)
+	}
+	full := a.size >= a.maxBytes
+	a.mu.Unlock()
+	if full {
+		return a.flush(ctx)
+	}
+	return nil
+}
+
+// flush uploads the buffer as a file, if not empty, after the files whose
+// upload failed before. Files are kept until uploaded, up to maxBytes of
+// them; the oldest are dropped past that, as the next batches would
+// otherwise pile up while the store is unavailable.
+func (a *archive[T]) flush(ctx context.Context) error {
+	a.mu.Lock()
+	pending := a.pending
+	a.pending, a.size = nil, 0
+	a.mu.Unlock()
+
+	a.uploadMu.Lock()
+	defer a.uploadMu.Unlock()
+	var errs []error
+	if len(pending) > 0 {
+		f, err := a.file(pending)
+		if err != nil {
+			return err
+		}
+		a.files = append(a.files, f)
+		a.queued += len(f.data)
+		for a.queued > a.maxBytes && len(a.files) > 1 {
+			errs = append(errs, fmt.Errorf("telemetry: archive upload backlog is full, dropping %s", a.files[0].key))
+			a.queued -= len(a.files[0].data)
+			a.files = a.files[1:]
+		}
+	}
+
+	for len(a.files) > 0 {
+		f := a.files[0]
+		if err := a.store.Upload(ctx, f.key, f.data); err != nil {
+			errs = append(errs, fmt.Errorf("telemetry: upload of %s failed, %d files kept for retry: %w", f.key, len(a.files), err))
+			break
+		}
+		a.queued -= len(f.data)
+		a.files = a.files[1:]
+	}
+	return errors.Join(errs...)
+}
+
+// file encodes msgs as a compressed file in the format of the archive.
+func (a *archive[T]) file(msgs []T) (archiveFile, error) {
+	var (
+		data []byte
+		ext  string
+		err  error
+	)
+	switch a.format {
+	case ArchiveParquet:
+		data, err = a.table(msgs).encode()
+		ext = "parquet"
+	default:
+		data, err = gzipProto(a.wrap(msgs))
+		ext = "binpb.gz"
+	}
+	if err != nil {
+		return archiveFile{}, err
+	}
+
+	now := time.Now().UTC()
+	key := path.Join(a.prefix, a.signal, now.Format("2006/01/02/15"),
+		fmt.Sprintf("%s-%s.%s", now.Format("20060102T150405Z"), uuid.NewString(), ext))
+	return archiveFile{key: key, data: data}, nil
+}
+
+// gzipProto returns msg encoded and gzipped.
+func gzipProto(msg proto.Message) ([]byte, error) {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// shutdown stops the interval uploads and uploads the rest of the buffer.
+func (a *archive[T]) shutdown(ctx context.Context) error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	a.mu.Unlock()
+
+	close(a.stop)
+	<-a.done
+	return a.flush(ctx)
+}
@@ -0,0 +1,160 @@
+package telemetry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// memoryStore is an ObjectStore keeping the uploaded objects, failing the
+// uploads while fail is set.
+type memoryStore struct {
+	mu      sync.Mutex
+	fail    bool
+	objects []string
+	data    [][]byte
+}
+
+func (s *memoryStore) Upload(_ context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("unavailable")
+	}
+	s.objects = append(s.objects, key)
+	s.data = append(s.data, data)
+	return nil
+}
+
+func TestArchiveRetainsFailedUploads(t *testing.T) {
+	tests := []struct {
+		name string
+		// fails tells whether the store fails during each export.
+		fails       []bool
+		maxBytes    int
+		wantErrs    []bool
+		wantObjects int
+	}{
+		{
+			name:        "uploaded",
+			fails:       []bool{false, false},
+			wantErrs:    []bool{false, false},
+			wantObjects: 2,
+		},
+		{
+			name:        "retried after a failure",
+			fails:       []bool{true, true, false},
+			wantErrs:    []bool{true, true, false},
+			wantObjects: 3,
+		},
+		{
+			name:        "oldest dropped past the limit",
+			fails:       []bool{true, true, false},
+			maxBytes:    1,
+			wantErrs:    []bool{true, true, true},
+			wantObjects: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := &memoryStore{}
+			exp := NewArchiveSpanExporter(ArchiveConfig{Store: store, Prefix: "test", Interval: time.Hour, MaxBytes: tt.maxBytes})
+			defer exp.Shutdown(ctx)
+
+			for i, fail := range tt.fails {
+				store.mu.Lock()
+				store.fail = fail
+				store.mu.Unlock()
+				err := errors.Join(
+					exp.ExportSpans(ctx, tracetest.SpanStubs{{Name: "checkout"}}.Snapshots()),
+					exp.archive.flush(ctx))
+				if (err != nil) != tt.wantErrs[i] {
+					t.Errorf("export %d = %v, want error %v", i, err, tt.wantErrs[i])
+				}
+			}
+			if len(store.objects) != tt.wantObjects {
+				t.Fatalf("uploaded %d objects, want %d", len(store.objects), tt.wantObjects)
+			}
+			for _, key := range store.objects {
+				if !strings.HasPrefix(key, "test/traces/") || !strings.HasSuffix(key, ".binpb.gz") {
+					t.Errorf("object key %q", key)
+				}
+			}
+		})
+	}
+}
+
+func TestArchiveMetricExporter(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	exp := NewArchiveMetricExporter(ArchiveConfig{Store: store, Interval: time.Hour})
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	meter := mp.Meter("test")
+
+	counter, _ := meter.Int64Counter("orders")
+	counter.Add(ctx, 3, metric.WithAttributes(attribute.String("region", "eu")))
+	histogram, _ := meter.Float64Histogram("latency")
+	histogram.Record(ctx, 0.5)
+	histogram.Record(ctx, 1.5)
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := exp.Export(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+	if err := exp.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.objects) != 1 || !strings.HasPrefix(store.objects[0], "metrics/") {
+		t.Fatalf("objects = %v", store.objects)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(store.data[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data metricspb.MetricsData
+	if err := proto.Unmarshal(raw, &data); err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]*metricspb.Metric)
+	for _, rm := range data.ResourceMetrics {
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				got[m.Name] = m
+			}
+		}
+	}
+	orders := got["orders"].GetSum()
+	if orders == nil || len(orders.DataPoints) != 1 || orders.DataPoints[0].GetAsInt() != 3 ||
+		orders.AggregationTemporality != metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE {
+		t.Errorf("orders = %v", got["orders"])
+	}
+	latency := got["latency"].GetHistogram()
+	if latency == nil || len(latency.DataPoints) != 1 || latency.DataPoints[0].Count != 2 ||
+		latency.DataPoints[0].GetSum() != 2 || latency.DataPoints[0].GetMax() != 1.5 {
+		t.Errorf("latency = %v", got["latency"])
+	}
+}
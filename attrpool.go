@@ -0,0 +1,64 @@
+package telemetry
+
+import (
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// attrPool recycles the attribute slices built for each measurement on hot
+// paths. The slices are copied into an attribute set by metric.WithAttributes,
+// so they can be returned to the pool as soon as the option is built.
+var attrPool = sync.Pool{
+	New: func() any {
+		s := make([]attribute.KeyValue, 0, 8)
+		return &s
+	},
+}
+
+// getAttrs returns an empty attribute slice from the pool.
+func getAttrs() *[]attribute.KeyValue {
+	return attrPool.Get().(*[]attribute.KeyValue)
+}
+
+// putAttrs clears attrs and returns it to the pool.
+func putAttrs(attrs *[]attribute.KeyValue) {
+	clear(*attrs)
+	*attrs = (*attrs)[:0]
+	attrPool.Put(attrs)
+}
+
+// otherMethod replaces request methods that aren't known HTTP methods, as
+// recommended by the semantic conventions, to bound metric cardinality.
+const otherMethod = "_OTHER"
+
+// methodAttrs holds the pre-baked http.request.method attribute and
+// measurement option of each known method, so recording a request doesn't
+// allocate them.
+var methodAttrs = func() map[string]methodAttr {
+	m := make(map[string]methodAttr)
+	for _, method := range []string{
+		http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace, otherMethod,
+	} {
+		attr := semconv.HTTPRequestMethodKey.String(method)
+		m[method] = methodAttr{attr: attr, opt: metric.WithAttributeSet(attribute.NewSet(attr))}
+	}
+	return m
+}()
+
+type methodAttr struct {
+	attr attribute.KeyValue
+	opt  metric.MeasurementOption
+}
+
+// lookupMethod returns the pre-baked attributes of method.
+func lookupMethod(method string) methodAttr {
+	if m, ok := methodAttrs[method]; ok {
+		return m
+	}
+	return methodAttrs[otherMethod]
+}
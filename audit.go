@@ -0,0 +1,98 @@
+package telemetry
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// auditLogger emits the records of Audit once the pipeline is set up.
+var auditLogger atomic.Pointer[log.Logger]
+
+// WithAuditExporter sets the exporter receiving audit records. It may point
+// to a different endpoint than the regular log pipeline. By default audit
+// records are sent to the log exporter of the pipeline, such as the
+// collector of SetupOTelSDKGrpc, bypassing its batching.
+func WithAuditExporter(exporter sdklog.Exporter) Option {
+	return func(c *config) {
+		c.auditExporter = exporter
+	}
+}
+
+// Audit emits a compliance event for action. Audit records go through their
+// own logger provider whose processor exports each record synchronously, so
+// they are never sampled, batched or dropped on exit. The privacy mode and
+// the attribute value limit of the pipeline apply to them. It returns
+// ErrNotInitialized if the pipeline isn't set up, and the error of the export
+// if it failed, so the caller can refuse the audited action or retry.
+func Audit(ctx context.Context, action string, attrs ...log.KeyValue) error {
+	logger := auditLogger.Load()
+	if logger == nil {
+		return ErrNotInitialized
+	}
+
+	var rec log.Record
+	rec.SetTimestamp(time.Now())
+	rec.SetSeverity(log.SeverityInfo)
+	rec.SetBody(log.StringValue(action))
+	rec.AddAttributes(log.String("audit.action", action))
+	rec.AddAttributes(attrs...)
+
+	var err error
+	(*logger).Emit(context.WithValue(ctx, auditErrKey{}, &err), rec)
+	return err
+}
+
+// auditErrKey is the context key under which Audit passes auditProcessor the
+// location of its error.
+type auditErrKey struct{}
+
+// auditProcessor hands the export error of each record to the Audit call
+// that emitted it, as loggers don't return errors.
+type auditProcessor struct {
+	sdklog.Processor
+}
+
+func (p auditProcessor) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	err := p.Processor.OnEmit(ctx, record)
+	if errp, ok := ctx.Value(auditErrKey{}).(*error); ok {
+		*errp = err
+		return nil
+	}
+	return err
+}
+
+// sharedLogExporter is the pipeline's log exporter used for audit records,
+// which is shut down by the regular logger provider.
+type sharedLogExporter struct {
+	sdklog.Exporter
+}
+
+func (sharedLogExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// newAuditLoggerProvider creates the logger provider backing Audit, exporting
+// to the audit exporter of cfg or else to logExporter, the log exporter of
+// the pipeline.
+func newAuditLoggerProvider(cfg config, logExporter sdklog.Exporter, res *resource.Resource) (*sdklog.LoggerProvider, error) {
+	exporter := cfg.auditExporter
+	if exporter == nil {
+		exporter = sharedLogExporter{logExporter}
+	}
+
+	opts := []sdklog.LoggerProviderOption{sdklog.WithResource(res)}
+	if cfg.privacy != nil {
+		opts = append(opts, sdklog.WithProcessor(privacyLogProcessor{filter: cfg.privacy}))
+	}
+	if cfg.attributeValueLimit > 0 {
+		opts = append(opts, sdklog.WithProcessor(truncatingLogProcessor{limit: cfg.attributeValueLimit}))
+	}
+	opts = append(opts, sdklog.WithProcessor(auditProcessor{sdklog.NewSimpleProcessor(exporter)}))
+	loggerProvider := sdklog.NewLoggerProvider(opts...)
+	return loggerProvider, nil
+}
@@ -0,0 +1,107 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// auditExporter records the audit records, failing with err if set.
+type auditExporter struct {
+	mu      sync.Mutex
+	err     error
+	actions []string
+	records []sdklog.Record
+}
+
+func (e *auditExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.err != nil {
+		return e.err
+	}
+	for _, r := range records {
+		e.actions = append(e.actions, r.Body().AsString())
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *auditExporter) Shutdown(context.Context) error   { return nil }
+func (e *auditExporter) ForceFlush(context.Context) error { return nil }
+
+func TestAudit(t *testing.T) {
+	ctx := context.Background()
+	if err := Audit(ctx, "before.setup"); !errors.Is(err, ErrNotInitialized) {
+		t.Fatalf("Audit before setup: got %v, want ErrNotInitialized", err)
+	}
+
+	exporter := &auditExporter{}
+	shutdown, err := SetupOTelSDKStdout(ctx,
+		WithStdoutWriters(io.Discard, io.Discard, io.Discard),
+		WithAuditExporter(exporter))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Audit(ctx, "user.login"); err != nil {
+		t.Errorf("Audit: %v", err)
+	}
+	exportErr := errors.New("export failed")
+	exporter.err = exportErr
+	if err := Audit(ctx, "user.logout"); !errors.Is(err, exportErr) {
+		t.Errorf("Audit with a failing exporter: got %v, want %v", err, exportErr)
+	}
+	if len(exporter.actions) != 1 || exporter.actions[0] != "user.login" {
+		t.Errorf("got actions %v, want [user.login]", exporter.actions)
+	}
+
+	if err := shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := Audit(ctx, "after.shutdown"); !errors.Is(err, ErrNotInitialized) {
+		t.Errorf("Audit after shutdown: got %v, want ErrNotInitialized", err)
+	}
+}
+
+func TestAuditAttributeFilters(t *testing.T) {
+	ctx := context.Background()
+	exporter := &auditExporter{}
+	shutdown, err := SetupOTelSDKStdout(ctx,
+		WithStdoutWriters(io.Discard, io.Discard, io.Discard),
+		WithAuditExporter(exporter),
+		WithPrivacy(PrivacyDrop, ""),
+		WithAttributeValueLimit(12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(ctx)
+
+	err = Audit(ctx, "user.export",
+		log.String("user.email", "jane@example.com"),
+		log.String("export.query", "SELECT * FROM orders"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exporter.records) != 1 {
+		t.Fatalf("got %d records, want 1", len(exporter.records))
+	}
+	got := make(map[string]string)
+	exporter.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+		got[kv.Key] = kv.Value.AsString()
+		return true
+	})
+	want := map[string]string{
+		"audit.action": "user.export",
+		"export.query": "SELECT * FRO" + truncationMarker,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("got attributes %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,230 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	// ec2MetadataURL is the base URL of the EC2 instance metadata service.
+	ec2MetadataURL = "http://169.254.169.254"
+	// k8sServiceAccountDir holds the credentials mounted into every pod.
+	k8sServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	// k8sAPIURL is the in-cluster address of the Kubernetes API server.
+	k8sAPIURL = "https://kubernetes.default.svc"
+)
+
+// EC2Detector detects the cloud.* and host.* attributes of an EC2 instance
+// from the instance metadata service, using IMDSv2. It finds nothing outside
+// of EC2.
+func EC2Detector() resource.Detector {
+	return detectorFunc(detectEC2)
+}
+
+func detectEC2(ctx context.Context) (*resource.Resource, error) {
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, ec2MetadataURL+"/latest/api/token", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	token, err := fetchMetadata(req)
+	if err != nil {
+		// Not running on EC2.
+		return resource.Empty(), nil
+	}
+
+	get := func(path string) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ec2MetadataURL+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-aws-ec2-metadata-token", string(token))
+		return fetchMetadata(req)
+	}
+	body, err := get("/latest/dynamic/instance-identity/document")
+	if err != nil {
+		return nil, fmt.Errorf("ec2: %w", err)
+	}
+	var doc struct {
+		AccountID        string `json:"accountId"`
+		AvailabilityZone string `json:"availabilityZone"`
+		ImageID          string `json:"imageId"`
+		InstanceID       string `json:"instanceId"`
+		InstanceType     string `json:"instanceType"`
+		Region           string `json:"region"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("ec2: %w", err)
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.CloudProviderAWS,
+		semconv.CloudPlatformAWSEC2,
+		semconv.CloudAccountID(doc.AccountID),
+		semconv.CloudRegion(doc.Region),
+		semconv.CloudAvailabilityZone(doc.AvailabilityZone),
+		semconv.HostID(doc.InstanceID),
+		semconv.HostImageID(doc.ImageID),
+		semconv.HostType(doc.InstanceType),
+	}
+	if hostname, err := get("/latest/meta-data/hostname"); err == nil {
+		attrs = append(attrs, semconv.HostName(string(hostname)))
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+// ECSDetector detects the cloud.* and aws.ecs.* attributes of an ECS task
+// from the task metadata endpoint v4. It finds nothing outside of ECS.
+func ECSDetector() resource.Detector {
+	return detectorFunc(detectECS)
+}
+
+func detectECS(ctx context.Context) (*resource.Resource, error) {
+	baseURL := os.Getenv("ECS_CONTAINER_METADATA_URI_V4")
+	if baseURL == "" {
+		return resource.Empty(), nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	defer cancel()
+
+	get := func(url string, v any) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		body, err := fetchMetadata(req)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(body, v)
+	}
+	var task struct {
+		AvailabilityZone string `json:"AvailabilityZone"`
+		Cluster          string `json:"Cluster"`
+		Family           string `json:"Family"`
+		LaunchType       string `json:"LaunchType"`
+		Revision         string `json:"Revision"`
+		TaskARN          string `json:"TaskARN"`
+	}
+	if err := get(baseURL+"/task", &task); err != nil {
+		return nil, fmt.Errorf("ecs: %w", err)
+	}
+	var container struct {
+		ContainerARN string `json:"ContainerARN"`
+		DockerID     string `json:"DockerId"`
+	}
+	if err := get(baseURL, &container); err != nil {
+		return nil, fmt.Errorf("ecs: %w", err)
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.CloudProviderAWS,
+		semconv.CloudPlatformAWSECS,
+		semconv.AWSECSTaskARN(task.TaskARN),
+		semconv.AWSECSTaskFamily(task.Family),
+		semconv.AWSECSTaskRevision(task.Revision),
+		semconv.AWSECSContainerARN(container.ContainerARN),
+		semconv.ContainerID(container.DockerID),
+	}
+	if task.AvailabilityZone != "" {
+		attrs = append(attrs, semconv.CloudAvailabilityZone(task.AvailabilityZone))
+	}
+	if lt := strings.ToLower(task.LaunchType); lt == "ec2" || lt == "fargate" {
+		attrs = append(attrs, semconv.AWSECSLaunchtypeKey.String(lt))
+	}
+	// The cluster is reported as a name or an ARN depending on the agent.
+	clusterARN := task.Cluster
+	if !strings.HasPrefix(clusterARN, "arn:") {
+		if prefix, _, ok := strings.Cut(task.TaskARN, ":task/"); ok {
+			clusterARN = prefix + ":cluster/" + task.Cluster
+		}
+	}
+	attrs = append(attrs, semconv.AWSECSClusterARN(clusterARN))
+	// arn:aws:ecs:<region>:<account>:task/...
+	if parts := strings.Split(task.TaskARN, ":"); len(parts) > 5 {
+		attrs = append(attrs, semconv.CloudRegion(parts[3]), semconv.CloudAccountID(parts[4]))
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+// EKSDetector detects the cloud.* and k8s.cluster.name attributes of a pod
+// running on EKS, reading the cluster name from the cluster-info config map
+// of the amazon-cloudwatch namespace, as set up by the CloudWatch agent. The
+// pod's service account must be allowed to read it and the aws-auth config
+// map of kube-system, which identifies the cluster as EKS. It finds nothing
+// outside of Kubernetes.
+func EKSDetector() resource.Detector {
+	return detectorFunc(detectEKS)
+}
+
+func detectEKS(ctx context.Context) (*resource.Resource, error) {
+	token, err := os.ReadFile(k8sServiceAccountDir + "/token")
+	if err != nil {
+		// Not running on Kubernetes.
+		return resource.Empty(), nil
+	}
+	ca, err := os.ReadFile(k8sServiceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("eks: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("eks: invalid service account CA certificate")
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	defer cancel()
+	getConfigMap := func(namespace, name string) (map[string]string, error) {
+		url := k8sAPIURL + "/api/v1/namespaces/" + namespace + "/configmaps/" + name
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("config map %s/%s: %s", namespace, name, resp.Status)
+		}
+		var cm struct {
+			Data map[string]string `json:"data"`
+		}
+		if err := json.NewDecoder(io.LimitReader(resp.Body, metadataMaxSize)).Decode(&cm); err != nil {
+			return nil, err
+		}
+		return cm.Data, nil
+	}
+
+	if _, err := getConfigMap("kube-system", "aws-auth"); err != nil {
+		// Not running on EKS, or not allowed to tell.
+		return resource.Empty(), nil
+	}
+	attrs := []attribute.KeyValue{semconv.CloudProviderAWS, semconv.CloudPlatformAWSEKS}
+	info, err := getConfigMap("amazon-cloudwatch", "cluster-info")
+	if err != nil {
+		return resource.NewWithAttributes(semconv.SchemaURL, attrs...), fmt.Errorf("eks: cluster name: %w", err)
+	}
+	if name := info["cluster.name"]; name != "" {
+		attrs = append(attrs, semconv.K8SClusterName(name))
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
@@ -0,0 +1,105 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// azureMetadataURL is the compute endpoint of the Azure instance metadata
+// service.
+const azureMetadataURL = "http://169.254.169.254/metadata/instance/compute?api-version=2021-12-13&format=json"
+
+// AzureDetector detects the cloud.*, host.* and k8s.* attributes of
+// workloads running on Azure VMs and AKS from the instance metadata service,
+// and of Container Apps from the environment their runtime sets. It finds
+// nothing outside of Azure.
+func AzureDetector() resource.Detector {
+	return detectorFunc(detectAzure)
+}
+
+func detectAzure(ctx context.Context) (*resource.Resource, error) {
+	if app := os.Getenv("CONTAINER_APP_NAME"); app != "" {
+		attrs := []attribute.KeyValue{
+			semconv.CloudProviderAzure,
+			semconv.CloudPlatformAzureContainerApps,
+			semconv.ServiceInstanceID(os.Getenv("CONTAINER_APP_REPLICA_NAME")),
+			attribute.String("azure.container_app.name", app),
+			attribute.String("azure.container_app.revision", os.Getenv("CONTAINER_APP_REVISION")),
+		}
+		return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, azureMetadataURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata", "true")
+	body, err := fetchMetadata(req)
+	if err != nil {
+		// Not running on an Azure VM.
+		return resource.Empty(), nil
+	}
+	var compute struct {
+		Location          string `json:"location"`
+		Name              string `json:"name"`
+		ResourceGroupName string `json:"resourceGroupName"`
+		ResourceID        string `json:"resourceId"`
+		SubscriptionID    string `json:"subscriptionId"`
+		VMID              string `json:"vmId"`
+		VMScaleSetName    string `json:"vmScaleSetName"`
+		VMSize            string `json:"vmSize"`
+		Zone              string `json:"zone"`
+	}
+	if err := json.Unmarshal(body, &compute); err != nil {
+		return nil, fmt.Errorf("azure: %w", err)
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.CloudProviderAzure,
+		semconv.CloudAccountID(compute.SubscriptionID),
+		semconv.CloudRegion(compute.Location),
+		semconv.CloudResourceID(compute.ResourceID),
+		semconv.HostID(compute.VMID),
+		semconv.HostName(compute.Name),
+		semconv.HostType(compute.VMSize),
+	}
+	if compute.Zone != "" {
+		attrs = append(attrs, semconv.CloudAvailabilityZone(compute.Zone))
+	}
+	// AKS nodes live in a resource group named MC_<group>_<cluster>_<location>.
+	if cluster, ok := aksClusterName(compute.ResourceGroupName, compute.Location); ok && os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		attrs = append(attrs, semconv.CloudPlatformAzureAKS, semconv.K8SClusterName(cluster))
+	} else {
+		attrs = append(attrs, semconv.CloudPlatformAzureVM)
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+// aksClusterName extracts the cluster name from the resource group of an AKS
+// node. Since resource group names may contain underscores too, the part
+// after the last underscore is taken as the cluster name.
+func aksClusterName(group, location string) (string, bool) {
+	rest, ok := strings.CutPrefix(group, "MC_")
+	if !ok {
+		return "", false
+	}
+	rest, ok = strings.CutSuffix(rest, "_"+location)
+	if !ok {
+		return "", false
+	}
+	i := strings.LastIndexByte(rest, '_')
+	if i < 0 {
+		return "", false
+	}
+	return rest[i+1:], true
+}
@@ -0,0 +1,112 @@
+package telemetry
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// B3 header names, see https://github.com/openzipkin/b3-propagation.
+const (
+	b3SingleHeader  = "b3"
+	b3TraceIDHeader = "x-b3-traceid"
+	b3SpanIDHeader  = "x-b3-spanid"
+	b3SampledHeader = "x-b3-sampled"
+	b3FlagsHeader   = "x-b3-flags"
+)
+
+// b3Propagator propagates the trace context in the Zipkin B3 format. It
+// injects either the single b3 header or the multiple X-B3-* headers, and
+// extracts both, preferring the single header.
+type b3Propagator struct {
+	single bool
+}
+
+var _ propagation.TextMapPropagator = b3Propagator{}
+
+func (p b3Propagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	sampled := "0"
+	if sc.IsSampled() {
+		sampled = "1"
+	}
+	if p.single {
+		carrier.Set(b3SingleHeader, sc.TraceID().String()+"-"+sc.SpanID().String()+"-"+sampled)
+		return
+	}
+	carrier.Set(b3TraceIDHeader, sc.TraceID().String())
+	carrier.Set(b3SpanIDHeader, sc.SpanID().String())
+	carrier.Set(b3SampledHeader, sampled)
+}
+
+func (p b3Propagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	var sc trace.SpanContext
+	var ok bool
+	if h := carrier.Get(b3SingleHeader); h != "" {
+		sc, ok = parseB3Single(h)
+	} else {
+		sc, ok = parseB3(carrier.Get(b3TraceIDHeader), carrier.Get(b3SpanIDHeader),
+			carrier.Get(b3SampledHeader), carrier.Get(b3FlagsHeader))
+	}
+	if !ok {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, sc)
+}
+
+func (p b3Propagator) Fields() []string {
+	if p.single {
+		return []string{b3SingleHeader}
+	}
+	return []string{b3TraceIDHeader, b3SpanIDHeader, b3SampledHeader}
+}
+
+// parseB3Single parses a b3 header of the form
+// {TraceId}-{SpanId}[-{SamplingState}[-{ParentSpanId}]]. Headers that only
+// carry a sampling state are ignored.
+func parseB3Single(h string) (trace.SpanContext, bool) {
+	parts := strings.Split(h, "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return trace.SpanContext{}, false
+	}
+	var sampled, flags string
+	if len(parts) > 2 {
+		if parts[2] == "d" {
+			flags = "1"
+		} else {
+			sampled = parts[2]
+		}
+	}
+	return parseB3(parts[0], parts[1], sampled, flags)
+}
+
+// parseB3 builds a remote span context from the B3 fields. 64-bit trace IDs
+// are left-padded to 128 bits.
+func parseB3(traceID, spanID, sampled, flags string) (trace.SpanContext, bool) {
+	if len(traceID) == 16 {
+		traceID = strings.Repeat("0", 16) + traceID
+	}
+	tid, err := trace.TraceIDFromHex(strings.ToLower(traceID))
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	sid, err := trace.SpanIDFromHex(strings.ToLower(spanID))
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	var traceFlags trace.TraceFlags
+	if flags == "1" || sampled == "1" || strings.EqualFold(sampled, "true") {
+		traceFlags = trace.FlagsSampled
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    tid,
+		SpanID:     sid,
+		TraceFlags: traceFlags,
+		Remote:     true,
+	}), true
+}
@@ -0,0 +1,91 @@
+package telemetry
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestB3RoundTrip(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	tests := []struct {
+		name   string
+		single bool
+		flags  trace.TraceFlags
+		want   propagation.MapCarrier
+	}{
+		{"single sampled", true, trace.FlagsSampled, propagation.MapCarrier{
+			"b3": "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+		}},
+		{"single not sampled", true, 0, propagation.MapCarrier{
+			"b3": "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0",
+		}},
+		{"multi sampled", false, trace.FlagsSampled, propagation.MapCarrier{
+			"x-b3-traceid": "4bf92f3577b34da6a3ce929d0e0e4736",
+			"x-b3-spanid":  "00f067aa0ba902b7",
+			"x-b3-sampled": "1",
+		}},
+		{"multi not sampled", false, 0, propagation.MapCarrier{
+			"x-b3-traceid": "4bf92f3577b34da6a3ce929d0e0e4736",
+			"x-b3-spanid":  "00f067aa0ba902b7",
+			"x-b3-sampled": "0",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := b3Propagator{single: tt.single}
+			sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: tt.flags})
+			carrier := propagation.MapCarrier{}
+			p.Inject(trace.ContextWithSpanContext(context.Background(), sc), carrier)
+			if !maps.Equal(carrier, tt.want) {
+				t.Errorf("injected %v, want %v", carrier, tt.want)
+			}
+			if got, fields := slices.Sorted(maps.Keys(carrier)), slices.Sorted(slices.Values(p.Fields())); !slices.Equal(got, fields) {
+				t.Errorf("injected headers %v, Fields %v", got, fields)
+			}
+
+			got := trace.SpanContextFromContext(p.Extract(context.Background(), carrier))
+			if !got.Equal(sc.WithRemote(true)) {
+				t.Errorf("extracted %v, want %v", got, sc)
+			}
+		})
+	}
+}
+
+func TestB3Extract(t *testing.T) {
+	tests := []struct {
+		name        string
+		carrier     propagation.MapCarrier
+		wantTraceID string
+		wantSampled bool
+	}{
+		{"64-bit trace ID", propagation.MapCarrier{"b3": "a3ce929d0e0e4736-00f067aa0ba902b7-1"}, "0000000000000000a3ce929d0e0e4736", true},
+		{"debug", propagation.MapCarrier{"b3": "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-d"}, "4bf92f3577b34da6a3ce929d0e0e4736", true},
+		{"multi flags", propagation.MapCarrier{
+			"x-b3-traceid": "4bf92f3577b34da6a3ce929d0e0e4736",
+			"x-b3-spanid":  "00f067aa0ba902b7",
+			"x-b3-flags":   "1",
+		}, "4bf92f3577b34da6a3ce929d0e0e4736", true},
+		{"sampling state only", propagation.MapCarrier{"b3": "0"}, "", false},
+		{"invalid span ID", propagation.MapCarrier{"b3": "4bf92f3577b34da6a3ce929d0e0e4736-xyz"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := trace.SpanContextFromContext(b3Propagator{}.Extract(context.Background(), tt.carrier))
+			if tt.wantTraceID == "" {
+				if sc.IsValid() {
+					t.Errorf("extracted %v, want none", sc)
+				}
+				return
+			}
+			if got := sc.TraceID().String(); got != tt.wantTraceID || sc.IsSampled() != tt.wantSampled || !sc.IsRemote() {
+				t.Errorf("extracted %v (sampled %v), want trace %s (sampled %v)", got, sc.IsSampled(), tt.wantTraceID, tt.wantSampled)
+			}
+		})
+	}
+}
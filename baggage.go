@@ -0,0 +1,49 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+const (
+	// maxBaggageMemberBytes is the W3C limit for a single baggage member.
+	maxBaggageMemberBytes = 4096
+	// maxBaggageBytes is the W3C limit for the whole baggage header.
+	maxBaggageBytes = 8192
+)
+
+// SetBaggage returns a copy of ctx whose baggage has key set to value. The
+// key is validated and the member and total baggage sizes are checked
+// against the W3C limits, so oversized baggage is rejected here rather than
+// silently dropped by the propagator.
+func SetBaggage(ctx context.Context, key, value string) (context.Context, error) {
+	member, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		return ctx, fmt.Errorf("telemetry: invalid baggage member %q: %w", key, err)
+	}
+	if n := len(member.String()); n > maxBaggageMemberBytes {
+		return ctx, fmt.Errorf("telemetry: baggage member %q is %d bytes, limit is %d", key, n, maxBaggageMemberBytes)
+	}
+
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx, fmt.Errorf("telemetry: setting baggage member %q: %w", key, err)
+	}
+	if n := len(bag.String()); n > maxBaggageBytes {
+		return ctx, fmt.Errorf("telemetry: baggage is %d bytes, limit is %d", n, maxBaggageBytes)
+	}
+	return baggage.ContextWithBaggage(ctx, bag), nil
+}
+
+// GetBaggage returns the value of the baggage member key in ctx, or "" if it
+// is not set.
+func GetBaggage(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
+
+// DeleteBaggage returns a copy of ctx without the baggage member key.
+func DeleteBaggage(ctx context.Context, key string) context.Context {
+	return baggage.ContextWithBaggage(ctx, baggage.FromContext(ctx).DeleteMember(key))
+}
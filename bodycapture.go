@@ -0,0 +1,124 @@
+package telemetry
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultBodyCaptureBytes is the number of body bytes captured when
+// BodyCapture.MaxBytes is not set.
+const defaultBodyCaptureBytes = 4096
+
+// BodyCapture selects the requests whose bodies CaptureBodies records.
+type BodyCapture struct {
+	// Routes are the route patterns, or paths when the route is unknown,
+	// whose bodies are always captured. "*" matches any sequence of
+	// characters, e.g. "POST /orders/*".
+	Routes []string
+	// OnError also captures the bodies of requests answered with a 4xx or
+	// 5xx status.
+	OnError bool
+	// MaxBytes is the number of bytes of each body recorded, 4096 by default.
+	MaxBytes int
+}
+
+// CaptureBodies returns a middleware recording the request and response
+// bodies of the requests selected by c as http.request.body and
+// http.response.body events on the current span, truncated to c.MaxBytes.
+// Only the part of the request body read by the handler is recorded, and
+// only with VerbosityDetailed. Place it inside Middleware so the server span
+// is already started.
+func CaptureBodies(c BodyCapture) func(http.Handler) http.Handler {
+	if c.MaxBytes <= 0 {
+		c.MaxBytes = defaultBodyCaptureBytes
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			span := trace.SpanFromContext(r.Context())
+			if !span.IsRecording() || !verbose(VerbosityDetailed) || (len(c.Routes) == 0 && !c.OnError) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			req := &limitedBuffer{limit: c.MaxBytes}
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = teeReadCloser{Reader: io.TeeReader(r.Body, req), Closer: r.Body}
+			}
+			rw := &bodyWriter{responseWriter: newResponseWriter(w), body: limitedBuffer{limit: c.MaxBytes}}
+			next.ServeHTTP(rw, r)
+
+			// The route pattern is only known once the handler has run.
+			failed := c.OnError && rw.status >= http.StatusBadRequest
+			if !failed && !matchRoute(c.Routes, r) {
+				return
+			}
+			span.AddEvent("http.request.body", trace.WithAttributes(req.attributes("http.request.body")...))
+			span.AddEvent("http.response.body", trace.WithAttributes(rw.body.attributes("http.response.body")...))
+		})
+	}
+}
+
+// matchRoute reports whether the route pattern of r, or its path if it has
+// none, matches one of routes.
+func matchRoute(routes []string, r *http.Request) bool {
+	target := r.Pattern
+	if target == "" {
+		target = r.Method + " " + r.URL.Path
+	}
+	for _, route := range routes {
+		if globMatch(route, target) || globMatch(route, r.URL.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// limitedBuffer keeps the first limit bytes written to it and counts the
+// rest.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+	total int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.total += len(p)
+	if room := b.limit - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(room, len(p))])
+	}
+	return len(p), nil
+}
+
+// attributes describes the captured body under the prefix attribute name.
+func (b *limitedBuffer) attributes(prefix string) []attribute.KeyValue {
+	content := b.String()
+	if b.total > b.Len() {
+		content += truncationMarker
+	}
+	return []attribute.KeyValue{
+		attribute.String(prefix+".content", content),
+		attribute.Int(prefix+".size", b.total),
+	}
+}
+
+// teeReadCloser reads through a tee while closing the original body.
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyWriter records the status and the start of the body written by a
+// handler.
+type bodyWriter struct {
+	*responseWriter
+	body limitedBuffer
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.responseWriter.Write(b)
+}
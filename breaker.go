@@ -0,0 +1,249 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// defaultBreakerCooldown is how long an open breaker waits before letting an
+// export through to probe the exporter.
+const defaultBreakerCooldown = 30 * time.Second
+
+// WithCircuitBreaker opens a circuit breaker per signal after failures
+// consecutive export errors. While it is open, batches are dropped, or
+// handed to the exporters set with WithExportFallback, instead of spending
+// time and CPU on exports bound to fail. After cooldown, 30s if zero, a
+// single export probes the exporter: its success closes the breaker and its
+// failure keeps it open for another cooldown. State changes are counted by
+// the telemetry.exporter.breaker.transitions metric and the opening is
+// reported to the global error handler. The breaker can also be enabled
+// with the TELEMETRY_EXPORT_BREAKER_FAILURES and
+// TELEMETRY_EXPORT_BREAKER_COOLDOWN environment variables.
+func WithCircuitBreaker(failures int, cooldown time.Duration) Option {
+	return func(c *config) {
+		c.breakerFailures = failures
+		c.breakerCooldown = cooldown
+	}
+}
+
+// WithExportFallback sets the exporters receiving the batches of each signal
+// while its circuit breaker is open, e.g. a local file to be replayed later.
+// A nil exporter drops the batches of its signal. It has no effect without
+// WithCircuitBreaker.
+func WithExportFallback(spans trace.SpanExporter, metrics sdkmetric.Exporter, logs sdklog.Exporter) Option {
+	return func(c *config) {
+		c.fallbackSpan = spans
+		c.fallbackMetric = metrics
+		c.fallbackLog = logs
+	}
+}
+
+// breakerState is the state of a circuit breaker.
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerClosed:
+		return "closed"
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half_open"
+	}
+	return fmt.Sprintf("breakerState(%d)", int(s))
+}
+
+// breakerTransitions returns the counter of the state changes of the circuit
+// breakers. A transition is rare, so it is fetched from the global meter
+// provider each time.
+func breakerTransitions() metric.Int64Counter {
+	counter, err := otel.Meter(instrumentationName).Int64Counter("telemetry.exporter.breaker.transitions",
+		metric.WithUnit("{transition}"),
+		metric.WithDescription("Number of state changes of the export circuit breakers, by signal and new state."))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return counter
+}
+
+// breaker is a circuit breaker guarding the exports of one signal.
+type breaker struct {
+	signal   string
+	failures int
+	cooldown time.Duration
+
+	mu       sync.Mutex
+	state    breakerState
+	failed   int
+	openedAt time.Time
+}
+
+func newBreaker(signal string, failures int, cooldown time.Duration) *breaker {
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &breaker{signal: signal, failures: failures, cooldown: cooldown}
+}
+
+// allow reports whether an export may go to the exporter. Once the cooldown
+// of an open breaker has elapsed, it lets a single probe through.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerClosed:
+		return true
+	case breakerOpen:
+		if time.Since(b.openedAt) >= b.cooldown {
+			b.transition(breakerHalfOpen)
+			return true
+		}
+	}
+	return false
+}
+
+// record updates the breaker with the result of an export it allowed.
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		b.failed = 0
+		if b.state != breakerClosed {
+			b.transition(breakerClosed)
+		}
+		return
+	}
+	b.failed++
+	if b.state == breakerHalfOpen || (b.state == breakerClosed && b.failed >= b.failures) {
+		b.openedAt = time.Now()
+		b.transition(breakerOpen)
+		otel.Handle(fmt.Errorf("telemetry: %s export circuit breaker opened after %d consecutive failures: %w", b.signal, b.failed, err))
+	}
+}
+
+// transition moves the breaker to state. b.mu must be held.
+func (b *breaker) transition(state breakerState) {
+	b.state = state
+	if counter := breakerTransitions(); counter != nil {
+		counter.Add(context.Background(), 1, metric.WithAttributes(
+			attribute.String("signal", b.signal),
+			attribute.String("state", state.String())))
+	}
+}
+
+// withBreakers wraps the exporters of exp with circuit breakers.
+func withBreakers(cfg config, exp exporters) exporters {
+	exp.span = &breakerSpanExporter{
+		SpanExporter: exp.span,
+		breaker:      newBreaker("traces", cfg.breakerFailures, cfg.breakerCooldown),
+		fallback:     cfg.fallbackSpan,
+	}
+	if exp.metric != nil {
+		exp.metric = &breakerMetricExporter{
+			Exporter: exp.metric,
+			breaker:  newBreaker("metrics", cfg.breakerFailures, cfg.breakerCooldown),
+			fallback: cfg.fallbackMetric,
+		}
+	}
+	exp.log = &breakerLogExporter{
+		Exporter: exp.log,
+		breaker:  newBreaker("logs", cfg.breakerFailures, cfg.breakerCooldown),
+		fallback: cfg.fallbackLog,
+	}
+	return exp
+}
+
+type breakerSpanExporter struct {
+	trace.SpanExporter
+	breaker  *breaker
+	fallback trace.SpanExporter
+}
+
+func (e *breakerSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	if !e.breaker.allow() {
+		if e.fallback != nil {
+			return e.fallback.ExportSpans(ctx, spans)
+		}
+		return nil
+	}
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	e.breaker.record(err)
+	return err
+}
+
+func (e *breakerSpanExporter) Shutdown(ctx context.Context) error {
+	err := e.SpanExporter.Shutdown(ctx)
+	if e.fallback != nil {
+		err = errors.Join(err, e.fallback.Shutdown(ctx))
+	}
+	return err
+}
+
+type breakerMetricExporter struct {
+	sdkmetric.Exporter
+	breaker  *breaker
+	fallback sdkmetric.Exporter
+}
+
+func (e *breakerMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	if !e.breaker.allow() {
+		if e.fallback != nil {
+			return e.fallback.Export(ctx, rm)
+		}
+		return nil
+	}
+	err := e.Exporter.Export(ctx, rm)
+	e.breaker.record(err)
+	return err
+}
+
+func (e *breakerMetricExporter) Shutdown(ctx context.Context) error {
+	err := e.Exporter.Shutdown(ctx)
+	if e.fallback != nil {
+		err = errors.Join(err, e.fallback.Shutdown(ctx))
+	}
+	return err
+}
+
+type breakerLogExporter struct {
+	sdklog.Exporter
+	breaker  *breaker
+	fallback sdklog.Exporter
+}
+
+func (e *breakerLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	if !e.breaker.allow() {
+		if e.fallback != nil {
+			return e.fallback.Export(ctx, records)
+		}
+		return nil
+	}
+	err := e.Exporter.Export(ctx, records)
+	e.breaker.record(err)
+	return err
+}
+
+func (e *breakerLogExporter) Shutdown(ctx context.Context) error {
+	err := e.Exporter.Shutdown(ctx)
+	if e.fallback != nil {
+		err = errors.Join(err, e.fallback.Shutdown(ctx))
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// failingSpanExporter counts the exports it gets, failing them while fail is
+// set.
+type failingSpanExporter struct {
+	fail    bool
+	exports int
+}
+
+func (e *failingSpanExporter) ExportSpans(context.Context, []trace.ReadOnlySpan) error {
+	e.exports++
+	if e.fail {
+		return errors.New("collector unavailable")
+	}
+	return nil
+}
+
+func (e *failingSpanExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestBreaker(t *testing.T) {
+	// Each step is an export the exporter accepts ("ok") or rejects
+	// ("fail"), or "cool" to let the cooldown of the breaker elapse, with
+	// the expectations of exports only.
+	type step struct {
+		do           string
+		wantExported bool
+		wantFallback bool
+		wantState    breakerState
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "stays closed under the threshold",
+			steps: []step{
+				{"fail", true, false, breakerClosed},
+				{"fail", true, false, breakerClosed},
+				{"ok", true, false, breakerClosed},
+				{"fail", true, false, breakerClosed},
+				{"fail", true, false, breakerClosed},
+			},
+		},
+		{
+			name: "opens after consecutive failures",
+			steps: []step{
+				{"fail", true, false, breakerClosed},
+				{"fail", true, false, breakerClosed},
+				{"fail", true, false, breakerOpen},
+				{"ok", false, true, breakerOpen},
+				{"fail", false, true, breakerOpen},
+			},
+		},
+		{
+			name: "probe success closes",
+			steps: []step{
+				{"fail", true, false, breakerClosed},
+				{"fail", true, false, breakerClosed},
+				{"fail", true, false, breakerOpen},
+				{"cool", false, false, breakerOpen},
+				{"ok", true, false, breakerClosed},
+				{"ok", true, false, breakerClosed},
+			},
+		},
+		{
+			name: "probe failure reopens",
+			steps: []step{
+				{"fail", true, false, breakerClosed},
+				{"fail", true, false, breakerClosed},
+				{"fail", true, false, breakerOpen},
+				{"cool", false, false, breakerOpen},
+				{"fail", true, false, breakerOpen},
+				{"ok", false, true, breakerOpen},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary, fallback := &failingSpanExporter{}, &failingSpanExporter{}
+			exp := &breakerSpanExporter{
+				SpanExporter: primary,
+				breaker:      newBreaker("traces", 3, time.Hour),
+				fallback:     fallback,
+			}
+			spans := tracetest.SpanStubs{{Name: "checkout"}}.Snapshots()
+
+			for i, s := range tt.steps {
+				if s.do == "cool" {
+					exp.breaker.openedAt = time.Now().Add(-time.Hour)
+					continue
+				}
+				exported, fellBack := primary.exports, fallback.exports
+				primary.fail = s.do == "fail"
+				exp.ExportSpans(context.Background(), spans)
+				if got := primary.exports > exported; got != s.wantExported {
+					t.Errorf("step %d: exported = %v, want %v", i, got, s.wantExported)
+				}
+				if got := fallback.exports > fellBack; got != s.wantFallback {
+					t.Errorf("step %d: fallback = %v, want %v", i, got, s.wantFallback)
+				}
+				if exp.breaker.state != s.wantState {
+					t.Errorf("step %d: state = %v, want %v", i, exp.breaker.state, s.wantState)
+				}
+			}
+		})
+	}
+}
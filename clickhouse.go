@@ -0,0 +1,334 @@
+package telemetry
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// ClickHouseConfig configures the ClickHouse exporters. The tables must
+// exist, following the schema created by the ClickHouse exporter of the
+// OpenTelemetry collector.
+type ClickHouseConfig struct {
+	// DB is a connection opened with the ClickHouse database/sql driver,
+	// e.g. sql.Open("clickhouse", dsn) after importing
+	// github.com/ClickHouse/clickhouse-go/v2.
+	DB *sql.DB
+	// TracesTable is the table spans are inserted into, otel_traces by
+	// default.
+	TracesTable string
+	// MetricsTable is the prefix of the tables metrics are inserted into,
+	// otel_metrics by default for otel_metrics_sum, otel_metrics_gauge and
+	// otel_metrics_histogram.
+	MetricsTable string
+}
+
+// ClickHouseSpanExporter is a span exporter inserting spans straight into
+// ClickHouse, for self-hosted setups without a collector. Pass it to
+// WithSpanExporter.
+type ClickHouseSpanExporter struct {
+	db    *sql.DB
+	table string
+}
+
+// NewClickHouseSpanExporter returns an exporter inserting spans into the
+// traces table of c.
+func NewClickHouseSpanExporter(c ClickHouseConfig) *ClickHouseSpanExporter {
+	if c.TracesTable == "" {
+		c.TracesTable = "otel_traces"
+	}
+	return &ClickHouseSpanExporter{db: c.DB, table: c.TracesTable}
+}
+
+var clickHouseSpanColumns = []string{
+	"Timestamp", "TraceId", "SpanId", "ParentSpanId", "TraceState",
+	"SpanName", "SpanKind", "ServiceName", "ResourceAttributes",
+	"ScopeName", "ScopeVersion", "SpanAttributes", "Duration",
+	"StatusCode", "StatusMessage",
+	"Events.Timestamp", "Events.Name", "Events.Attributes",
+	"Links.TraceId", "Links.SpanId", "Links.TraceState", "Links.Attributes",
+}
+
+// ExportSpans inserts spans in a single batch.
+func (e *ClickHouseSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	return clickHouseInsert(ctx, e.db, e.table, clickHouseSpanColumns, func(insert func(...any) error) error {
+		for _, s := range spans {
+			eventTimes := make([]time.Time, 0, len(s.Events()))
+			eventNames := make([]string, 0, len(s.Events()))
+			eventAttrs := make([]map[string]string, 0, len(s.Events()))
+			for _, ev := range s.Events() {
+				eventTimes = append(eventTimes, ev.Time)
+				eventNames = append(eventNames, ev.Name)
+				eventAttrs = append(eventAttrs, clickHouseMap(ev.Attributes))
+			}
+			linkTraces := make([]string, 0, len(s.Links()))
+			linkSpans := make([]string, 0, len(s.Links()))
+			linkStates := make([]string, 0, len(s.Links()))
+			linkAttrs := make([]map[string]string, 0, len(s.Links()))
+			for _, l := range s.Links() {
+				linkTraces = append(linkTraces, l.SpanContext.TraceID().String())
+				linkSpans = append(linkSpans, l.SpanContext.SpanID().String())
+				linkStates = append(linkStates, l.SpanContext.TraceState().String())
+				linkAttrs = append(linkAttrs, clickHouseMap(l.Attributes))
+			}
+
+			var parent string
+			if s.Parent().SpanID().IsValid() {
+				parent = s.Parent().SpanID().String()
+			}
+			err := insert(
+				s.StartTime(),
+				s.SpanContext().TraceID().String(),
+				s.SpanContext().SpanID().String(),
+				parent,
+				s.SpanContext().TraceState().String(),
+				s.Name(),
+				clickHouseSpanKind(s.SpanKind()),
+				clickHouseServiceName(s.Resource()),
+				clickHouseMap(s.Resource().Attributes()),
+				s.InstrumentationScope().Name,
+				s.InstrumentationScope().Version,
+				clickHouseMap(s.Attributes()),
+				uint64(max(s.EndTime().Sub(s.StartTime()), 0)),
+				clickHouseStatusCode(s.Status().Code),
+				s.Status().Description,
+				eventTimes, eventNames, eventAttrs,
+				linkTraces, linkSpans, linkStates, linkAttrs,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Shutdown does nothing; the database is owned by the caller.
+func (e *ClickHouseSpanExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// ClickHouseMetricExporter is a metric exporter inserting sums, gauges and
+// histograms straight into ClickHouse. Exponential histograms and summaries
+// are skipped. Pass it to WithMetricExporter.
+type ClickHouseMetricExporter struct {
+	db     *sql.DB
+	prefix string
+}
+
+// NewClickHouseMetricExporter returns an exporter inserting metrics into the
+// metrics tables of c.
+func NewClickHouseMetricExporter(c ClickHouseConfig) *ClickHouseMetricExporter {
+	if c.MetricsTable == "" {
+		c.MetricsTable = "otel_metrics"
+	}
+	return &ClickHouseMetricExporter{db: c.DB, prefix: c.MetricsTable}
+}
+
+// Temporality returns the default temporality for kind.
+func (e *ClickHouseMetricExporter) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	return sdkmetric.DefaultTemporalitySelector(kind)
+}
+
+// Aggregation returns the default aggregation for kind.
+func (e *ClickHouseMetricExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+var (
+	clickHouseMetricColumns = []string{
+		"ResourceAttributes", "ResourceSchemaUrl", "ScopeName", "ScopeVersion",
+		"ServiceName", "MetricName", "MetricDescription", "MetricUnit",
+		"Attributes", "StartTimeUnix", "TimeUnix",
+	}
+	clickHouseSumColumns       = slices.Concat(clickHouseMetricColumns, []string{"Value", "AggregationTemporality", "IsMonotonic"})
+	clickHouseGaugeColumns     = slices.Concat(clickHouseMetricColumns, []string{"Value"})
+	clickHouseHistogramColumns = slices.Concat(clickHouseMetricColumns, []string{
+		"Count", "Sum", "BucketCounts", "ExplicitBounds", "Min", "Max", "AggregationTemporality",
+	})
+)
+
+// Export inserts the data points of rm, one batch per table.
+func (e *ClickHouseMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	var sums, gauges, histograms [][]any
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			common := func(attrs attribute.Set, start, ts time.Time) []any {
+				return []any{
+					clickHouseMap(rm.Resource.Attributes()), rm.Resource.SchemaURL(),
+					sm.Scope.Name, sm.Scope.Version,
+					clickHouseServiceName(rm.Resource), m.Name, m.Description, m.Unit,
+					clickHouseMap(attrs.ToSlice()), start, ts,
+				}
+			}
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					sums = append(sums, append(common(dp.Attributes, dp.StartTime, dp.Time), float64(dp.Value), clickHouseTemporality(data.Temporality), data.IsMonotonic))
+				}
+			case metricdata.Sum[float64]:
+				for _, dp := range data.DataPoints {
+					sums = append(sums, append(common(dp.Attributes, dp.StartTime, dp.Time), dp.Value, clickHouseTemporality(data.Temporality), data.IsMonotonic))
+				}
+			case metricdata.Gauge[int64]:
+				for _, dp := range data.DataPoints {
+					gauges = append(gauges, append(common(dp.Attributes, dp.StartTime, dp.Time), float64(dp.Value)))
+				}
+			case metricdata.Gauge[float64]:
+				for _, dp := range data.DataPoints {
+					gauges = append(gauges, append(common(dp.Attributes, dp.StartTime, dp.Time), dp.Value))
+				}
+			case metricdata.Histogram[int64]:
+				for _, dp := range data.DataPoints {
+					histograms = append(histograms, append(common(dp.Attributes, dp.StartTime, dp.Time), clickHouseHistogram(dp, data.Temporality)...))
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					histograms = append(histograms, append(common(dp.Attributes, dp.StartTime, dp.Time), clickHouseHistogram(dp, data.Temporality)...))
+				}
+			}
+		}
+	}
+
+	var errs []error
+	for _, batch := range []struct {
+		table   string
+		columns []string
+		rows    [][]any
+	}{
+		{e.prefix + "_sum", clickHouseSumColumns, sums},
+		{e.prefix + "_gauge", clickHouseGaugeColumns, gauges},
+		{e.prefix + "_histogram", clickHouseHistogramColumns, histograms},
+	} {
+		if len(batch.rows) == 0 {
+			continue
+		}
+		errs = append(errs, clickHouseInsert(ctx, e.db, batch.table, batch.columns, func(insert func(...any) error) error {
+			for _, row := range batch.rows {
+				if err := insert(row...); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+// ForceFlush does nothing; the exporter holds no state.
+func (e *ClickHouseMetricExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+// Shutdown does nothing; the database is owned by the caller.
+func (e *ClickHouseMetricExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// clickHouseInsert inserts the rows passed by rows to insert into table as a
+// single batch: the ClickHouse driver sends the statements executed in a
+// transaction when it commits.
+func clickHouseInsert(ctx context.Context, db *sql.DB, table string, columns []string, rows func(insert func(...any) error) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("telemetry: ClickHouse insert into %s failed: %w", table, err)
+	}
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s (%s)", table, strings.Join(columns, ", ")))
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("telemetry: ClickHouse insert into %s failed: %w", table, err)
+	}
+	defer stmt.Close()
+
+	err = rows(func(values ...any) error {
+		_, err := stmt.ExecContext(ctx, values...)
+		return err
+	})
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("telemetry: ClickHouse insert into %s failed: %w", table, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("telemetry: ClickHouse insert into %s failed: %w", table, err)
+	}
+	return nil
+}
+
+// clickHouseHistogram returns the histogram columns of dp.
+func clickHouseHistogram[N int64 | float64](dp metricdata.HistogramDataPoint[N], temporality metricdata.Temporality) []any {
+	var minimum, maximum float64
+	if v, ok := dp.Min.Value(); ok {
+		minimum = float64(v)
+	}
+	if v, ok := dp.Max.Value(); ok {
+		maximum = float64(v)
+	}
+	return []any{dp.Count, float64(dp.Sum), dp.BucketCounts, dp.Bounds, minimum, maximum, clickHouseTemporality(temporality)}
+}
+
+// clickHouseMap converts attributes to a Map(String, String) value.
+func clickHouseMap(attrs []attribute.KeyValue) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, kv := range attrs {
+		m[string(kv.Key)] = kv.Value.Emit()
+	}
+	return m
+}
+
+func clickHouseServiceName(res *resource.Resource) string {
+	v, _ := res.Set().Value(semconv.ServiceNameKey)
+	return v.AsString()
+}
+
+// clickHouseSpanKind returns the name the collector uses for kind.
+func clickHouseSpanKind(kind oteltrace.SpanKind) string {
+	switch kind {
+	case oteltrace.SpanKindInternal:
+		return "Internal"
+	case oteltrace.SpanKindServer:
+		return "Server"
+	case oteltrace.SpanKindClient:
+		return "Client"
+	case oteltrace.SpanKindProducer:
+		return "Producer"
+	case oteltrace.SpanKindConsumer:
+		return "Consumer"
+	}
+	return "Unspecified"
+}
+
+// clickHouseStatusCode returns the name the collector uses for code.
+func clickHouseStatusCode(code codes.Code) string {
+	switch code {
+	case codes.Ok:
+		return "Ok"
+	case codes.Error:
+		return "Error"
+	}
+	return "Unset"
+}
+
+// clickHouseTemporality returns the protobuf enum value of temporality the
+// collector stores.
+func clickHouseTemporality(temporality metricdata.Temporality) int32 {
+	switch temporality {
+	case metricdata.DeltaTemporality:
+		return 1
+	case metricdata.CumulativeTemporality:
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,266 @@
+package telemetry
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// fakeClickHouse is a database/sql driver recording the rows inserted by the
+// committed transactions, failing the inserts with err if set.
+type fakeClickHouse struct {
+	mu        sync.Mutex
+	err       error
+	committed map[string][][]any
+	rollbacks int
+}
+
+func (db *fakeClickHouse) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{db: db}, nil
+}
+func (db *fakeClickHouse) Driver() driver.Driver { return nil }
+
+// rows returns the committed rows of table, by column name.
+func (db *fakeClickHouse) rows(table string) []map[string]any {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var rows []map[string]any
+	for query, values := range db.committed {
+		if !strings.HasPrefix(query, "INSERT INTO "+table+" (") {
+			continue
+		}
+		columns := strings.Split(strings.TrimSuffix(strings.TrimPrefix(query, "INSERT INTO "+table+" ("), ")"), ", ")
+		for _, v := range values {
+			row := make(map[string]any, len(columns))
+			for i, c := range columns {
+				row[c] = v[i]
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+type fakeConn struct {
+	db      *fakeClickHouse
+	pending map[string][][]any
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c: c, query: query}, nil
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.pending = make(map[string][][]any)
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	if c.db.committed == nil {
+		c.db.committed = make(map[string][][]any)
+	}
+	for query, rows := range c.pending {
+		c.db.committed[query] = append(c.db.committed[query], rows...)
+	}
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.rollbacks++
+	return nil
+}
+
+type fakeStmt struct {
+	c     *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+// CheckNamedValue accepts the arrays and maps of the ClickHouse driver.
+func (s *fakeStmt) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.db.mu.Lock()
+	err := s.c.db.err
+	s.c.db.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if n := strings.Count(s.query, ",") + 1; n != len(args) {
+		return nil, errors.New("column count mismatch")
+	}
+	row := make([]any, len(args))
+	for i, v := range args {
+		row[i] = v
+	}
+	s.c.pending[s.query] = append(s.c.pending[s.query], row)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestClickHouseSpanExporter(t *testing.T) {
+	fake := &fakeClickHouse{}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+	exporter := NewClickHouseSpanExporter(ClickHouseConfig{DB: db})
+
+	start := time.Unix(1700000000, 0)
+	traceID := oteltrace.TraceID{1}
+	parent := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: oteltrace.SpanID{1}})
+	linked := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: oteltrace.TraceID{2}, SpanID: oteltrace.SpanID{3}})
+	spans := tracetest.SpanStubs{{
+		Name:                 "GET /orders",
+		SpanContext:          oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: oteltrace.SpanID{2}}),
+		Parent:               parent,
+		SpanKind:             oteltrace.SpanKindServer,
+		StartTime:            start,
+		EndTime:              start.Add(1500 * time.Microsecond),
+		Attributes:           []attribute.KeyValue{attribute.Int("http.response.status_code", 500)},
+		Events:               []trace.Event{{Name: "retry", Time: start.Add(time.Millisecond)}},
+		Links:                []trace.Link{{SpanContext: linked, Attributes: []attribute.KeyValue{attribute.String("kind", "batch")}}},
+		Status:               trace.Status{Code: codes.Error, Description: "internal"},
+		Resource:             resource.NewSchemaless(semconv.ServiceName("checkout")),
+		InstrumentationScope: instrumentation.Scope{Name: "net/http", Version: "1.0"},
+	}}.Snapshots()
+	if err := exporter.ExportSpans(context.Background(), spans); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := fake.rows("otel_traces")
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(rows))
+	}
+	want := map[string]any{
+		"Timestamp":          start,
+		"TraceId":            traceID.String(),
+		"SpanId":             oteltrace.SpanID{2}.String(),
+		"ParentSpanId":       oteltrace.SpanID{1}.String(),
+		"TraceState":         "",
+		"SpanName":           "GET /orders",
+		"SpanKind":           "Server",
+		"ServiceName":        "checkout",
+		"ResourceAttributes": map[string]string{"service.name": "checkout"},
+		"ScopeName":          "net/http",
+		"ScopeVersion":       "1.0",
+		"SpanAttributes":     map[string]string{"http.response.status_code": "500"},
+		"Duration":           uint64(1500000),
+		"StatusCode":         "Error",
+		"StatusMessage":      "internal",
+		"Events.Timestamp":   []time.Time{start.Add(time.Millisecond)},
+		"Events.Name":        []string{"retry"},
+		"Events.Attributes":  []map[string]string{{}},
+		"Links.TraceId":      []string{linked.TraceID().String()},
+		"Links.SpanId":       []string{linked.SpanID().String()},
+		"Links.TraceState":   []string{""},
+		"Links.Attributes":   []map[string]string{{"kind": "batch"}},
+	}
+	if !reflect.DeepEqual(rows[0], want) {
+		t.Errorf("got row\n%v\nwant\n%v", rows[0], want)
+	}
+
+	insertErr := errors.New("table is read-only")
+	fake.err = insertErr
+	if err := exporter.ExportSpans(context.Background(), spans); !errors.Is(err, insertErr) {
+		t.Errorf("got %v, want %v", err, insertErr)
+	}
+	if fake.rollbacks != 1 {
+		t.Errorf("got %d rollbacks, want 1", fake.rollbacks)
+	}
+	if rows := fake.rows("otel_traces"); len(rows) != 1 {
+		t.Errorf("failed insert committed rows: got %d rows, want 1", len(rows))
+	}
+}
+
+func TestClickHouseMetricExporter(t *testing.T) {
+	fake := &fakeClickHouse{}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+	exporter := NewClickHouseMetricExporter(ClickHouseConfig{DB: db, MetricsTable: "metrics"})
+
+	start, now := time.Unix(1700000000, 0), time.Unix(1700000060, 0)
+	attrs := attribute.NewSet(attribute.String("route", "/orders"))
+	rm := &metricdata.ResourceMetrics{
+		Resource: resource.NewSchemaless(semconv.ServiceName("checkout")),
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Scope: instrumentation.Scope{Name: "app"},
+			Metrics: []metricdata.Metrics{
+				{Name: "requests", Unit: "{request}", Data: metricdata.Sum[int64]{
+					Temporality: metricdata.CumulativeTemporality,
+					IsMonotonic: true,
+					DataPoints:  []metricdata.DataPoint[int64]{{Attributes: attrs, StartTime: start, Time: now, Value: 7}},
+				}},
+				{Name: "queue", Data: metricdata.Gauge[float64]{
+					DataPoints: []metricdata.DataPoint[float64]{{Time: now, Value: 2.5}},
+				}},
+				{Name: "latency", Unit: "s", Data: metricdata.Histogram[float64]{
+					Temporality: metricdata.DeltaTemporality,
+					DataPoints: []metricdata.HistogramDataPoint[float64]{{
+						StartTime: start, Time: now, Count: 3, Sum: 0.6,
+						Bounds: []float64{0.1, 1}, BucketCounts: []uint64{1, 2, 0},
+						Min: metricdata.NewExtrema(0.05), Max: metricdata.NewExtrema(0.3),
+					}},
+				}},
+				{Name: "sizes", Data: metricdata.ExponentialHistogram[int64]{}},
+			},
+		}},
+	}
+	if err := exporter.Export(context.Background(), rm); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		table string
+		want  map[string]any
+	}{
+		{"metrics_sum", map[string]any{
+			"MetricName": "requests", "MetricUnit": "{request}", "Attributes": map[string]string{"route": "/orders"},
+			"StartTimeUnix": start, "TimeUnix": now, "Value": 7.0, "AggregationTemporality": int32(2), "IsMonotonic": true,
+		}},
+		{"metrics_gauge", map[string]any{"MetricName": "queue", "Attributes": map[string]string{}, "Value": 2.5}},
+		{"metrics_histogram", map[string]any{
+			"MetricName": "latency", "Count": uint64(3), "Sum": 0.6, "BucketCounts": []uint64{1, 2, 0},
+			"ExplicitBounds": []float64{0.1, 1}, "Min": 0.05, "Max": 0.3, "AggregationTemporality": int32(1),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			rows := fake.rows(tt.table)
+			if len(rows) != 1 {
+				t.Fatalf("got %d rows, want 1", len(rows))
+			}
+			if got := rows[0]["ServiceName"]; got != "checkout" {
+				t.Errorf("ServiceName = %v, want checkout", got)
+			}
+			for column, want := range tt.want {
+				if got := rows[0][column]; !reflect.DeepEqual(got, want) {
+					t.Errorf("%s = %#v, want %#v", column, got, want)
+				}
+			}
+		})
+	}
+}
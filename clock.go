@@ -0,0 +1,136 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Clock is the time source of the pipeline.
+type Clock interface {
+	Now() time.Time
+}
+
+// WithClock sets the time source used for span, event and log record
+// timestamps and for the timestamps of exported metric data points, so tests
+// of exported data are reproducible. Timestamps passed explicitly with
+// trace.WithTimestamp take precedence. The schedule of the periodic metric
+// reader still follows the wall clock; use SetupOTelSDKManualReader to
+// control when metrics are collected.
+func WithClock(clock Clock) Option {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
+
+// clockTracerProvider hands out tracers timestamping spans with clock.
+type clockTracerProvider struct {
+	*sdktrace.TracerProvider
+	clock Clock
+}
+
+func (p clockTracerProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	return clockTracer{Tracer: p.TracerProvider.Tracer(name, opts...), clock: p.clock}
+}
+
+type clockTracer struct {
+	trace.Tracer
+	clock Clock
+}
+
+func (t clockTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	opts = append([]trace.SpanStartOption{trace.WithTimestamp(t.clock.Now())}, opts...)
+	ctx, span := t.Tracer.Start(ctx, name, opts...)
+	s := clockSpan{Span: span, clock: t.clock}
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+type clockSpan struct {
+	trace.Span
+	clock Clock
+}
+
+func (s clockSpan) End(opts ...trace.SpanEndOption) {
+	s.Span.End(append([]trace.SpanEndOption{trace.WithTimestamp(s.clock.Now())}, opts...)...)
+}
+
+func (s clockSpan) AddEvent(name string, opts ...trace.EventOption) {
+	s.Span.AddEvent(name, append([]trace.EventOption{trace.WithTimestamp(s.clock.Now())}, opts...)...)
+}
+
+func (s clockSpan) RecordError(err error, opts ...trace.EventOption) {
+	s.Span.RecordError(err, append([]trace.EventOption{trace.WithTimestamp(s.clock.Now())}, opts...)...)
+}
+
+// clockStamper is a log processor setting the timestamps of records to the
+// clock's time before they reach the next processors.
+type clockStamper struct {
+	clock Clock
+}
+
+func (c clockStamper) OnEmit(_ context.Context, r *sdklog.Record) error {
+	now := c.clock.Now()
+	r.SetTimestamp(now)
+	r.SetObservedTimestamp(now)
+	return nil
+}
+
+func (clockStamper) Shutdown(context.Context) error   { return nil }
+func (clockStamper) ForceFlush(context.Context) error { return nil }
+
+// clockMetricExporter stamps the data points it exports with the clock's time.
+type clockMetricExporter struct {
+	sdkmetric.Exporter
+	clock Clock
+}
+
+func (e clockMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	stampMetrics(rm, e.clock.Now())
+	return e.Exporter.Export(ctx, rm)
+}
+
+// stampMetrics sets the start and end times of every data point in rm to now.
+func stampMetrics(rm *metricdata.ResourceMetrics, now time.Time) {
+	for i := range rm.ScopeMetrics {
+		for j := range rm.ScopeMetrics[i].Metrics {
+			m := &rm.ScopeMetrics[i].Metrics[j]
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				stampPoints(data.DataPoints, now)
+			case metricdata.Sum[float64]:
+				stampPoints(data.DataPoints, now)
+			case metricdata.Gauge[int64]:
+				stampPoints(data.DataPoints, now)
+			case metricdata.Gauge[float64]:
+				stampPoints(data.DataPoints, now)
+			case metricdata.Histogram[int64]:
+				for k := range data.DataPoints {
+					data.DataPoints[k].StartTime, data.DataPoints[k].Time = now, now
+				}
+			case metricdata.Histogram[float64]:
+				for k := range data.DataPoints {
+					data.DataPoints[k].StartTime, data.DataPoints[k].Time = now, now
+				}
+			case metricdata.ExponentialHistogram[int64]:
+				for k := range data.DataPoints {
+					data.DataPoints[k].StartTime, data.DataPoints[k].Time = now, now
+				}
+			case metricdata.ExponentialHistogram[float64]:
+				for k := range data.DataPoints {
+					data.DataPoints[k].StartTime, data.DataPoints[k].Time = now, now
+				}
+			}
+		}
+	}
+}
+
+func stampPoints[N int64 | float64](points []metricdata.DataPoint[N], now time.Time) {
+	for i := range points {
+		points[i].StartTime, points[i].Time = now, now
+	}
+}
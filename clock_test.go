@@ -0,0 +1,156 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// manualClock is a clock moved forward by the test.
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) advance() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(time.Second)
+}
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// at returns the time seconds after epoch.
+func at(seconds int) time.Time { return epoch.Add(time.Duration(seconds) * time.Second) }
+
+func TestClockTracerProvider(t *testing.T) {
+	clock := &manualClock{now: epoch}
+	recorder := tracetest.NewSpanRecorder()
+	tp := clockTracerProvider{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		clock:          clock,
+	}
+	tracer := tp.Tracer("test")
+
+	explicit := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock.advance()
+	ctx, span := tracer.Start(context.Background(), "checkout")
+	clock.advance()
+	span.AddEvent("validated")
+	clock.advance()
+	trace.SpanFromContext(ctx).RecordError(errors.New("declined"))
+	_, child := tracer.Start(ctx, "charge", trace.WithTimestamp(explicit))
+	clock.advance()
+	child.End()
+	clock.advance()
+	span.End()
+
+	got := make(map[string]sdktrace.ReadOnlySpan)
+	for _, s := range recorder.Ended() {
+		got[s.Name()] = s
+	}
+	if len(got) != 2 || len(got["checkout"].Events()) != 2 {
+		t.Fatalf("got spans %v", got)
+	}
+	tests := []struct {
+		name string
+		got  time.Time
+		want time.Time
+	}{
+		{"span start", got["checkout"].StartTime(), at(1)},
+		{"event", got["checkout"].Events()[0].Time, at(2)},
+		{"error through context", got["checkout"].Events()[1].Time, at(3)},
+		{"explicit start", got["charge"].StartTime(), explicit},
+		{"child end", got["charge"].EndTime(), at(4)},
+		{"span end", got["checkout"].EndTime(), at(5)},
+	}
+	for _, tt := range tests {
+		if !tt.got.Equal(tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestClockStamper(t *testing.T) {
+	var r sdklog.Record
+	r.SetTimestamp(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err := (clockStamper{clock: &manualClock{now: epoch}}).OnEmit(context.Background(), &r); err != nil {
+		t.Fatal(err)
+	}
+	if !r.Timestamp().Equal(epoch) || !r.ObservedTimestamp().Equal(epoch) {
+		t.Errorf("timestamps = %v, %v, want %v", r.Timestamp(), r.ObservedTimestamp(), epoch)
+	}
+}
+
+func TestStampMetrics(t *testing.T) {
+	rm := metricdata.ResourceMetrics{ScopeMetrics: []metricdata.ScopeMetrics{{
+		Metrics: []metricdata.Metrics{
+			{Name: "sum", Data: metricdata.Sum[int64]{DataPoints: []metricdata.DataPoint[int64]{{Value: 1}}}},
+			{Name: "gauge", Data: metricdata.Gauge[float64]{DataPoints: []metricdata.DataPoint[float64]{{Value: 1}}}},
+			{Name: "histogram", Data: metricdata.Histogram[float64]{DataPoints: []metricdata.HistogramDataPoint[float64]{{Count: 1}}}},
+			{Name: "exponential", Data: metricdata.ExponentialHistogram[int64]{DataPoints: []metricdata.ExponentialHistogramDataPoint[int64]{{Count: 1}}}},
+		},
+	}}}
+	stampMetrics(&rm, epoch)
+
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		var start, end time.Time
+		switch data := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			start, end = data.DataPoints[0].StartTime, data.DataPoints[0].Time
+		case metricdata.Gauge[float64]:
+			start, end = data.DataPoints[0].StartTime, data.DataPoints[0].Time
+		case metricdata.Histogram[float64]:
+			start, end = data.DataPoints[0].StartTime, data.DataPoints[0].Time
+		case metricdata.ExponentialHistogram[int64]:
+			start, end = data.DataPoints[0].StartTime, data.DataPoints[0].Time
+		}
+		if !start.Equal(epoch) || !end.Equal(epoch) {
+			t.Errorf("%s: points at %v-%v, want %v", m.Name, start, end, epoch)
+		}
+	}
+}
+
+func TestSetupOTelSDKManualReaderClock(t *testing.T) {
+	ctx := context.Background()
+	clock := &manualClock{now: epoch}
+	collect, shutdown, err := SetupOTelSDKManualReader(ctx, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(ctx)
+
+	counter, _ := otel.Meter("test").Int64Counter("orders")
+	counter.Add(ctx, 1)
+	clock.advance()
+	rm, err := collect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var points []metricdata.DataPoint[int64]
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == "orders" {
+				points = m.Data.(metricdata.Sum[int64]).DataPoints
+			}
+		}
+	}
+	if len(points) != 1 || !points[0].Time.Equal(at(1)) || !points[0].StartTime.Equal(at(1)) {
+		t.Errorf("points = %+v, want one at %v", points, at(1))
+	}
+}
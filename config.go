@@ -0,0 +1,240 @@
+package telemetry
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+)
+
+// config holds the settings used to build the telemetry pipeline.
+type config struct {
+	attributeValueLimit   int
+	auditExporter         sdklog.Exporter
+	breakerCooldown       time.Duration
+	breakerFailures       int
+	clock                 Clock
+	connStateCallbacks    []func(connectivity.State)
+	collectorSRV          string
+	collectorSRVRefresh   time.Duration
+	connectMode           ConnectMode
+	connectRetryMaxWait   time.Duration
+	connectTimeout        time.Duration
+	debugExporter         func() bool
+	detectors             []resource.Detector
+	endpoint              string
+	exportLogs            func() bool
+	exportMetrics         func() bool
+	exportTraces          func() bool
+	fallbackLog           sdklog.Exporter
+	fallbackMetric        sdkmetric.Exporter
+	fallbackSpan          trace.SpanExporter
+	grpcClientError       func(codes.Code) bool
+	grpcServerError       func(codes.Code) bool
+	httpClientError       func(int) bool
+	httpServerError       func(int) bool
+	logExportTimeout      time.Duration
+	kafkaBrokers          []string
+	kafkaTopic            string
+	logExporters          []sdklog.Exporter
+	logMaxAttributes      int
+	logMaxBodyBytes       int
+	logMinSeverity        log.Severity
+	logRotation           LogRotation
+	logShutdownTimeout    time.Duration
+	lokiURL               string
+	memoryLimit           int64
+	memoryPolicy          MemoryPolicy
+	metricExportTimeout   time.Duration
+	metricExporters       []sdkmetric.Exporter
+	metricRules           []MetricRule
+	metricShutdownTimeout time.Duration
+	peerServices          map[string]string
+	recentSpans           int
+	pprofLabels           bool
+	privacy               *privacyFilter
+	propagators           []string
+	profilingInterval     time.Duration
+	profilingURL          string
+	pushgatewayJob        string
+	pushgatewayURL        string
+	remoteWriteURL        string
+	resourceAttrs         []attribute.KeyValue
+	resources             []*resource.Resource
+	sampler               trace.Sampler
+	samplingRules         []SamplingRule
+	simpleSpanProcessor   bool
+	spanExporters         []trace.SpanExporter
+	stdoutCompact         bool
+	stdoutDeterministic   bool
+	stdoutLogs            io.Writer
+	stdoutLogsFile        string
+	stdoutMetrics         io.Writer
+	stdoutMetricsFile     string
+	stdoutTraces          io.Writer
+	stdoutTracesFile      string
+	spanNameNormalizer    func(string) string
+	tenantExporters       map[string]trace.SpanExporter
+	traceExportTimeout    time.Duration
+	traceShutdownTimeout  time.Duration
+	verbosity             Verbosity
+}
+
+// Option configures the telemetry pipeline set up by SetupOTelSDKStdout or
+// SetupOTelSDKGrpc.
+type Option func(*config)
+
+// newConfig returns the settings read from the environment, overridden by opts.
+func newConfig(opts []Option) config {
+	c := config{
+		attributeValueLimit: envInt("TELEMETRY_ATTRIBUTE_VALUE_LIMIT", 0),
+		breakerCooldown:     envDuration("TELEMETRY_EXPORT_BREAKER_COOLDOWN", defaultBreakerCooldown),
+		breakerFailures:     envInt("TELEMETRY_EXPORT_BREAKER_FAILURES", 0),
+		collectorSRV:        os.Getenv("TELEMETRY_COLLECTOR_SRV"),
+		collectorSRVRefresh: envDuration("TELEMETRY_COLLECTOR_SRV_REFRESH", 0),
+		connectMode:         envConnectMode("TELEMETRY_CONNECT_MODE"),
+		connectRetryMaxWait: envDuration("TELEMETRY_CONNECT_RETRY_MAX_WAIT", 0),
+		connectTimeout:      envDuration("TELEMETRY_CONNECT_TIMEOUT", defaultConnectTimeout),
+		detectors:           envDetectors("TELEMETRY_RESOURCE_DETECTORS"),
+		endpoint:            envString("OTEL_EXPORTER_OTLP_ENDPOINT", defaultEndpoint),
+		grpcClientError:     envGRPCCodes("TELEMETRY_GRPC_CLIENT_ERROR_CODES", defaultGRPCClientError),
+		grpcServerError:     envGRPCCodes("TELEMETRY_GRPC_SERVER_ERROR_CODES", defaultGRPCServerError),
+		httpClientError:     envStatusRanges("TELEMETRY_HTTP_CLIENT_ERROR_STATUSES", defaultHTTPClientError),
+		httpServerError:     envStatusRanges("TELEMETRY_HTTP_SERVER_ERROR_STATUSES", defaultHTTPServerError),
+		kafkaBrokers:        envList("TELEMETRY_KAFKA_BROKERS"),
+		kafkaTopic:          os.Getenv("TELEMETRY_KAFKA_TOPIC"),
+		logMaxAttributes:    envInt("TELEMETRY_LOG_MAX_ATTRIBUTES", 0),
+		logMaxBodyBytes:     envInt("TELEMETRY_LOG_MAX_BODY_BYTES", 0),
+		logMinSeverity:      envSeverity("TELEMETRY_LOGS_MIN_SEVERITY"),
+		logRotation:         envLogRotation(),
+		lokiURL:             os.Getenv("TELEMETRY_LOKI_URL"),
+		memoryLimit:         int64(envInt("TELEMETRY_MEMORY_LIMIT_MIB", 0)) << 20,
+		memoryPolicy:        envMemoryPolicy("TELEMETRY_MEMORY_LIMIT_POLICY"),
+		metricRules:         envMetricRules("TELEMETRY_METRIC_RULES"),
+		peerServices:        envPeerServices("TELEMETRY_PEER_SERVICES"),
+		recentSpans:         envInt("TELEMETRY_RECENT_SPANS", 0),
+		pprofLabels:         envBool("TELEMETRY_PPROF_LABELS"),
+		privacy:             envPrivacy(),
+		propagators:         envPropagators("OTEL_PROPAGATORS"),
+		profilingInterval:   envDuration("TELEMETRY_PROFILING_INTERVAL", defaultProfilingInterval),
+		profilingURL:        os.Getenv("TELEMETRY_PYROSCOPE_URL"),
+		pushgatewayJob:      os.Getenv("TELEMETRY_PUSHGATEWAY_JOB"),
+		pushgatewayURL:      os.Getenv("TELEMETRY_PUSHGATEWAY_URL"),
+		remoteWriteURL:      os.Getenv("TELEMETRY_PROMETHEUS_REMOTE_WRITE_URL"),
+		sampler:             envSampler(),
+		samplingRules:       envSamplingRules("TELEMETRY_SAMPLING_RULES"),
+		simpleSpanProcessor: envBool("TELEMETRY_SIMPLE_SPAN_PROCESSOR"),
+		stdoutCompact:       envBool("TELEMETRY_STDOUT_COMPACT"),
+		stdoutLogsFile:      os.Getenv("TELEMETRY_STDOUT_LOGS_FILE"),
+		stdoutMetricsFile:   os.Getenv("TELEMETRY_STDOUT_METRICS_FILE"),
+		stdoutTracesFile:    os.Getenv("TELEMETRY_STDOUT_TRACES_FILE"),
+		verbosity:           envVerbosity("TELEMETRY_VERBOSITY"),
+	}
+	if envBool("TELEMETRY_NORMALIZE_SPAN_NAMES") {
+		c.spanNameNormalizer = NormalizeSpanName
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// envSeverity reads a log severity name from the environment variable key.
+func envSeverity(key string) log.Severity {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return log.SeverityUndefined
+	}
+	sev, err := ParseSeverity(v)
+	if err != nil {
+		otel.Handle(err)
+	}
+	return sev
+}
+
+// envMetricRules reads metric rename and drop rules from the environment
+// variable key.
+func envMetricRules(key string) []MetricRule {
+	rules, err := ParseMetricRules(os.Getenv(key))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return rules
+}
+
+// envInt reads an integer from the environment variable key, returning def if
+// it is unset or invalid.
+func envInt(key string, def int) int {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		otel.Handle(err)
+		return def
+	}
+	return n
+}
+
+// envBool reads a boolean from the environment variable key, returning false
+// if it is unset or invalid.
+func envBool(key string) bool {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		otel.Handle(err)
+		return false
+	}
+	return b
+}
+
+// envString reads the environment variable key, returning def if it is unset
+// or empty.
+func envString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// envList reads a comma-separated list from the environment variable key,
+// skipping empty items.
+func envList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// envDuration reads a duration from the environment variable key, returning
+// def if it is unset or invalid.
+func envDuration(key string, def time.Duration) time.Duration {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		otel.Handle(err)
+		return def
+	}
+	return d
+}
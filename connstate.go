@@ -0,0 +1,70 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// connectivityStates are the states reported by the connection state gauge.
+var connectivityStates = []connectivity.State{
+	connectivity.Idle,
+	connectivity.Connecting,
+	connectivity.Ready,
+	connectivity.TransientFailure,
+	connectivity.Shutdown,
+}
+
+// WithConnectionStateCallback registers fn to be called with the new state
+// whenever the collector connection of SetupOTelSDKGrpc changes state, e.g.
+// to alert when a service loses its telemetry path. Callbacks run on a
+// single goroutine and should not block.
+func WithConnectionStateCallback(fn func(connectivity.State)) Option {
+	return func(c *config) {
+		c.connStateCallbacks = append(c.connStateCallbacks, fn)
+	}
+}
+
+// watchConnState reports the state of conn through the
+// telemetry.exporter.connection.state gauge, which is 1 for the current
+// state and 0 for the others, and calls callbacks on every change until ctx
+// is done or conn is closed.
+func watchConnState(ctx context.Context, mp metric.MeterProvider, conn *grpc.ClientConn, callbacks []func(connectivity.State)) error {
+	gauge, err := mp.Meter(instrumentationName).Int64ObservableGauge("telemetry.exporter.connection.state",
+		metric.WithUnit("1"),
+		metric.WithDescription("State of the connection to the collector; 1 for the current state."))
+	if err != nil {
+		return err
+	}
+	_, err = mp.Meter(instrumentationName).RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		current := conn.GetState()
+		for _, state := range connectivityStates {
+			var v int64
+			if state == current {
+				v = 1
+			}
+			o.ObserveInt64(gauge, v, metric.WithAttributes(attribute.String("state", state.String())))
+		}
+		return nil
+	}, gauge)
+	if err != nil {
+		return err
+	}
+
+	if len(callbacks) == 0 {
+		return nil
+	}
+	go func() {
+		state := conn.GetState()
+		for state != connectivity.Shutdown && conn.WaitForStateChange(ctx, state) {
+			state = conn.GetState()
+			for _, fn := range callbacks {
+				fn(state)
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,84 @@
+package telemetry
+
+import (
+	"context"
+	"maps"
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// connStates returns the value of the connection state gauge per state.
+func connStates(t *testing.T, reader *sdkmetric.ManualReader) map[string]int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	states := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "telemetry.exporter.connection.state" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Gauge[int64]).DataPoints {
+				state, _ := dp.Attributes.Value("state")
+				states[state.AsString()] = dp.Value
+			}
+		}
+	}
+	return states
+}
+
+func TestWatchConnState(t *testing.T) {
+	conn, err := grpc.NewClient(startCollector(t), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer mp.Shutdown(context.Background())
+
+	changes := make(chan connectivity.State, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := watchConnState(ctx, mp, conn, []func(connectivity.State){func(s connectivity.State) { changes <- s }}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]int64{"IDLE": 1, "CONNECTING": 0, "READY": 0, "TRANSIENT_FAILURE": 0, "SHUTDOWN": 0}
+	if got := connStates(t, reader); !maps.Equal(got, want) {
+		t.Errorf("before connecting: states = %v, want %v", got, want)
+	}
+
+	conn.Connect()
+	waitForState(t, changes, connectivity.Ready)
+	want = map[string]int64{"IDLE": 0, "CONNECTING": 0, "READY": 1, "TRANSIENT_FAILURE": 0, "SHUTDOWN": 0}
+	if got := connStates(t, reader); !maps.Equal(got, want) {
+		t.Errorf("once connected: states = %v, want %v", got, want)
+	}
+
+	conn.Close()
+	waitForState(t, changes, connectivity.Shutdown)
+}
+
+// waitForState reads state changes until want is reported.
+func waitForState(t *testing.T, changes <-chan connectivity.State, want connectivity.State) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case s := <-changes:
+			if s == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("callback never reported %v", want)
+		}
+	}
+}
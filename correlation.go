@@ -0,0 +1,59 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// RequestIDHeader is the header carrying the correlation ID.
+	RequestIDHeader = "X-Request-ID"
+	// requestIDKey is the baggage and span attribute key of the correlation ID.
+	requestIDKey = "request.id"
+)
+
+// CorrelationMiddleware links legacy correlation IDs with traces. It reads the
+// X-Request-ID header, or generates an ID from the current trace ID when the
+// header is missing, then stores it in the baggage and as a span attribute
+// and echoes it in the response header. Place it inside Middleware so the
+// server span is already started.
+func CorrelationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = newRequestID(ctx)
+		}
+
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String(requestIDKey, id))
+		if bctx, err := SetBaggage(ctx, requestIDKey, id); err == nil {
+			ctx = bctx
+		} else {
+			otel.Handle(err)
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestID returns the correlation ID stored by CorrelationMiddleware.
+func RequestID(ctx context.Context) string {
+	return GetBaggage(ctx, requestIDKey)
+}
+
+// newRequestID returns the trace ID of ctx, or a random ID if there is none.
+func newRequestID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
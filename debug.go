@@ -0,0 +1,76 @@
+package telemetry
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// debugExporter is the span processor attached by EnableDebugExporter.
+var debugExporter struct {
+	sync.Mutex
+	processor trace.SpanProcessor
+}
+
+// EnableDebugExporter attaches an exporter printing every finished span to w
+// (stdout if nil) to the live tracer provider, in addition to the configured
+// exporters. It lets spans be inspected on a running process without
+// redeploying. The spans are rewritten like the exported ones, such as by
+// WithPrivacy. Calling it again replaces the previous debug exporter.
+func EnableDebugExporter(w io.Writer) error {
+	providers.Lock()
+	tp, cfg := providers.tracer, providers.config
+	providers.Unlock()
+	if tp == nil {
+		return ErrNotInitialized
+	}
+
+	if w == nil {
+		w = os.Stdout
+	}
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(w), stdouttrace.WithPrettyPrint())
+	if err != nil {
+		return err
+	}
+
+	debugExporter.Lock()
+	defer debugExporter.Unlock()
+	if debugExporter.processor != nil {
+		tp.UnregisterSpanProcessor(debugExporter.processor)
+	}
+	debugExporter.processor = trace.NewSimpleSpanProcessor(wrapSpanExporter(*cfg, exporter))
+	tp.RegisterSpanProcessor(debugExporter.processor)
+	return nil
+}
+
+// DisableDebugExporter detaches the exporter attached by EnableDebugExporter.
+func DisableDebugExporter() error {
+	providers.Lock()
+	tp := providers.tracer
+	providers.Unlock()
+	if tp == nil {
+		return ErrNotInitialized
+	}
+
+	debugExporter.Lock()
+	defer debugExporter.Unlock()
+	if debugExporter.processor != nil {
+		tp.UnregisterSpanProcessor(debugExporter.processor)
+		debugExporter.processor = nil
+	}
+	return nil
+}
+
+// WithSimpleSpanProcessor exports every span synchronously as it ends
+// instead of batching, so spans appear immediately and none are lost if the
+// process exits without shutting the pipeline down. It suits debugging and
+// short-lived CLIs but slows down every instrumented operation. It can also
+// be enabled with the TELEMETRY_SIMPLE_SPAN_PROCESSOR environment variable.
+func WithSimpleSpanProcessor() Option {
+	return func(c *config) {
+		c.simpleSpanProcessor = true
+	}
+}
@@ -0,0 +1,62 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ContextSnapshot holds the telemetry identity of a context, its baggage
+// (request ID, ...), tenant, span context and selected values, so that it can
+// be re-applied to contexts that outlive it.
+type ContextSnapshot struct {
+	baggage baggage.Baggage
+	tenant  string
+	span    trace.SpanContext
+	keys    []any
+	values  []any
+}
+
+// Snapshot captures the baggage, tenant and span context of ctx, along with the
+// values of ctx for keys, e.g. before handing work to a goroutine or queue
+// that runs after the request is done.
+func Snapshot(ctx context.Context, keys ...any) ContextSnapshot {
+	s := ContextSnapshot{
+		baggage: baggage.FromContext(ctx),
+		tenant:  Tenant(ctx),
+		span:    trace.SpanContextFromContext(ctx),
+	}
+	for _, k := range keys {
+		if v := ctx.Value(k); v != nil {
+			s.keys = append(s.keys, k)
+			s.values = append(s.values, v)
+		}
+	}
+	return s
+}
+
+// Apply returns a copy of ctx carrying the snapshot. Spans started from it
+// are children of the captured span, and are stamped with its tenant.
+func (s ContextSnapshot) Apply(ctx context.Context) context.Context {
+	if s.baggage.Len() > 0 {
+		ctx = baggage.ContextWithBaggage(ctx, s.baggage)
+	}
+	if s.tenant != "" {
+		ctx = context.WithValue(ctx, tenantKey{}, s.tenant)
+	}
+	if s.span.IsValid() {
+		ctx = trace.ContextWithSpanContext(ctx, s.span)
+	}
+	for i, k := range s.keys {
+		ctx = context.WithValue(ctx, k, s.values[i])
+	}
+	return ctx
+}
+
+// Detach returns a context carrying the snapshot of ctx for keys, but
+// neither its deadline nor its cancellation, for background work started
+// from a request.
+func Detach(ctx context.Context, keys ...any) context.Context {
+	return Snapshot(ctx, keys...).Apply(context.Background())
+}
@@ -0,0 +1,147 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+const (
+	// metadataTimeout bounds each cloud detector, so that setup outside of
+	// that cloud isn't held up by an unreachable metadata endpoint.
+	metadataTimeout = 2 * time.Second
+	// metadataMaxSize bounds the metadata responses read by the detectors.
+	metadataMaxSize = 1 << 20
+)
+
+// detectorFunc adapts a function to the resource.Detector interface.
+type detectorFunc func(context.Context) (*resource.Resource, error)
+
+func (f detectorFunc) Detect(ctx context.Context) (*resource.Resource, error) {
+	return f(ctx)
+}
+
+// HostDetector detects the host.name attribute.
+func HostDetector() resource.Detector {
+	return sdkDetector(resource.WithHost())
+}
+
+// OSDetector detects the os.type and os.description attributes.
+func OSDetector() resource.Detector {
+	return sdkDetector(resource.WithOSType(), resource.WithOSDescription())
+}
+
+// ProcessDetector detects the process.pid, process.executable.* and
+// process.runtime.* attributes. The command line and owner are left out,
+// since they may hold secrets or personal data.
+func ProcessDetector() resource.Detector {
+	return sdkDetector(
+		resource.WithProcessPID(),
+		resource.WithProcessExecutableName(),
+		resource.WithProcessExecutablePath(),
+		resource.WithProcessRuntimeName(),
+		resource.WithProcessRuntimeVersion(),
+		resource.WithProcessRuntimeDescription(),
+	)
+}
+
+// sdkDetector runs the SDK detectors selected by opts.
+func sdkDetector(opts ...resource.Option) resource.Detector {
+	return detectorFunc(func(ctx context.Context) (*resource.Resource, error) {
+		return resource.New(ctx, opts...)
+	})
+}
+
+// detectorsByName are the detectors that can be enabled with the
+// TELEMETRY_RESOURCE_DETECTORS environment variable.
+var detectorsByName = map[string]func() resource.Detector{
+	"host":    HostDetector,
+	"os":      OSDetector,
+	"process": ProcessDetector,
+	"aws_ec2": EC2Detector,
+	"aws_ecs": ECSDetector,
+	"aws_eks": EKSDetector,
+	"gcp":     GCPDetector,
+	"azure":   AzureDetector,
+}
+
+// WithDetectors runs detectors at setup and merges the attributes they find
+// into the resource shared by all signals, e.g.
+// WithDetectors(HostDetector(), ProcessDetector()). Detected attributes take
+// precedence over the SDK defaults but not over WithResource, the
+// environment or WithResourceAttributes. A detector that fails is reported
+// to the global error handler and doesn't fail the setup. Detectors can also
+// be enabled with the TELEMETRY_RESOURCE_DETECTORS environment variable as a
+// comma separated list of names: host, os, process, aws_ec2, aws_ecs,
+// aws_eks, gcp and azure.
+func WithDetectors(detectors ...resource.Detector) Option {
+	return func(c *config) {
+		c.detectors = append(c.detectors, detectors...)
+	}
+}
+
+// envDetectors reads the names of the detectors to enable from the
+// environment variable key.
+func envDetectors(key string) []resource.Detector {
+	var detectors []resource.Detector
+	for _, name := range strings.Split(os.Getenv(key), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		detector, ok := detectorsByName[strings.ToLower(name)]
+		if !ok {
+			otel.Handle(fmt.Errorf("telemetry: unknown resource detector %q", name))
+			continue
+		}
+		detectors = append(detectors, detector())
+	}
+	return detectors
+}
+
+// fetchMetadata sends req to a metadata endpoint and returns the response
+// body.
+func fetchMetadata(req *http.Request) ([]byte, error) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, metadataMaxSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s: %s", req.Method, req.URL.Path, resp.Status)
+	}
+	return body, nil
+}
+
+// detectResource runs detectors and merges their results in order. Failing
+// detectors are reported and skipped, keeping any partial result.
+func detectResource(ctx context.Context, detectors []resource.Detector) *resource.Resource {
+	res := resource.Empty()
+	for _, d := range detectors {
+		r, err := d.Detect(ctx)
+		if err != nil {
+			otel.Handle(fmt.Errorf("telemetry: resource detection: %w", err))
+		}
+		if r == nil {
+			continue
+		}
+		merged, err := mergeResources(res, r)
+		if err != nil {
+			otel.Handle(err)
+			continue
+		}
+		res = merged
+	}
+	return res
+}
@@ -0,0 +1,124 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithDeterministicOutput makes the stdout exporters produce output that can
+// be compared with golden files: timestamps are zeroed and trace and span
+// IDs are replaced with sequence numbers, in the order they are first
+// exported, so that the parent-child relationships and the correlation of
+// log records with spans remain visible. The generated service.instance.id
+// is the nil UUID.
+func WithDeterministicOutput() Option {
+	return func(c *config) {
+		c.stdoutDeterministic = true
+	}
+}
+
+// idRenumberer maps trace and span IDs to sequence numbers.
+type idRenumberer struct {
+	mu     sync.Mutex
+	traces map[trace.TraceID]trace.TraceID
+	spans  map[trace.SpanID]trace.SpanID
+}
+
+func newIDRenumberer() *idRenumberer {
+	return &idRenumberer{
+		traces: make(map[trace.TraceID]trace.TraceID),
+		spans:  make(map[trace.SpanID]trace.SpanID),
+	}
+}
+
+func (r *idRenumberer) traceID(id trace.TraceID) trace.TraceID {
+	if !id.IsValid() {
+		return id
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, ok := r.traces[id]
+	if !ok {
+		binary.BigEndian.PutUint64(n[8:], uint64(len(r.traces)+1))
+		r.traces[id] = n
+	}
+	return n
+}
+
+func (r *idRenumberer) spanID(id trace.SpanID) trace.SpanID {
+	if !id.IsValid() {
+		return id
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, ok := r.spans[id]
+	if !ok {
+		binary.BigEndian.PutUint64(n[:], uint64(len(r.spans)+1))
+		r.spans[id] = n
+	}
+	return n
+}
+
+func (r *idRenumberer) spanContext(sc trace.SpanContext) trace.SpanContext {
+	return sc.WithTraceID(r.traceID(sc.TraceID())).WithSpanID(r.spanID(sc.SpanID()))
+}
+
+// deterministicSpanExporter renumbers the IDs of the spans it exports.
+type deterministicSpanExporter struct {
+	sdktrace.SpanExporter
+	ids *idRenumberer
+}
+
+func (e *deterministicSpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	renumbered := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, s := range spans {
+		renumbered[i] = renumberedSpan{ReadOnlySpan: s, ids: e.ids}
+	}
+	return e.SpanExporter.ExportSpans(ctx, renumbered)
+}
+
+type renumberedSpan struct {
+	sdktrace.ReadOnlySpan
+	ids *idRenumberer
+}
+
+func (s renumberedSpan) SpanContext() trace.SpanContext {
+	return s.ids.spanContext(s.ReadOnlySpan.SpanContext())
+}
+
+func (s renumberedSpan) Parent() trace.SpanContext {
+	return s.ids.spanContext(s.ReadOnlySpan.Parent())
+}
+
+func (s renumberedSpan) Links() []sdktrace.Link {
+	links := s.ReadOnlySpan.Links()
+	renumbered := make([]sdktrace.Link, len(links))
+	for i, l := range links {
+		l.SpanContext = s.ids.spanContext(l.SpanContext)
+		renumbered[i] = l
+	}
+	return renumbered
+}
+
+// deterministicLogExporter renumbers the trace and span IDs of the log
+// records it exports.
+type deterministicLogExporter struct {
+	sdklog.Exporter
+	ids *idRenumberer
+}
+
+func (e *deterministicLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	renumbered := make([]sdklog.Record, len(records))
+	for i, r := range records {
+		r = r.Clone()
+		r.SetTraceID(e.ids.traceID(r.TraceID()))
+		r.SetSpanID(e.ids.spanID(r.SpanID()))
+		renumbered[i] = r
+	}
+	return e.Exporter.Export(ctx, renumbered)
+}
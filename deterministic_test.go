@@ -0,0 +1,76 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// deterministicRun traces a request with a child span and a log record
+// through a deterministic pipeline, returning the trace and log output.
+func deterministicRun(t *testing.T, opts ...Option) (traces, logs string) {
+	t.Helper()
+	ctx := context.Background()
+	var traceBuf, logBuf bytes.Buffer
+	opts = append(opts, WithDeterministicOutput(), WithStdoutWriters(&traceBuf, io.Discard, &logBuf))
+	shutdown, err := SetupOTelSDKStdout(ctx, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, parent := otel.Tracer("test").Start(ctx, "GET /orders")
+	ctx, child := otel.Tracer("test").Start(ctx, "SELECT orders")
+	var record log.Record
+	record.SetBody(log.StringValue("orders listed"))
+	global.GetLoggerProvider().Logger("test").Emit(ctx, record)
+	child.End()
+	parent.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return traceBuf.String(), logBuf.String()
+}
+
+func TestDeterministicOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"pretty", nil},
+		{"compact", []Option{WithCompactJSON()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traces, logs := deterministicRun(t, tt.opts...)
+			againTraces, againLogs := deterministicRun(t, tt.opts...)
+			if traces != againTraces {
+				t.Errorf("trace output differs between runs:\n%s\n%s", traces, againTraces)
+			}
+			if logs != againLogs {
+				t.Errorf("log output differs between runs:\n%s\n%s", logs, againLogs)
+			}
+
+			traceID := "00000000000000000000000000000001"
+			for _, want := range []string{traceID, "0000000000000001", "0000000000000002", "00000000-0000-0000-0000-000000000000"} {
+				if !strings.Contains(traces, want) {
+					t.Errorf("trace output lacks %s:\n%s", want, traces)
+				}
+			}
+			if !strings.Contains(logs, traceID) {
+				t.Errorf("log output lacks the trace ID %s:\n%s", traceID, logs)
+			}
+			if year := strconv.Itoa(time.Now().Year()) + "-"; strings.Contains(traces+logs, year) {
+				t.Errorf("timestamps not zeroed:\n%s\n%s", traces, logs)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// redacted replaces secret values in the output of DumpConfig.
+const redacted = "REDACTED"
+
+// secretEnvMarkers flag the environment variables whose values DumpConfig
+// redacts.
+var secretEnvMarkers = []string{"HEADERS", "KEY", "PASSWORD", "SALT", "SECRET", "TOKEN"}
+
+// configDump is the JSON document written by DumpConfig.
+type configDump struct {
+	Resource      map[string]string `json:"resource"`
+	SchemaURL     string            `json:"schema_url"`
+	Propagators   []string          `json:"propagators"`
+	Sampler       string            `json:"sampler"`
+	Exporters     map[string]string `json:"exporters"`
+	Endpoint      string            `json:"endpoint,omitempty"`
+	ConnectMode   string            `json:"connect_mode,omitempty"`
+	RemoteWrite   string            `json:"remote_write_url,omitempty"`
+	Pushgateway   string            `json:"pushgateway_url,omitempty"`
+	Profiling     string            `json:"profiling_url,omitempty"`
+	LogSeverity   string            `json:"log_min_severity"`
+	MemoryLimit   int64             `json:"memory_limit_bytes,omitempty"`
+	MemoryPolicy  string            `json:"memory_policy,omitempty"`
+	Privacy       string            `json:"privacy_mode,omitempty"`
+	Verbosity     string            `json:"verbosity"`
+	ExportTimeout map[string]string `json:"export_timeouts,omitempty"`
+	Environment   map[string]string `json:"environment"`
+}
+
+// DumpConfig writes the configuration resolved by the last setup to w as
+// indented JSON: the resource attributes, propagators, sampler, exporters
+// and endpoints, along with the OTEL_* and TELEMETRY_* environment
+// variables. Credentials in URLs and the values of variables that look like
+// secrets, such as OTEL_EXPORTER_OTLP_HEADERS, are redacted. It helps
+// telling why telemetry doesn't show up where expected.
+func DumpConfig(w io.Writer) error {
+	providers.Lock()
+	cfg, res, exp := providers.config, providers.resource, providers.exporters
+	providers.Unlock()
+	if cfg == nil {
+		return ErrNotInitialized
+	}
+
+	d := configDump{
+		Resource:    make(map[string]string),
+		SchemaURL:   res.SchemaURL(),
+		Propagators: cfg.propagators,
+		Exporters: map[string]string{
+			"traces":  fmt.Sprintf("%T", exp.span),
+			"metrics": fmt.Sprintf("%T", exp.metric),
+			"logs":    fmt.Sprintf("%T", exp.log),
+		},
+		LogSeverity: cfg.logMinSeverity.String(),
+		Verbosity:   cfg.verbosity.String(),
+		Environment: make(map[string]string),
+	}
+	for _, kv := range res.Attributes() {
+		d.Resource[string(kv.Key)] = kv.Value.Emit()
+	}
+	if exp.metricReader != nil {
+		d.Exporters["metrics"] = fmt.Sprintf("%T", exp.metricReader)
+	}
+	if s := newSampler(*cfg); s != nil {
+		d.Sampler = s.Description()
+	} else {
+		d.Sampler = trace.ParentBased(trace.AlwaysSample()).Description()
+	}
+	if exp.endpoint != "" {
+		d.Endpoint = redactURL(exp.endpoint)
+		d.ConnectMode = cfg.connectMode.String()
+	}
+	d.RemoteWrite = redactURL(cfg.remoteWriteURL)
+	d.Pushgateway = redactURL(cfg.pushgatewayURL)
+	d.Profiling = redactURL(cfg.profilingURL)
+	if cfg.memoryLimit > 0 {
+		d.MemoryLimit = cfg.memoryLimit
+		d.MemoryPolicy = cfg.memoryPolicy.String()
+	}
+	if cfg.privacy != nil {
+		d.Privacy = cfg.privacy.mode.String()
+	}
+	for signal, timeout := range map[string]time.Duration{
+		"traces":  cfg.traceExportTimeout,
+		"metrics": cfg.metricExportTimeout,
+		"logs":    cfg.logExportTimeout,
+	} {
+		if timeout > 0 {
+			if d.ExportTimeout == nil {
+				d.ExportTimeout = make(map[string]string)
+			}
+			d.ExportTimeout[signal] = timeout.String()
+		}
+	}
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(key, "OTEL_") && !strings.HasPrefix(key, "TELEMETRY_") {
+			continue
+		}
+		if slices.ContainsFunc(secretEnvMarkers, func(m string) bool { return strings.Contains(key, m) }) {
+			value = redacted
+		} else if strings.HasSuffix(key, "_URL") || strings.HasSuffix(key, "_ENDPOINT") {
+			value = redactURL(value)
+		}
+		d.Environment[key] = value
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(d)
+}
+
+// redactURL hides the password and query parameter values of u, which may
+// carry credentials.
+func redactURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil || (parsed.User == nil && parsed.RawQuery == "") {
+		return u
+	}
+	if parsed.User != nil {
+		parsed.User = url.UserPassword(parsed.User.Username(), redacted)
+	}
+	if parsed.RawQuery != "" {
+		q := parsed.Query()
+		for k := range q {
+			q.Set(k, redacted)
+		}
+		parsed.RawQuery = q.Encode()
+	}
+	return parsed.String()
+}
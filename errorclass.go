@@ -0,0 +1,114 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrorClass is the coarse cause of an error, recorded as the error.type
+// attribute by RecordClassifiedError so error rates can be broken down the
+// same way across services.
+type ErrorClass string
+
+const (
+	// ErrorClient is an error caused by the caller, such as invalid input or
+	// a canceled request.
+	ErrorClient ErrorClass = "client"
+	// ErrorDependency is a failure of a downstream service or datastore.
+	ErrorDependency ErrorClass = "dependency"
+	// ErrorTimeout is a deadline that was exceeded.
+	ErrorTimeout ErrorClass = "timeout"
+	// ErrorInternal is any other error, a fault of the service itself.
+	ErrorInternal ErrorClass = "internal"
+)
+
+// errorMatchers are the matchers registered with RegisterErrorMatcher.
+var errorMatchers struct {
+	sync.RWMutex
+	list []func(error) (ErrorClass, bool)
+}
+
+// RegisterErrorMatcher adds match to the matchers ClassifyError consults, in
+// registration order, before the built-in ones. A matcher returns false for
+// errors it doesn't know about, e.g.:
+//
+//	telemetry.RegisterErrorMatcher(func(err error) (telemetry.ErrorClass, bool) {
+//		if errors.Is(err, ErrValidation) {
+//			return telemetry.ErrorClient, true
+//		}
+//		return "", false
+//	})
+func RegisterErrorMatcher(match func(error) (ErrorClass, bool)) {
+	errorMatchers.Lock()
+	defer errorMatchers.Unlock()
+	errorMatchers.list = append(errorMatchers.list, match)
+}
+
+// ClassifyError returns the class of err from the registered matchers. If
+// none matches, exceeded deadlines and network timeouts are timeouts,
+// canceled contexts are client errors and anything else is internal.
+func ClassifyError(err error) ErrorClass {
+	errorMatchers.RLock()
+	matchers := errorMatchers.list
+	errorMatchers.RUnlock()
+	for _, match := range matchers {
+		if class, ok := match(err); ok {
+			return class
+		}
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return ErrorTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorClient
+	}
+	return ErrorInternal
+}
+
+// classifiedErrors returns the error.count counter of the global meter
+// provider at the time of the call.
+func classifiedErrors() metric.Int64Counter {
+	counter, err := otel.Meter(instrumentationName).Int64Counter("error.count",
+		metric.WithUnit("{error}"),
+		metric.WithDescription("Number of errors recorded, by class."))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return counter
+}
+
+// RecordClassifiedError records err on span with its class as the error.type
+// attribute, and counts it in the error.count metric with the same
+// attribute. The span is marked as failed unless the error is the client's.
+// It returns the class, and does nothing for a nil err.
+func RecordClassifiedError(span trace.Span, err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+	class := ClassifyError(err)
+	attr := semconv.ErrorTypeKey.String(string(class))
+
+	span.RecordError(err, trace.WithAttributes(attr))
+	span.SetAttributes(attr)
+	if class != ErrorClient {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	if counter := classifiedErrors(); counter != nil {
+		ctx := trace.ContextWithSpan(context.Background(), span)
+		counter.Add(ctx, 1, metric.WithAttributes(attr))
+	}
+	return class
+}
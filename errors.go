@@ -0,0 +1,42 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotInitialized is returned by features that need the pipeline to have
+// been set up first.
+var ErrNotInitialized = errors.New("telemetry: pipeline is not initialized")
+
+// ErrAlreadyInitialized is returned by the setup functions when the pipeline
+// of a previous setup is still installed, such as when both
+// SetupOTelSDKGrpc and SetupOTelSDKStdout are called, rather than replacing
+// the global providers under the code already using them. Call the shutdown
+// function of the previous setup first.
+var ErrAlreadyInitialized = errors.New("telemetry: pipeline is already initialized")
+
+// The setup functions wrap the failures of each stage in these errors, to be
+// tested with errors.Is. A setup returns the failures of every stage it got
+// to, joined, after shutting down what it built; the globals are only
+// replaced once every stage succeeded.
+var (
+	// ErrConnection reports a failure to connect to the collector.
+	ErrConnection = errors.New("telemetry: collector connection failed")
+	// ErrExporter reports a failure to create an exporter.
+	ErrExporter = errors.New("telemetry: exporter setup failed")
+	// ErrResource reports a failure to build the resource.
+	ErrResource = errors.New("telemetry: resource setup failed")
+	// ErrTracerProvider reports a failure to build the tracer provider.
+	ErrTracerProvider = errors.New("telemetry: tracer provider setup failed")
+	// ErrMeterProvider reports a failure to build the meter provider or to
+	// register the metrics of the package, such as an invalid metric rule.
+	ErrMeterProvider = errors.New("telemetry: meter provider setup failed")
+	// ErrLoggerProvider reports a failure to build the logger providers.
+	ErrLoggerProvider = errors.New("telemetry: logger provider setup failed")
+)
+
+// stageError wraps err, the failure of a setup stage, in the stage's error.
+func stageError(stage, err error) error {
+	return fmt.Errorf("%w: %w", stage, err)
+}
@@ -0,0 +1,132 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// maxEventAttributes caps the attributes produced from an event payload.
+	maxEventAttributes = 64
+	// maxEventValueLength caps the length of string attribute values.
+	maxEventValueLength = 1024
+)
+
+// AddEvent adds an event called name to the span in ctx, with payload
+// flattened into attributes. The payload is encoded following its json
+// tags; nested objects become dotted keys (e.g. "order.id"). At most 64
+// attributes are recorded and string values are cut to 1024 bytes, marked
+// like the values shortened by WithAttributeValueLimit. Numbers keep their
+// precision: integers are recorded as int64 attributes, and integers too
+// large for them as strings. Nothing is recorded with VerbosityOff.
+func AddEvent(ctx context.Context, name string, payload any) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() || !verbose(VerbosityBasic) {
+		return
+	}
+	span.AddEvent(name, trace.WithAttributes(EventAttributes(payload)...))
+}
+
+// EventAttributes returns the attributes AddEvent would record for payload.
+func EventAttributes(payload any) []attribute.KeyValue {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return []attribute.KeyValue{attribute.String("event.error", err.Error())}
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return []attribute.KeyValue{attribute.String("event.error", err.Error())}
+	}
+
+	var attrs []attribute.KeyValue
+	flattenEvent(&attrs, "", v)
+	if len(attrs) > maxEventAttributes {
+		attrs = append(attrs[:maxEventAttributes], attribute.Bool("event.truncated", true))
+	}
+	return attrs
+}
+
+// flattenEvent appends the attributes for the decoded JSON value v under key.
+func flattenEvent(attrs *[]attribute.KeyValue, key string, v any) {
+	if len(*attrs) > maxEventAttributes {
+		return
+	}
+	if m, ok := v.(map[string]any); ok {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := k
+			if key != "" {
+				child = key + "." + k
+			}
+			flattenEvent(attrs, child, m[k])
+		}
+		return
+	}
+
+	// Scalar payloads are recorded under a fixed key.
+	if key == "" {
+		key = "payload"
+	}
+	switch v := v.(type) {
+	case nil:
+	case bool:
+		*attrs = append(*attrs, attribute.Bool(key, v))
+	case json.Number:
+		*attrs = append(*attrs, numberAttribute(key, v))
+	case string:
+		*attrs = append(*attrs, attribute.String(key, truncateValue(v)))
+	case []any:
+		if strs, ok := stringSlice(v); ok {
+			*attrs = append(*attrs, attribute.StringSlice(key, strs))
+			return
+		}
+		b, _ := json.Marshal(v)
+		*attrs = append(*attrs, attribute.String(key, truncateValue(string(b))))
+	}
+}
+
+// stringSlice converts v to a []string if all its elements are strings.
+func stringSlice(v []any) ([]string, bool) {
+	strs := make([]string, 0, len(v))
+	for _, e := range v {
+		s, ok := e.(string)
+		if !ok {
+			return nil, false
+		}
+		strs = append(strs, truncateValue(s))
+	}
+	return strs, true
+}
+
+// numberAttribute returns the attribute for the JSON number n, as an int64
+// if it is an integer that fits, a float64 if it has a fraction or an
+// exponent, and a string otherwise.
+func numberAttribute(key string, n json.Number) attribute.KeyValue {
+	if i, err := n.Int64(); err == nil {
+		return attribute.Int64(key, i)
+	}
+	if strings.ContainsAny(n.String(), ".eE") {
+		if f, err := n.Float64(); err == nil {
+			return attribute.Float64(key, f)
+		}
+	}
+	return attribute.String(key, n.String())
+}
+
+// truncateValue cuts s to maxEventValueLength bytes at a character boundary.
+func truncateValue(s string) string {
+	s, _ = truncate(s, maxEventValueLength)
+	return s
+}
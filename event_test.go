@@ -0,0 +1,57 @@
+package telemetry
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestEventAttributes(t *testing.T) {
+	type order struct {
+		ID    uint64   `json:"id"`
+		Total float64  `json:"total"`
+		Items []string `json:"items"`
+		Big   uint64   `json:"big"`
+	}
+	tests := []struct {
+		name    string
+		payload any
+		want    []attribute.KeyValue
+	}{
+		{"scalar", "paid", []attribute.KeyValue{attribute.String("payload", "paid")}},
+		{
+			"nested",
+			map[string]any{"order": order{ID: 1<<53 + 1, Total: 9.5, Items: []string{"a"}, Big: 1 << 63}},
+			[]attribute.KeyValue{
+				attribute.String("order.big", "9223372036854775808"),
+				attribute.Int64("order.id", 1<<53+1),
+				attribute.StringSlice("order.items", []string{"a"}),
+				attribute.Float64("order.total", 9.5),
+			},
+		},
+		{"mixed slice", map[string]any{"v": []any{1, "a"}}, []attribute.KeyValue{attribute.String("v", `[1,"a"]`)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EventAttributes(tt.payload); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventAttributesTruncation(t *testing.T) {
+	long := strings.Repeat("é", maxEventValueLength)
+	attrs := EventAttributes(map[string]any{"note": long, "tags": []string{long}})
+	if len(attrs) != 2 {
+		t.Fatalf("got %v", attrs)
+	}
+	for _, v := range []string{attrs[0].Value.AsString(), attrs[1].Value.AsStringSlice()[0]} {
+		if !utf8.ValidString(v) || !strings.HasSuffix(v, truncationMarker) || len(v) > maxEventValueLength+len(truncationMarker) {
+			t.Errorf("value of %d bytes not truncated at a character boundary: %q", len(v), v[len(v)-20:])
+		}
+	}
+}
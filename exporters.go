@@ -0,0 +1,37 @@
+package telemetry
+
+import (
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// WithSpanExporter adds exporter to the trace pipeline, receiving the spans
+// in addition to the exporter of the setup function, such as a
+// ClickHouseSpanExporter. Spans go through the same rewrites, and each
+// additional exporter gets its own batch processor. It can be passed several
+// times.
+func WithSpanExporter(exporter trace.SpanExporter) Option {
+	return func(c *config) {
+		c.spanExporters = append(c.spanExporters, exporter)
+	}
+}
+
+// WithMetricExporter adds exporter to the metric pipeline, reading the
+// metrics on the SDK's default export interval in addition to the exporter
+// of the setup function. It can be passed several times.
+func WithMetricExporter(exporter sdkmetric.Exporter) Option {
+	return func(c *config) {
+		c.metricExporters = append(c.metricExporters, exporter)
+	}
+}
+
+// WithLogExporter adds exporter to the log pipeline, receiving the records
+// in addition to the exporter of the setup function, such as a
+// SyslogExporter. Records go through the same filters, and each additional
+// exporter gets its own batch processor. It can be passed several times.
+func WithLogExporter(exporter sdklog.Exporter) Option {
+	return func(c *config) {
+		c.logExporters = append(c.logExporters, exporter)
+	}
+}
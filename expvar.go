@@ -0,0 +1,145 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// publishedExpvars holds the names published by PublishExpvar, which
+// ObserveExpvar must not observe back.
+var publishedExpvars sync.Map
+
+// PublishExpvar publishes the metrics matching patterns under the expvar
+// name, so they are served on /debug/vars alongside existing variables.
+// Patterns may contain "*" wildcards; with none, every metric is published.
+// Each metric maps its attribute sets to the value of their data point, the
+// sum and count of histograms. It returns an error if name is already
+// published.
+func PublishExpvar(name string, patterns ...string) error {
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("telemetry: expvar %q is already published", name)
+	}
+	expvar.Publish(name, expvar.Func(func() any {
+		rm, err := SnapshotMetrics(context.Background())
+		if err != nil {
+			return err.Error()
+		}
+		return expvarMetrics(rm, patterns)
+	}))
+	publishedExpvars.Store(name, true)
+	return nil
+}
+
+// expvarMetrics flattens the metrics of rm matching patterns.
+func expvarMetrics(rm metricdata.ResourceMetrics, patterns []string) map[string]map[string]any {
+	out := make(map[string]map[string]any)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if !matchesAny(patterns, m.Name) {
+				continue
+			}
+			points := make(map[string]any)
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				addExpvarPoints(points, data.DataPoints)
+			case metricdata.Sum[float64]:
+				addExpvarPoints(points, data.DataPoints)
+			case metricdata.Gauge[int64]:
+				addExpvarPoints(points, data.DataPoints)
+			case metricdata.Gauge[float64]:
+				addExpvarPoints(points, data.DataPoints)
+			case metricdata.Histogram[int64]:
+				for _, p := range data.DataPoints {
+					points[p.Attributes.Encoded(attribute.DefaultEncoder())] = map[string]any{"count": p.Count, "sum": p.Sum}
+				}
+			case metricdata.Histogram[float64]:
+				for _, p := range data.DataPoints {
+					points[p.Attributes.Encoded(attribute.DefaultEncoder())] = map[string]any{"count": p.Count, "sum": p.Sum}
+				}
+			}
+			out[m.Name] = points
+		}
+	}
+	return out
+}
+
+func addExpvarPoints[N int64 | float64](points map[string]any, dps []metricdata.DataPoint[N]) {
+	for _, p := range dps {
+		points[p.Attributes.Encoded(attribute.DefaultEncoder())] = p.Value
+	}
+}
+
+func matchesAny(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if globMatch(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ObserveExpvar reports the numeric expvar variables with the given names as
+// gauges named "expvar.<name>" on mp, easing the migration of services that
+// already expose /debug/vars. With no names, every variable published at the
+// time of the call is observed, except those published by PublishExpvar.
+// Maps of numbers, such as *expvar.Map, are reported with one data point per
+// key in the expvar.key attribute; non-numeric values are skipped.
+func ObserveExpvar(mp metric.MeterProvider, names ...string) (metric.Registration, error) {
+	if len(names) == 0 {
+		expvar.Do(func(kv expvar.KeyValue) {
+			if _, ok := publishedExpvars.Load(kv.Key); !ok {
+				names = append(names, kv.Key)
+			}
+		})
+	}
+
+	meter := mp.Meter(instrumentationName)
+	gauges := make(map[string]metric.Float64ObservableGauge, len(names))
+	observables := make([]metric.Observable, 0, len(names))
+	var err error
+	for _, name := range names {
+		g, e := meter.Float64ObservableGauge("expvar."+name,
+			metric.WithDescription(fmt.Sprintf("Value of the %s expvar variable.", name)))
+		err = errors.Join(err, e)
+		gauges[name] = g
+		observables = append(observables, g)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		for name, g := range gauges {
+			v := expvar.Get(name)
+			if v == nil {
+				continue
+			}
+			var value any
+			if json.Unmarshal([]byte(v.String()), &value) != nil {
+				continue
+			}
+			switch value := value.(type) {
+			case float64:
+				o.ObserveFloat64(g, value)
+			case map[string]any:
+				for k, entry := range value {
+					if f, ok := entry.(float64); ok {
+						o.ObserveFloat64(g, f, metric.WithAttributes(attribute.String("expvar.key", k)))
+					}
+				}
+			}
+		}
+		return nil
+	}, observables...)
+}
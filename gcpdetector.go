@@ -0,0 +1,100 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// gcpMetadataURL is the base URL of the GCP metadata server.
+const gcpMetadataURL = "http://metadata.google.internal/computeMetadata/v1"
+
+// GCPDetector detects the cloud.*, host.*, k8s.* and faas.* attributes of
+// workloads running on Compute Engine, GKE, Cloud Run and Cloud Functions
+// from the metadata server. It finds nothing outside of GCP.
+func GCPDetector() resource.Detector {
+	return detectorFunc(detectGCP)
+}
+
+func detectGCP(ctx context.Context) (*resource.Resource, error) {
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	defer cancel()
+
+	baseURL := gcpMetadataURL
+	if host := os.Getenv("GCE_METADATA_HOST"); host != "" {
+		baseURL = "http://" + host + "/computeMetadata/v1"
+	}
+	get := func(p string) (string, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/"+p, nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Metadata-Flavor", "Google")
+		body, err := fetchMetadata(req)
+		return strings.TrimSpace(string(body)), err
+	}
+
+	projectID, err := get("project/project-id")
+	if err != nil {
+		// Not running on GCP.
+		return resource.Empty(), nil
+	}
+	attrs := []attribute.KeyValue{semconv.CloudProviderGCP, semconv.CloudAccountID(projectID)}
+
+	switch {
+	case os.Getenv("K_SERVICE") != "" && os.Getenv("FUNCTION_TARGET") != "":
+		attrs = append(attrs, semconv.CloudPlatformGCPCloudFunctions)
+		attrs = append(attrs, gcpServerless(get)...)
+	case os.Getenv("K_SERVICE") != "":
+		attrs = append(attrs, semconv.CloudPlatformGCPCloudRun)
+		attrs = append(attrs, gcpServerless(get)...)
+	default:
+		if cluster, err := get("instance/attributes/cluster-name"); err == nil && cluster != "" {
+			attrs = append(attrs, semconv.CloudPlatformGCPKubernetesEngine, semconv.K8SClusterName(cluster))
+		} else {
+			attrs = append(attrs, semconv.CloudPlatformGCPComputeEngine)
+		}
+		if id, err := get("instance/id"); err == nil {
+			attrs = append(attrs, semconv.HostID(id))
+		}
+		if name, err := get("instance/name"); err == nil {
+			attrs = append(attrs, semconv.HostName(name))
+		}
+		if machineType, err := get("instance/machine-type"); err == nil {
+			attrs = append(attrs, semconv.HostType(path.Base(machineType)))
+		}
+		if zone, err := get("instance/zone"); err == nil {
+			// projects/<number>/zones/<region>-<zone>
+			zone = path.Base(zone)
+			attrs = append(attrs, semconv.CloudAvailabilityZone(zone))
+			if i := strings.LastIndexByte(zone, '-'); i > 0 {
+				attrs = append(attrs, semconv.CloudRegion(zone[:i]))
+			}
+		}
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+// gcpServerless returns the attributes of a Cloud Run service or Cloud
+// Function, read from the environment its runtime sets and the metadata
+// server.
+func gcpServerless(get func(string) (string, error)) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.FaaSName(os.Getenv("K_SERVICE")),
+		semconv.FaaSVersion(os.Getenv("K_REVISION")),
+	}
+	if id, err := get("instance/id"); err == nil {
+		attrs = append(attrs, semconv.FaaSInstance(id))
+	}
+	if region, err := get("instance/region"); err == nil {
+		// projects/<number>/regions/<region>
+		attrs = append(attrs, semconv.CloudRegion(path.Base(region)))
+	}
+	return attrs
+}
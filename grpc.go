@@ -0,0 +1,260 @@
+package telemetry
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	// defaultEndpoint is the collector address used when none is configured.
+	defaultEndpoint = "localhost:4317"
+	// defaultConnectTimeout bounds a blocking connect to the collector.
+	defaultConnectTimeout = 10 * time.Second
+	// initialRetryBackoff and maxRetryBackoff bound the delay between
+	// connection attempts when retrying.
+	initialRetryBackoff = 500 * time.Millisecond
+	maxRetryBackoff     = 30 * time.Second
+)
+
+// ConnectMode selects how SetupOTelSDKGrpc connects to the collector.
+type ConnectMode int
+
+const (
+	// ConnectLazy returns immediately and connects on the first export.
+	// Export failures are reported to the otel error handler.
+	ConnectLazy ConnectMode = iota
+	// ConnectBlocking waits for the connection to be ready, up to the connect
+	// timeout, and fails the setup if the collector is unreachable.
+	ConnectBlocking
+)
+
+// String returns the name used for m in TELEMETRY_CONNECT_MODE.
+func (m ConnectMode) String() string {
+	switch m {
+	case ConnectLazy:
+		return "lazy"
+	case ConnectBlocking:
+		return "blocking"
+	}
+	return fmt.Sprintf("ConnectMode(%d)", int(m))
+}
+
+// WithEndpoint sets the address of the collector SetupOTelSDKGrpc exports
+// to. It defaults to OTEL_EXPORTER_OTLP_ENDPOINT, or localhost:4317. An
+// https:// scheme enables TLS.
+func WithEndpoint(endpoint string) Option {
+	return func(c *config) {
+		c.endpoint = endpoint
+	}
+}
+
+// WithConnectMode selects whether SetupOTelSDKGrpc waits for the collector
+// connection. It defaults to TELEMETRY_CONNECT_MODE, or ConnectLazy.
+func WithConnectMode(mode ConnectMode) Option {
+	return func(c *config) {
+		c.connectMode = mode
+	}
+}
+
+// WithConnectTimeout bounds how long a ConnectBlocking setup waits for the
+// collector. It defaults to TELEMETRY_CONNECT_TIMEOUT, or 10s.
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.connectTimeout = timeout
+	}
+}
+
+// WithConnectRetry makes SetupOTelSDKGrpc retry a blocking connect with
+// exponential backoff for up to maxWait, for collectors that start after the
+// application, such as Kubernetes sidecars. It implies ConnectBlocking. It
+// defaults to TELEMETRY_CONNECT_RETRY_MAX_WAIT, or no retry.
+func WithConnectRetry(maxWait time.Duration) Option {
+	return func(c *config) {
+		c.connectRetryMaxWait = maxWait
+	}
+}
+
+// SetupOTelSDKGrpc bootstraps the OpenTelemetry pipeline with OTLP exporters
+// sending every signal to the collector over a single gRPC connection.
+// Canceling ctx, or its deadline, aborts a blocking or retrying connect, in
+// which case the error wraps ctx.Err().
+// If it does not return an error, make sure to call shutdown for proper cleanup.
+func SetupOTelSDKGrpc(ctx context.Context, opts ...Option) (shutdown func(context.Context) error, err error) {
+	if err := claimSetup(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			initialized.Store(false)
+		}
+	}()
+
+	cfg := newConfig(opts)
+	conn, err := initConn(ctx, cfg)
+	if err != nil {
+		return nil, stageError(ErrConnection, err)
+	}
+
+	exp, err := newOTLPExporters(ctx, cfg, conn)
+	if err != nil {
+		return nil, errors.Join(stageError(ErrExporter, err), conn.Close())
+	}
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	exp.instrument = func(mp metric.MeterProvider) error {
+		return watchConnState(watchCtx, mp, conn, cfg.connStateCallbacks)
+	}
+	shutdownPipeline, err := setupPipeline(ctx, cfg, exp)
+	if err != nil {
+		stopWatch()
+		return nil, errors.Join(err, conn.Close())
+	}
+
+	return func(ctx context.Context) error {
+		stopWatch()
+		return errors.Join(shutdownPipeline(ctx), conn.Close())
+	}, nil
+}
+
+// initConn creates the gRPC connection to the collector, waiting for it to
+// be ready when cfg asks for a blocking connect and retrying until the
+// configured maximum wait has elapsed. It gives up as soon as ctx is done.
+func initConn(ctx context.Context, cfg config) (*grpc.ClientConn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if cfg.connectRetryMaxWait <= 0 {
+		return dialCollector(ctx, cfg, cfg.connectMode == ConnectBlocking)
+	}
+
+	deadline := time.Now().Add(cfg.connectRetryMaxWait)
+	backoff := initialRetryBackoff
+	for {
+		conn, err := dialCollector(ctx, cfg, true)
+		if err == nil {
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return nil, fmt.Errorf("telemetry: giving up on collector after %v: %w", cfg.connectRetryMaxWait, err)
+		}
+		otel.Handle(fmt.Errorf("%w; retrying in %v", err, backoff))
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("telemetry: gave up on collector: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxRetryBackoff)
+	}
+}
+
+// dialCollector makes a single attempt at creating the gRPC connection to the
+// collector, waiting for it to be ready if block is set, until the connect
+// timeout elapses or ctx is done.
+func dialCollector(ctx context.Context, cfg config, block bool) (*grpc.ClientConn, error) {
+	target, creds := parseEndpoint(cfg.endpoint)
+	dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if cfg.collectorSRV != "" {
+		var name string
+		name, creds = parseEndpoint(cfg.collectorSRV)
+		target = srvScheme + ":///" + name
+		dialOpts = []grpc.DialOption{
+			grpc.WithTransportCredentials(creds),
+			grpc.WithResolvers(&srvBuilder{refresh: cfg.collectorSRVRefresh}),
+			grpc.WithAuthority(srvAuthority(name)),
+		}
+	}
+	conn, err := grpc.NewClient(target, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: failed to create gRPC connection to collector: %w", err)
+	}
+	if !block {
+		return conn, nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, cfg.connectTimeout)
+	defer cancel()
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return conn, nil
+		}
+		if !conn.WaitForStateChange(waitCtx, state) {
+			err := fmt.Errorf("telemetry: collector at %s not ready after %v (state %v)", target, cfg.connectTimeout, state)
+			if ctx.Err() != nil {
+				err = fmt.Errorf("telemetry: collector at %s not ready (state %v): %w", target, state, ctx.Err())
+			}
+			return nil, errors.Join(err, conn.Close())
+		}
+	}
+}
+
+// parseEndpoint splits an OTLP endpoint into a gRPC target and the transport
+// credentials its scheme calls for.
+func parseEndpoint(endpoint string) (string, credentials.TransportCredentials) {
+	if rest, ok := strings.CutPrefix(endpoint, "https://"); ok {
+		return rest, credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	return strings.TrimPrefix(endpoint, "http://"), insecure.NewCredentials()
+}
+
+// newOTLPExporters creates OTLP exporters sharing conn, bounding each export
+// by the timeout configured for its signal.
+func newOTLPExporters(ctx context.Context, cfg config, conn *grpc.ClientConn) (exporters, error) {
+	traceOpts := []otlptracegrpc.Option{otlptracegrpc.WithGRPCConn(conn)}
+	if cfg.traceExportTimeout > 0 {
+		traceOpts = append(traceOpts, otlptracegrpc.WithTimeout(cfg.traceExportTimeout))
+	}
+	traceExporter, err := otlptracegrpc.New(ctx, traceOpts...)
+	if err != nil {
+		return exporters{}, err
+	}
+	metricOpts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithGRPCConn(conn)}
+	if cfg.metricExportTimeout > 0 {
+		metricOpts = append(metricOpts, otlpmetricgrpc.WithTimeout(cfg.metricExportTimeout))
+	}
+	metricExporter, err := otlpmetricgrpc.New(ctx, metricOpts...)
+	if err != nil {
+		return exporters{}, err
+	}
+	logOpts := []otlploggrpc.Option{otlploggrpc.WithGRPCConn(conn)}
+	if cfg.logExportTimeout > 0 {
+		logOpts = append(logOpts, otlploggrpc.WithTimeout(cfg.logExportTimeout))
+	}
+	logExporter, err := otlploggrpc.New(ctx, logOpts...)
+	if err != nil {
+		return exporters{}, err
+	}
+	return exporters{span: traceExporter, metric: metricExporter, log: logExporter, endpoint: cmp.Or(cfg.collectorSRV, cfg.endpoint)}, nil
+}
+
+// envConnectMode reads a connect mode name from the environment variable key.
+func envConnectMode(key string) ConnectMode {
+	switch v := os.Getenv(key); v {
+	case "", "lazy":
+		return ConnectLazy
+	case "blocking":
+		return ConnectBlocking
+	default:
+		otel.Handle(fmt.Errorf("telemetry: unknown connect mode %q", v))
+		return ConnectLazy
+	}
+}
@@ -0,0 +1,195 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// startCollector serves an empty gRPC server on a local port until the test
+// ends and returns its address.
+func startCollector(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+// unreachableAddr returns a local address nothing listens on.
+func unreachableAddr(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+	return addr
+}
+
+func TestInitConn(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		endpoint  string
+		mode      ConnectMode
+		wantState connectivity.State
+		wantErr   string
+		wantIs    error
+	}{
+		{"lazy does not connect", context.Background(), unreachableAddr(t), ConnectLazy, connectivity.Idle, "", nil},
+		{"blocking waits for ready", context.Background(), startCollector(t), ConnectBlocking, connectivity.Ready, "", nil},
+		{"blocking times out", context.Background(), unreachableAddr(t), ConnectBlocking, 0, "not ready after 200ms", nil},
+		{"canceled context", canceled, startCollector(t), ConnectBlocking, 0, "", context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config{endpoint: "http://" + tt.endpoint, connectMode: tt.mode, connectTimeout: 200 * time.Millisecond}
+			start := time.Now()
+			conn, err := initConn(tt.ctx, cfg)
+			if tt.wantErr != "" || tt.wantIs != nil {
+				if err == nil {
+					conn.Close()
+					t.Fatal("initConn succeeded")
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) || (tt.wantIs != nil && !errors.Is(err, tt.wantIs)) {
+					t.Errorf("err = %v, want %q (%v)", err, tt.wantErr, tt.wantIs)
+				}
+				if elapsed := time.Since(start); elapsed > 2*time.Second {
+					t.Errorf("initConn took %v to fail", elapsed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			if got := conn.GetState(); got != tt.wantState {
+				t.Errorf("state = %v, want %v", got, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestSetupOTelSDKGrpcBlockingFailure(t *testing.T) {
+	ctx := context.Background()
+	opts := []Option{
+		WithEndpoint(unreachableAddr(t)),
+		WithConnectMode(ConnectBlocking),
+		WithConnectTimeout(100 * time.Millisecond),
+	}
+	if _, err := SetupOTelSDKGrpc(ctx, opts...); !errors.Is(err, ErrConnection) {
+		t.Fatalf("got %v, want ErrConnection", err)
+	}
+
+	shutdown, err := SetupOTelSDKGrpc(ctx, WithEndpoint(startCollector(t)), WithConnectMode(ConnectBlocking))
+	if err != nil {
+		t.Fatalf("setup after a failed one: %v", err)
+	}
+	// The test collector implements no OTLP service, so the final flush
+	// fails; the pipeline is torn down regardless.
+	shutdown(ctx)
+}
+
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint   string
+		wantTarget string
+		wantTLS    bool
+	}{
+		{"localhost:4317", "localhost:4317", false},
+		{"http://collector:4317", "collector:4317", false},
+		{"https://collector:4317", "collector:4317", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			target, creds := parseEndpoint(tt.endpoint)
+			if target != tt.wantTarget {
+				t.Errorf("target = %q, want %q", target, tt.wantTarget)
+			}
+			if got := creds.Info().SecurityProtocol == "tls"; got != tt.wantTLS {
+				t.Errorf("TLS = %v, want %v", got, tt.wantTLS)
+			}
+		})
+	}
+}
+
+func TestEnvConnectMode(t *testing.T) {
+	tests := []struct {
+		value string
+		want  ConnectMode
+	}{
+		{"", ConnectLazy},
+		{"lazy", ConnectLazy},
+		{"blocking", ConnectBlocking},
+		{"eager", ConnectLazy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("TELEMETRY_CONNECT_MODE", tt.value)
+			if got := envConnectMode("TELEMETRY_CONNECT_MODE"); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitConnRetry(t *testing.T) {
+	t.Run("collector starts late", func(t *testing.T) {
+		addr := unreachableAddr(t)
+		go func() {
+			time.Sleep(300 * time.Millisecond)
+			lis, err := net.Listen("tcp", addr)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			srv := grpc.NewServer()
+			go srv.Serve(lis)
+			t.Cleanup(srv.Stop)
+		}()
+		cfg := config{endpoint: addr, connectTimeout: 100 * time.Millisecond, connectRetryMaxWait: 10 * time.Second}
+		conn, err := initConn(context.Background(), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		if got := conn.GetState(); got != connectivity.Ready {
+			t.Errorf("state = %v, want READY", got)
+		}
+	})
+
+	t.Run("gives up after the maximum wait", func(t *testing.T) {
+		cfg := config{endpoint: unreachableAddr(t), connectTimeout: 100 * time.Millisecond, connectRetryMaxWait: time.Second}
+		start := time.Now()
+		_, err := initConn(context.Background(), cfg)
+		if err == nil || !strings.Contains(err.Error(), "giving up on collector after 1s") {
+			t.Errorf("err = %v, want giving up", err)
+		}
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("initConn took %v to give up", elapsed)
+		}
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+		cfg := config{endpoint: unreachableAddr(t), connectTimeout: 100 * time.Millisecond, connectRetryMaxWait: time.Minute}
+		if _, err := initConn(ctx, cfg); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("err = %v, want context.DeadlineExceeded", err)
+		}
+	})
+}
@@ -0,0 +1,267 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcErrorCodes holds the functions deciding which status codes mark server
+// and client spans as failed, installed by the setup.
+var grpcErrorCodes atomic.Pointer[grpcCodeClassifier]
+
+type grpcCodeClassifier struct {
+	server func(codes.Code) bool
+	client func(codes.Code) bool
+}
+
+// defaultGRPCServerError follows the semantic conventions: only the codes
+// that point at a server fault mark server spans as failed.
+func defaultGRPCServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented,
+		codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	}
+	return false
+}
+
+// defaultGRPCClientError marks client spans as failed for every code but OK.
+func defaultGRPCClientError(code codes.Code) bool {
+	return code != codes.OK
+}
+
+// WithGRPCErrorCodes overrides which status codes mark the spans of the gRPC
+// server and client interceptors as failed, e.g. to treat NotFound as a
+// success or ResourceExhausted as an error. A nil function keeps the
+// default: Unknown, DeadlineExceeded, Unimplemented, Internal, Unavailable
+// and DataLoss for servers and every code but OK for clients. The codes can
+// also be set with the TELEMETRY_GRPC_SERVER_ERROR_CODES and
+// TELEMETRY_GRPC_CLIENT_ERROR_CODES environment variables, as comma
+// separated code names or numbers such as "NotFound,RESOURCE_EXHAUSTED,13".
+func WithGRPCErrorCodes(server, client func(code codes.Code) bool) Option {
+	return func(c *config) {
+		if server != nil {
+			c.grpcServerError = server
+		}
+		if client != nil {
+			c.grpcClientError = client
+		}
+	}
+}
+
+// ParseGRPCCodes parses comma separated status code names, in either the
+// Go ("NotFound") or canonical ("NOT_FOUND") form, or numbers into a
+// function reporting whether a code is listed.
+func ParseGRPCCodes(s string) (func(code codes.Code) bool, error) {
+	var listed [codes.Unauthenticated + 1]bool
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		code, ok := parseGRPCCode(name)
+		if !ok {
+			return nil, fmt.Errorf("telemetry: unknown gRPC code %q", name)
+		}
+		listed[code] = true
+	}
+	return func(code codes.Code) bool {
+		return code < codes.Code(len(listed)) && listed[code]
+	}, nil
+}
+
+func parseGRPCCode(name string) (codes.Code, bool) {
+	if n, err := strconv.Atoi(name); err == nil {
+		return codes.Code(n), n >= 0 && n <= int(codes.Unauthenticated)
+	}
+	normalized := strings.ReplaceAll(name, "_", "")
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if strings.EqualFold(c.String(), normalized) {
+			return c, true
+		}
+	}
+	// The canonical name of Canceled is CANCELLED.
+	if strings.EqualFold(normalized, "cancelled") {
+		return codes.Canceled, true
+	}
+	return 0, false
+}
+
+// envGRPCCodes reads gRPC status codes from the environment variable key,
+// returning def if it is unset or invalid.
+func envGRPCCodes(key string, def func(codes.Code) bool) func(codes.Code) bool {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	fn, err := ParseGRPCCodes(v)
+	if err != nil {
+		otel.Handle(err)
+		return def
+	}
+	return fn
+}
+
+func isGRPCServerError(code codes.Code) bool {
+	if c := grpcErrorCodes.Load(); c != nil {
+		return c.server(code)
+	}
+	return defaultGRPCServerError(code)
+}
+
+func isGRPCClientError(code codes.Code) bool {
+	if c := grpcErrorCodes.Load(); c != nil {
+		return c.client(code)
+	}
+	return defaultGRPCClientError(code)
+}
+
+// UnaryServerInterceptor returns an interceptor starting a server span for
+// each unary call, continuing the trace context sent in the metadata.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	tracer := otel.Tracer(instrumentationName)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, span := startGRPCServerSpan(ctx, tracer, info.FullMethod)
+		defer span.End()
+
+		var resp any
+		var err error
+		profile(ctx, true, func(ctx context.Context) { resp, err = handler(ctx, req) })
+		endGRPCSpan(span, err, isGRPCServerError)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns an interceptor starting a server span for
+// each streaming call, continuing the trace context sent in the metadata.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	tracer := otel.Tracer(instrumentationName)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startGRPCServerSpan(ss.Context(), tracer, info.FullMethod)
+		defer span.End()
+
+		var err error
+		profile(ctx, true, func(ctx context.Context) { err = handler(srv, &serverStream{ServerStream: ss, ctx: ctx}) })
+		endGRPCSpan(span, err, isGRPCServerError)
+		return err
+	}
+}
+
+// UnaryClientInterceptor returns an interceptor starting a client span for
+// each unary call and sending its trace context in the metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	tracer := otel.Tracer(instrumentationName)
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := startGRPCClientSpan(ctx, tracer, method)
+		defer span.End()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		endGRPCSpan(span, err, isGRPCClientError)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns an interceptor starting a client span for
+// each streaming call and sending its trace context in the metadata. The
+// span ends when the stream is set up; it doesn't cover the messages.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	tracer := otel.Tracer(instrumentationName)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, span := startGRPCClientSpan(ctx, tracer, method)
+		defer span.End()
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		endGRPCSpan(span, err, isGRPCClientError)
+		return cs, err
+	}
+}
+
+func startGRPCServerSpan(ctx context.Context, tracer trace.Tracer, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	return tracer.Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(grpcAttributes(fullMethod)...))
+}
+
+func startGRPCClientSpan(ctx context.Context, tracer trace.Tracer, fullMethod string) (context.Context, trace.Span) {
+	ctx, span := tracer.Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(grpcAttributes(fullMethod)...))
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+// grpcAttributes returns the attributes of a call to fullMethod, of the
+// form /package.Service/Method.
+func grpcAttributes(fullMethod string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.RPCSystemGRPC}
+	if service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/"); ok {
+		attrs = append(attrs, semconv.RPCService(service), semconv.RPCMethod(method))
+	}
+	return attrs
+}
+
+// endGRPCSpan records the status code of err on span, marking it as failed
+// if isError reports the code as an error.
+func endGRPCSpan(span trace.Span, err error, isError func(codes.Code) bool) {
+	s, _ := status.FromError(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(s.Code())))
+	if isError(s.Code()) {
+		span.SetStatus(otelcodes.Error, s.Message())
+	}
+}
+
+// serverStream overrides the context of a server stream with the one
+// carrying its span.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// metadataCarrier adapts gRPC metadata to a propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
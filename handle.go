@@ -0,0 +1,36 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Telemetry is a handle on an installed pipeline, for code that receives its
+// dependencies explicitly rather than through the otel globals.
+type Telemetry struct {
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+
+	shutdown func(context.Context) error
+}
+
+// New sets up the pipeline like SetupOTelSDKStdout and returns a handle on it.
+func New(ctx context.Context, opts ...Option) (*Telemetry, error) {
+	shutdown, err := SetupOTelSDKStdout(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Telemetry{
+		TracerProvider: otel.GetTracerProvider(),
+		MeterProvider:  otel.GetMeterProvider(),
+		shutdown:       shutdown,
+	}, nil
+}
+
+// Shutdown flushes and stops the pipeline.
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	return t.shutdown(ctx)
+}
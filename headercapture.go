@@ -0,0 +1,87 @@
+package telemetry
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// redactedValue replaces the values of sensitive headers.
+const redactedValue = "[REDACTED]"
+
+// sensitiveHeaders are always masked when captured.
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Proxy-Authorization",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// HeaderCapture lists the headers CaptureHeaders records.
+type HeaderCapture struct {
+	// Request and Response are the names of the headers to record,
+	// case-insensitively.
+	Request  []string
+	Response []string
+	// Sensitive are the names of headers whose values are masked in
+	// addition to Authorization, Cookie, Set-Cookie and the usual API key
+	// headers.
+	Sensitive []string
+}
+
+// CaptureHeaders returns a middleware recording the allowed request and
+// response headers as http.request.header.<name> and
+// http.response.header.<name> attributes of the current span, following the
+// semantic conventions. The values of sensitive headers are replaced with
+// "[REDACTED]". Headers are only recorded with VerbosityDetailed. Place it
+// inside Middleware so the server span is already started.
+func CaptureHeaders(c HeaderCapture) func(http.Handler) http.Handler {
+	sensitive := make(map[string]bool)
+	for _, h := range append(sensitiveHeaders, c.Sensitive...) {
+		sensitive[http.CanonicalHeaderKey(h)] = true
+	}
+	request := headerKeys("http.request.header.", c.Request)
+	response := headerKeys("http.response.header.", c.Response)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			span := trace.SpanFromContext(r.Context())
+			if !span.IsRecording() || !verbose(VerbosityDetailed) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			span.SetAttributes(headerAttributes(r.Header, request, sensitive)...)
+			next.ServeHTTP(w, r)
+			span.SetAttributes(headerAttributes(w.Header(), response, sensitive)...)
+		})
+	}
+}
+
+// headerKeys maps canonical header names to their attribute keys.
+func headerKeys(prefix string, names []string) map[string]attribute.Key {
+	keys := make(map[string]attribute.Key, len(names))
+	for _, name := range names {
+		keys[http.CanonicalHeaderKey(name)] = attribute.Key(prefix + strings.ToLower(name))
+	}
+	return keys
+}
+
+// headerAttributes returns the attributes of the headers of h listed in keys.
+func headerAttributes(h http.Header, keys map[string]attribute.Key, sensitive map[string]bool) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for name, key := range keys {
+		values := h.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		if sensitive[name] {
+			values = []string{redactedValue}
+		}
+		attrs = append(attrs, key.StringSlice(values))
+	}
+	return attrs
+}
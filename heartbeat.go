@@ -0,0 +1,37 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+// processStart approximates the time the process started.
+var processStart = time.Now()
+
+// registerHeartbeat emits the up gauge and process uptime counter on every
+// collection, so a silent pipeline can be told apart from an idle service.
+func registerHeartbeat(mp metric.MeterProvider) error {
+	meter := mp.Meter(instrumentationName)
+
+	up, err := meter.Int64ObservableGauge("up",
+		metric.WithUnit("1"),
+		metric.WithDescription("Always 1 while the telemetry pipeline is exporting."))
+	if err != nil {
+		return err
+	}
+	uptime, err := meter.Float64ObservableCounter("process.uptime",
+		metric.WithUnit("s"),
+		metric.WithDescription("Time since the process started."))
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(up, 1)
+		o.ObserveFloat64(uptime, time.Since(processStart).Seconds())
+		return nil
+	}, up, uptime)
+	return err
+}
@@ -0,0 +1,206 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the telemetry emitted by this package.
+const instrumentationName = "github.com/luciano-personal-org/telemetry"
+
+// HTTPMetrics holds the standard RED instruments for an HTTP server:
+// request count, error count, request duration and active requests. The
+// duration and active request instruments follow the semantic conventions;
+// the counters, which the conventions don't define, are named under the
+// telemetry. namespace.
+type HTTPMetrics struct {
+	requests metric.Int64Counter
+	errors   metric.Int64Counter
+	duration metric.Float64Histogram
+	active   metric.Int64UpDownCounter
+}
+
+// NewHTTPMetrics creates the HTTP server instruments on the given meter provider.
+func NewHTTPMetrics(mp metric.MeterProvider) (*HTTPMetrics, error) {
+	meter := mp.Meter(instrumentationName)
+
+	var m HTTPMetrics
+	var err, e error
+	m.requests, e = meter.Int64Counter("telemetry.http.server.request.count",
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Number of HTTP server requests."))
+	err = errors.Join(err, e)
+	m.errors, e = meter.Int64Counter("telemetry.http.server.error.count",
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Number of HTTP server requests that ended in a server error."))
+	err = errors.Join(err, e)
+	m.duration, e = meter.Float64Histogram(semconv.HTTPServerRequestDurationName,
+		metric.WithUnit(semconv.HTTPServerRequestDurationUnit),
+		metric.WithDescription(semconv.HTTPServerRequestDurationDescription))
+	err = errors.Join(err, e)
+	m.active, e = meter.Int64UpDownCounter(semconv.HTTPServerActiveRequestsName,
+		metric.WithUnit(semconv.HTTPServerActiveRequestsUnit),
+		metric.WithDescription(semconv.HTTPServerActiveRequestsDescription))
+	err = errors.Join(err, e)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Handler wraps next so that every request it serves is recorded in m.
+// It can be attached to any handler, independently of Middleware.
+func (m *HTTPMetrics) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := newResponseWriter(w)
+		done := m.start(r)
+		next.ServeHTTP(rw, r)
+		done(rw.status)
+	})
+}
+
+// start records the beginning of a request and returns a function that must
+// be called with the response status once the request is complete.
+func (m *HTTPMetrics) start(r *http.Request) func(status int) {
+	done := m.Begin(r.Context(), r.Method)
+	return func(status int) {
+		done(patternRoute(r.Pattern), status)
+	}
+}
+
+// Begin records the beginning of a request and returns a function that must
+// be called with the matched route template, if any, and the response status
+// once the request is complete. It lets framework integrations that don't use
+// net/http routing record the same metrics.
+// Methods other than the standard HTTP methods are recorded as "_OTHER".
+func (m *HTTPMetrics) Begin(ctx context.Context, method string) func(route string, status int) {
+	methodAttr := lookupMethod(method)
+	m.active.Add(ctx, 1, methodAttr.opt)
+	start := time.Now()
+
+	return func(route string, status int) {
+		elapsed := time.Since(start).Seconds()
+		m.active.Add(ctx, -1, methodAttr.opt)
+
+		attrs := getAttrs()
+		*attrs = append(*attrs, methodAttr.attr, semconv.HTTPResponseStatusCode(status))
+		if route != "" {
+			*attrs = append(*attrs, semconv.HTTPRoute(route))
+		}
+		opt := metric.WithAttributes(*attrs...)
+		putAttrs(attrs)
+		m.requests.Add(ctx, 1, opt)
+		m.duration.Record(ctx, elapsed, opt)
+		if isHTTPServerError(status) {
+			m.errors.Add(ctx, 1, opt)
+		}
+	}
+}
+
+// Middleware wraps next with a server span and the standard HTTP metrics.
+// Incoming trace context is extracted with the global propagator and the
+// span is named after the matched route when one is available. The route is
+// known when the span starts, for sampling rules on http.route, only if next
+// is an *http.ServeMux; otherwise it is set once next has served.
+func Middleware(next http.Handler) http.Handler {
+	tracer := otel.Tracer(instrumentationName)
+	metrics, err := NewHTTPMetrics(otel.GetMeterProvider())
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	mux, _ := next.(*http.ServeMux)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.Method
+		attrs := []attribute.KeyValue{
+			semconv.HTTPRequestMethodKey.String(r.Method),
+			semconv.URLPath(r.URL.Path),
+		}
+		// The route of a ServeMux is looked up before the span starts, so
+		// sampling rules can match it.
+		var route string
+		if mux != nil {
+			_, pattern := mux.Handler(r)
+			route = patternRoute(pattern)
+		}
+		if route != "" {
+			name += " " + route
+			attrs = append(attrs, semconv.HTTPRoute(route))
+		}
+
+		ctx := Extract(r.Context(), r.Header)
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attrs...))
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		rw := newResponseWriter(w)
+		var done func(int)
+		if metrics != nil {
+			done = metrics.start(r)
+		}
+		profile(ctx, route != "", func(context.Context) { next.ServeHTTP(rw, r) })
+		if done != nil {
+			done(rw.status)
+		}
+
+		if served := patternRoute(r.Pattern); served != "" && served != route {
+			span.SetName(r.Method + " " + served)
+			span.SetAttributes(semconv.HTTPRoute(served))
+		}
+		SetHTTPServerStatus(span, rw.status)
+	})
+}
+
+// patternRoute returns the path of a ServeMux pattern, without the method
+// and host it may start with, e.g. "/orders/{id}" for
+// "GET example.com/orders/{id}".
+func patternRoute(pattern string) string {
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		pattern = strings.TrimLeft(path, " \t")
+	}
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		pattern = pattern[i:]
+	}
+	return pattern
+}
+
+// responseWriter records the status code written by a handler.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
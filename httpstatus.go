@@ -0,0 +1,128 @@
+package telemetry
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// httpErrorStatus holds the functions deciding which response statuses mark
+// server and client spans as failed, installed by the setup.
+var httpErrorStatus atomic.Pointer[httpStatusClassifier]
+
+type httpStatusClassifier struct {
+	server func(int) bool
+	client func(int) bool
+}
+
+// defaultHTTPServerError follows the semantic conventions: 4xx responses are
+// the client's fault, so only 5xx ones mark server spans as failed.
+func defaultHTTPServerError(status int) bool {
+	return status >= http.StatusInternalServerError
+}
+
+// defaultHTTPClientError marks client spans as failed for 4xx and 5xx
+// responses, following the semantic conventions.
+func defaultHTTPClientError(status int) bool {
+	return status >= http.StatusBadRequest
+}
+
+// WithHTTPErrorStatuses overrides which response statuses mark the spans of
+// the HTTP middlewares (server) and Transport (client) as failed, e.g. to
+// treat 404 as a success for a lookup service or 499 as an error. A nil
+// function keeps the default: 5xx for servers and 4xx and 5xx for clients.
+// The statuses can also be set with the TELEMETRY_HTTP_SERVER_ERROR_STATUSES
+// and TELEMETRY_HTTP_CLIENT_ERROR_STATUSES environment variables, as comma
+// separated statuses and ranges such as "499,500-599".
+func WithHTTPErrorStatuses(server, client func(status int) bool) Option {
+	return func(c *config) {
+		if server != nil {
+			c.httpServerError = server
+		}
+		if client != nil {
+			c.httpClientError = client
+		}
+	}
+}
+
+// ParseStatusRanges parses comma separated HTTP statuses and inclusive
+// ranges, such as "404,500-599", into a function reporting whether a status
+// is listed.
+func ParseStatusRanges(s string) (func(status int) bool, error) {
+	type statusRange struct{ lo, hi int }
+	var ranges []statusRange
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		if !isRange {
+			hi = lo
+		}
+		l, err1 := strconv.Atoi(strings.TrimSpace(lo))
+		h, err2 := strconv.Atoi(strings.TrimSpace(hi))
+		if err1 != nil || err2 != nil || l > h {
+			return nil, fmt.Errorf("telemetry: invalid HTTP status range %q", part)
+		}
+		ranges = append(ranges, statusRange{l, h})
+	}
+	return func(status int) bool {
+		for _, r := range ranges {
+			if r.lo <= status && status <= r.hi {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// envStatusRanges reads HTTP status ranges from the environment variable
+// key, returning def if it is unset or invalid.
+func envStatusRanges(key string, def func(int) bool) func(int) bool {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	fn, err := ParseStatusRanges(v)
+	if err != nil {
+		otel.Handle(err)
+		return def
+	}
+	return fn
+}
+
+// isHTTPServerError reports whether status marks a server span as failed.
+func isHTTPServerError(status int) bool {
+	if c := httpErrorStatus.Load(); c != nil {
+		return c.server(status)
+	}
+	return defaultHTTPServerError(status)
+}
+
+// isHTTPClientError reports whether status marks a client span as failed.
+func isHTTPClientError(status int) bool {
+	if c := httpErrorStatus.Load(); c != nil {
+		return c.client(status)
+	}
+	return defaultHTTPClientError(status)
+}
+
+// SetHTTPServerStatus records the response status of a server span and marks
+// it as failed if the status is configured as an error, see
+// WithHTTPErrorStatuses. It lets framework integrations apply the same
+// mapping as Middleware.
+func SetHTTPServerStatus(span trace.Span, status int) {
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if isHTTPServerError(status) {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+}
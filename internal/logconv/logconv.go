@@ -0,0 +1,85 @@
+// Package logconv converts values from third-party logging libraries into
+// OpenTelemetry log values. It is shared by the logging bridges.
+package logconv
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+)
+
+// Value converts v into a log.Value, falling back to its string form for
+// types without a direct equivalent.
+func Value(v any) log.Value {
+	switch v := v.(type) {
+	case nil:
+		return log.Value{}
+	case string:
+		return log.StringValue(v)
+	case bool:
+		return log.BoolValue(v)
+	case int:
+		return log.IntValue(v)
+	case int8:
+		return log.Int64Value(int64(v))
+	case int16:
+		return log.Int64Value(int64(v))
+	case int32:
+		return log.Int64Value(int64(v))
+	case int64:
+		return log.Int64Value(v)
+	case uint8:
+		return log.Int64Value(int64(v))
+	case uint16:
+		return log.Int64Value(int64(v))
+	case uint32:
+		return log.Int64Value(int64(v))
+	case float32:
+		return log.Float64Value(float64(v))
+	case float64:
+		return log.Float64Value(v)
+	case []byte:
+		return log.BytesValue(v)
+	case time.Duration:
+		return log.Int64Value(v.Nanoseconds())
+	case time.Time:
+		return log.StringValue(v.Format(time.RFC3339Nano))
+	case error:
+		return log.StringValue(v.Error())
+	case fmt.Stringer:
+		return log.StringValue(v.String())
+	case map[string]any:
+		kvs := make([]log.KeyValue, 0, len(v))
+		for k, val := range v {
+			kvs = append(kvs, log.KeyValue{Key: k, Value: Value(val)})
+		}
+		return log.MapValue(kvs...)
+	case []any:
+		vals := make([]log.Value, 0, len(v))
+		for _, val := range v {
+			vals = append(vals, Value(val))
+		}
+		return log.SliceValue(vals...)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		vals := make([]log.Value, 0, rv.Len())
+		for i := range rv.Len() {
+			vals = append(vals, Value(rv.Index(i).Interface()))
+		}
+		return log.SliceValue(vals...)
+	}
+	return log.StringValue(fmt.Sprintf("%+v", v))
+}
+
+// KeyValues converts a map of fields into log attributes.
+func KeyValues(fields map[string]any) []log.KeyValue {
+	kvs := make([]log.KeyValue, 0, len(fields))
+	for k, v := range fields {
+		kvs = append(kvs, log.KeyValue{Key: k, Value: Value(v)})
+	}
+	return kvs
+}
@@ -0,0 +1,78 @@
+package telemetry
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// jobInstruments returns the job.runs counter and the job.duration histogram
+// of the current meter provider. They are not cached, so runs after the
+// pipeline is set up again are recorded by the new one.
+func jobInstruments() (metric.Int64Counter, metric.Float64Histogram) {
+	meter := otel.Meter(instrumentationName)
+	runs, err := meter.Int64Counter("job.runs",
+		metric.WithUnit("{run}"),
+		metric.WithDescription("Number of job executions."))
+	if err != nil {
+		otel.Handle(err)
+	}
+	duration, err := meter.Float64Histogram("job.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of job executions."))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return runs, duration
+}
+
+// RunJob runs fn as a background job or cron execution. Each run gets its own
+// root span and is recorded in the job.runs and job.duration metrics. Buffered
+// telemetry is flushed before RunJob returns, so short-lived processes don't
+// exit before the batch processors export. A panic in fn is recorded as a
+// failed run and then propagated.
+func RunJob(ctx context.Context, name string, fn func(context.Context) error) (err error) {
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, name,
+		trace.WithNewRoot(),
+		trace.WithAttributes(attribute.String("job.name", name)))
+	start := time.Now()
+
+	defer func() {
+		r := recover()
+		outcome := "success"
+		switch {
+		case r != nil:
+			outcome = "failure"
+			recordPanic(ctx, r, debug.Stack())
+		case err != nil:
+			outcome = "failure"
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		attrs := metric.WithAttributes(
+			attribute.String("job.name", name),
+			attribute.String("job.outcome", outcome),
+		)
+		runs, duration := jobInstruments()
+		runs.Add(ctx, 1, attrs)
+		duration.Record(ctx, time.Since(start).Seconds(), attrs)
+
+		if e := ForceFlush(context.WithoutCancel(ctx)); e != nil {
+			otel.Handle(e)
+		}
+		if r != nil {
+			panic(r)
+		}
+	}()
+
+	profile(ctx, true, func(ctx context.Context) { err = fn(ctx) })
+	return err
+}
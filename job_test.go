@@ -0,0 +1,94 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// jobOutcomes returns the job.runs counts per outcome collected by reader.
+func jobOutcomes(t *testing.T, reader *sdkmetric.ManualReader) map[string]int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	outcomes := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "job.runs" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				outcome, _ := dp.Attributes.Value("job.outcome")
+				outcomes[outcome.AsString()] += dp.Value
+			}
+		}
+	}
+	return outcomes
+}
+
+func TestRunJob(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		fn         func(context.Context) error
+		wantPanic  bool
+		wantStatus codes.Code
+		wantEvents int
+	}{
+		{"success", func(context.Context) error { return nil }, false, codes.Unset, 0},
+		{"error", func(context.Context) error { return errors.New("declined") }, false, codes.Error, 1},
+		{"panic", func(context.Context) error { panic("boom") }, true, codes.Error, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr.Reset()
+			var recovered any
+			func() {
+				defer func() { recovered = recover() }()
+				RunJob(ctx, "nightly", tt.fn)
+			}()
+			if (recovered != nil) != tt.wantPanic {
+				t.Fatalf("recovered %v, want panic %v", recovered, tt.wantPanic)
+			}
+
+			ended := sr.Ended()
+			if len(ended) != 1 {
+				t.Fatalf("got %d ended spans, want 1", len(ended))
+			}
+			if got := ended[0].Status().Code; got != tt.wantStatus {
+				t.Errorf("status = %v, want %v", got, tt.wantStatus)
+			}
+			if got := len(ended[0].Events()); got != tt.wantEvents {
+				t.Errorf("got %d events, want %d", got, tt.wantEvents)
+			}
+		})
+	}
+
+	if got, want := jobOutcomes(t, reader), map[string]int64{"success": 1, "failure": 2}; !maps.Equal(got, want) {
+		t.Errorf("job.runs = %v, want %v", got, want)
+	}
+
+	// A new meter provider, as installed by setting the pipeline up again,
+	// records the following runs.
+	next := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(next)))
+	RunJob(ctx, "nightly", func(context.Context) error { return nil })
+	if got, want := jobOutcomes(t, next), map[string]int64{"success": 1}; !maps.Equal(got, want) {
+		t.Errorf("job.runs on the new provider = %v, want %v", got, want)
+	}
+}
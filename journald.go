@@ -0,0 +1,135 @@
+//go:build linux
+
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// journaldSocket is the socket of the systemd journal native protocol.
+const journaldSocket = "/run/systemd/journal/socket"
+
+// JournaldExporter is a log exporter writing records to the systemd
+// journal, with their severity as the PRIORITY field and their attributes
+// and trace context as additional fields, e.g. http.route as HTTP_ROUTE.
+// Pass it to WithLogExporter.
+type JournaldExporter struct {
+	identifier string
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+// NewJournaldExporter connects to the local journal. Records are tagged with
+// identifier as the SYSLOG_IDENTIFIER field, the program name if empty.
+func NewJournaldExporter(identifier string) (*JournaldExporter, error) {
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journaldSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &JournaldExporter{identifier: identifier, conn: conn}, nil
+}
+
+// Export sends each record as a journal entry.
+func (e *JournaldExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return errors.New("telemetry: journald exporter is shut down")
+	}
+
+	var errs []error
+	for i := range records {
+		if _, err := e.conn.Write(e.entry(&records[i])); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// entry encodes r in the journal native protocol.
+func (e *JournaldExporter) entry(r *sdklog.Record) []byte {
+	var b bytes.Buffer
+	journalField(&b, "MESSAGE", r.Body().String())
+	journalField(&b, "PRIORITY", strconv.Itoa(syslogPriority(r.Severity())))
+	journalField(&b, "SYSLOG_IDENTIFIER", e.identifier)
+	if r.TraceID().IsValid() {
+		journalField(&b, "TRACE_ID", r.TraceID().String())
+	}
+	if r.SpanID().IsValid() {
+		journalField(&b, "SPAN_ID", r.SpanID().String())
+	}
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		if name := journalFieldName(kv.Key); name != "" {
+			journalField(&b, name, kv.Value.String())
+		}
+		return true
+	})
+	return b.Bytes()
+}
+
+// journalField appends a field to b, using the binary form for values
+// spanning several lines.
+func journalField(b *bytes.Buffer, name, value string) {
+	b.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteByte('\n')
+	binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// journalFieldName converts an attribute key to a journal field name, made of
+// uppercase letters, digits and underscores and not starting with an
+// underscore, which is reserved for trusted fields. It returns "" if nothing
+// is left.
+func journalFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, key)
+	name = strings.TrimLeft(name, "_0123456789")
+	return name[:min(len(name), 64)]
+}
+
+// Shutdown closes the connection to the journal.
+func (e *JournaldExporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return nil
+	}
+	err := e.conn.Close()
+	e.conn = nil
+	return err
+}
+
+// ForceFlush does nothing, records are sent as they are exported.
+func (e *JournaldExporter) ForceFlush(context.Context) error {
+	return nil
+}
@@ -0,0 +1,81 @@
+//go:build linux
+
+package telemetry
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/log/logtest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestJournaldEntry(t *testing.T) {
+	e := &JournaldExporter{identifier: "checkout"}
+	tests := []struct {
+		name   string
+		record sdklog.Record
+		want   string
+	}{
+		{
+			name:   "fields",
+			record: logRecord("charged", log.SeverityWarn, log.String("http.route", "/orders/{id}"), log.Int("_retry", 2)),
+			want:   "MESSAGE=charged\nPRIORITY=4\nSYSLOG_IDENTIFIER=checkout\nHTTP_ROUTE=/orders/{id}\nRETRY=2\n",
+		},
+		{
+			name: "trace context",
+			record: logtest.RecordFactory{
+				Body:    log.StringValue("charged"),
+				TraceID: trace.TraceID{1},
+				SpanID:  trace.SpanID{2},
+			}.NewRecord(),
+			want: "MESSAGE=charged\nPRIORITY=6\nSYSLOG_IDENTIFIER=checkout\nTRACE_ID=01000000000000000000000000000000\nSPAN_ID=0200000000000000\n",
+		},
+		{
+			name:   "multiline message",
+			record: logRecord("a\nb", log.SeverityError),
+			want:   "MESSAGE\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\nPRIORITY=3\nSYSLOG_IDENTIFIER=checkout\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(e.entry(&tt.record)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJournalFieldName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"http.route", "HTTP_ROUTE"},
+		{"userId", "USERID"},
+		{"_private", "PRIVATE"},
+		{"2xx.count", "XX_COUNT"},
+		{"...", ""},
+		{strings.Repeat("a", 80), strings.Repeat("A", 64)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := journalFieldName(tt.key); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJournaldExporterShutdown(t *testing.T) {
+	e := &JournaldExporter{identifier: "checkout"}
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Export(context.Background(), []sdklog.Record{logRecord("late", log.SeverityInfo)}); err == nil {
+		t.Error("Export after Shutdown succeeded")
+	}
+}
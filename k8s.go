@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+var (
+	// k8sNameRE matches DNS subdomain names, used for pods and nodes.
+	k8sNameRE = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	// k8sLabelRE matches DNS labels, used for namespaces.
+	k8sLabelRE = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+)
+
+// kubernetesAttributes returns the k8s.* attributes set by the environment
+// variables conventionally injected through the downward API:
+//
+//	env:
+//	- name: POD_NAME
+//	  valueFrom: {fieldRef: {fieldPath: metadata.name}}
+//	- name: POD_UID
+//	  valueFrom: {fieldRef: {fieldPath: metadata.uid}}
+//	- name: NAMESPACE
+//	  valueFrom: {fieldRef: {fieldPath: metadata.namespace}}
+//	- name: NODE_NAME
+//	  valueFrom: {fieldRef: {fieldPath: spec.nodeName}}
+//
+// POD_NAMESPACE is accepted in place of NAMESPACE. Values that aren't valid
+// Kubernetes names are skipped and reported in the error.
+func kubernetesAttributes() ([]attribute.KeyValue, error) {
+	var attrs []attribute.KeyValue
+	var errs []error
+	add := func(key string, valid func(string) bool, attr func(string) attribute.KeyValue) {
+		v := os.Getenv(key)
+		if v == "" {
+			return
+		}
+		if !valid(v) {
+			errs = append(errs, fmt.Errorf("telemetry: invalid %s %q", key, v))
+			return
+		}
+		attrs = append(attrs, attr(v))
+	}
+
+	add("POD_NAME", isK8SName, semconv.K8SPodName)
+	add("POD_UID", isUUID, semconv.K8SPodUID)
+	if os.Getenv("NAMESPACE") != "" {
+		add("NAMESPACE", isK8SLabel, semconv.K8SNamespaceName)
+	} else {
+		add("POD_NAMESPACE", isK8SLabel, semconv.K8SNamespaceName)
+	}
+	add("NODE_NAME", isK8SName, semconv.K8SNodeName)
+	return attrs, errors.Join(errs...)
+}
+
+func isK8SName(s string) bool {
+	return len(s) <= 253 && k8sNameRE.MatchString(s)
+}
+
+func isK8SLabel(s string) bool {
+	return len(s) <= 63 && k8sLabelRE.MatchString(s)
+}
+
+func isUUID(s string) bool {
+	_, err := uuid.Parse(s)
+	return err == nil
+}
@@ -0,0 +1,97 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultKafkaTopic is the topic the collector's Kafka receiver reads spans
+// from by default.
+const defaultKafkaTopic = "otlp_spans"
+
+// WithKafka additionally produces spans to topic on the Kafka cluster at
+// brokers, "otlp_spans" if empty. It defaults to the comma-separated
+// TELEMETRY_KAFKA_BROKERS and TELEMETRY_KAFKA_TOPIC. Use WithSpanExporter
+// and NewKafkaExporter for more control.
+func WithKafka(brokers []string, topic string) Option {
+	return func(c *config) {
+		c.kafkaBrokers = brokers
+		c.kafkaTopic = topic
+	}
+}
+
+// KafkaConfig configures a KafkaExporter.
+type KafkaConfig struct {
+	// Brokers are the addresses of the brokers to bootstrap from, e.g.
+	// "kafka-0.kafka:9092".
+	Brokers []string
+	// Topic is the topic spans are produced to, "otlp_spans" by default.
+	Topic string
+	// Transport overrides the transport of the producer, for TLS or SASL.
+	Transport kafka.RoundTripper
+}
+
+// KafkaExporter is a span exporter producing spans to a Kafka topic, for
+// consumers tapping the trace stream without a collector. Each message holds
+// the spans of a trace of the batch, OTLP protobuf encoded as the
+// collector's otlp_proto encoding, and is keyed by the trace ID so the spans
+// of a trace land on the same partition. Pass it to WithSpanExporter.
+type KafkaExporter struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaExporter returns an exporter producing to the topic configured by
+// c. Connections are made on the first export.
+func NewKafkaExporter(c KafkaConfig) *KafkaExporter {
+	if c.Topic == "" {
+		c.Topic = defaultKafkaTopic
+	}
+	return &KafkaExporter{writer: &kafka.Writer{
+		Addr:         kafka.TCP(c.Brokers...),
+		Topic:        c.Topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+		Transport:    c.Transport,
+	}}
+}
+
+// ExportSpans produces a message per trace.
+func (e *KafkaExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	var (
+		traces = make(map[oteltrace.TraceID][]trace.ReadOnlySpan)
+		order  []oteltrace.TraceID
+	)
+	for _, s := range spans {
+		id := s.SpanContext().TraceID()
+		if _, ok := traces[id]; !ok {
+			order = append(order, id)
+		}
+		traces[id] = append(traces[id], s)
+	}
+
+	msgs := make([]kafka.Message, 0, len(order))
+	for _, id := range order {
+		value, err := proto.Marshal(otlpTraces(traces[id]))
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, kafka.Message{Key: id[:], Value: value})
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	if err := e.writer.WriteMessages(ctx, msgs...); err != nil {
+		return fmt.Errorf("telemetry: Kafka produce failed: %w", err)
+	}
+	return nil
+}
+
+// Shutdown closes the producer.
+func (e *KafkaExporter) Shutdown(context.Context) error {
+	return e.writer.Close()
+}
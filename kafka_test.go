@@ -0,0 +1,139 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+	"github.com/segmentio/kafka-go/protocol/metadata"
+	"github.com/segmentio/kafka-go/protocol/produce"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeKafka is a Kafka transport serving a topic of two partitions and
+// recording the messages produced to it.
+type fakeKafka struct {
+	mu       sync.Mutex
+	topic    string
+	messages []kafka.Message
+}
+
+func (k *fakeKafka) RoundTrip(_ context.Context, _ net.Addr, req kafka.Request) (kafka.Response, error) {
+	switch req := req.(type) {
+	case *metadata.Request:
+		return &metadata.Response{
+			Brokers: []metadata.ResponseBroker{{NodeID: 1, Host: "localhost", Port: 9092}},
+			Topics: []metadata.ResponseTopic{{
+				Name:       k.topic,
+				Partitions: []metadata.ResponsePartition{{PartitionIndex: 0, LeaderID: 1}, {PartitionIndex: 1, LeaderID: 1}},
+			}},
+		}, nil
+	case *produce.Request:
+		res := &produce.Response{}
+		for _, t := range req.Topics {
+			rt := produce.ResponseTopic{Topic: t.Topic}
+			for _, p := range t.Partitions {
+				if err := k.record(t.Topic, int(p.Partition), p.RecordSet.Records); err != nil {
+					return nil, err
+				}
+				rt.Partitions = append(rt.Partitions, produce.ResponsePartition{Partition: p.Partition})
+			}
+			res.Topics = append(res.Topics, rt)
+		}
+		return res, nil
+	}
+	return nil, errors.New("unexpected request")
+}
+
+func (k *fakeKafka) record(topic string, partition int, records protocol.RecordReader) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for {
+		r, err := records.ReadRecord()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		key, err := protocol.ReadAll(r.Key)
+		if err != nil {
+			return err
+		}
+		value, err := protocol.ReadAll(r.Value)
+		if err != nil {
+			return err
+		}
+		k.messages = append(k.messages, kafka.Message{Topic: topic, Partition: partition, Key: key, Value: value})
+	}
+}
+
+func TestKafkaExporter(t *testing.T) {
+	transport := &fakeKafka{topic: "otlp_spans"}
+	exporter := NewKafkaExporter(KafkaConfig{Brokers: []string{"localhost:9092"}, Transport: transport})
+	defer exporter.Shutdown(context.Background())
+
+	span := func(trace byte, name string) tracetest.SpanStub {
+		return tracetest.SpanStub{
+			Name:        name,
+			SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: oteltrace.TraceID{trace}, SpanID: oteltrace.SpanID{trace, byte(len(name))}}),
+		}
+	}
+	spans := tracetest.SpanStubs{span(1, "checkout"), span(2, "search"), span(1, "charge")}.Snapshots()
+	if err := exporter.ExportSpans(context.Background(), spans); err != nil {
+		t.Fatal(err)
+	}
+
+	wantTraces := map[oteltrace.TraceID][]string{
+		{1}: {"checkout", "charge"},
+		{2}: {"search"},
+	}
+	if len(transport.messages) != len(wantTraces) {
+		t.Fatalf("got %d messages, want one per trace", len(transport.messages))
+	}
+	partitions := make(map[oteltrace.TraceID]int)
+	for _, msg := range transport.messages {
+		if msg.Topic != "otlp_spans" {
+			t.Errorf("produced to %q, want otlp_spans", msg.Topic)
+		}
+		var traceID oteltrace.TraceID
+		copy(traceID[:], msg.Key)
+		var data tracepb.TracesData
+		if err := proto.Unmarshal(msg.Value, &data); err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, rs := range data.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					if oteltrace.TraceID(s.TraceId) != traceID {
+						t.Errorf("span of trace %x in the message keyed %s", s.TraceId, traceID)
+					}
+					names = append(names, s.Name)
+				}
+			}
+		}
+		if want := wantTraces[traceID]; !slices.Equal(names, want) {
+			t.Errorf("trace %s: got spans %v, want %v", traceID, names, want)
+		}
+		partitions[traceID] = msg.Partition
+	}
+
+	// The spans of a trace exported later land on the same partition.
+	transport.messages = nil
+	if err := exporter.ExportSpans(context.Background(), tracetest.SpanStubs{span(2, "render")}.Snapshots()); err != nil {
+		t.Fatal(err)
+	}
+	if len(transport.messages) != 1 || transport.messages[0].Partition != partitions[oteltrace.TraceID{2}] {
+		t.Errorf("later span of trace 2 produced to %+v, want partition %d", transport.messages, partitions[oteltrace.TraceID{2}])
+	}
+}
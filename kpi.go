@@ -0,0 +1,104 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// kpiNamespace prefixes the names of the business metrics.
+const kpiNamespace = "business."
+
+var (
+	// kpiNamePattern is the snake_case form required of KPI names.
+	kpiNamePattern = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	// kpiAttributePattern is the dotted snake_case form required of KPI
+	// attribute keys, e.g. "payment.method".
+	kpiAttributePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+)
+
+// KPI declares a business metric, such as the number of orders placed,
+// counted by a KPICounter.
+type KPI struct {
+	// Name is the snake_case name of the metric, e.g. "orders_placed". It
+	// is exported as business.<name>.
+	Name string
+	// Description says what the metric counts.
+	Description string
+	// Unit is the UCUM unit of the metric, required: an annotation such as
+	// "{order}" for counts, or e.g. "USD" for amounts.
+	Unit string
+	// Attributes are the keys every measurement must carry, e.g.
+	// "payment.method".
+	Attributes []string
+	// Optional are the keys measurements may carry in addition to
+	// Attributes. Any other attribute is rejected, to keep the cardinality
+	// under control.
+	Optional []string
+}
+
+// KPICounter counts a business metric declared with a KPI.
+type KPICounter struct {
+	kpi     KPI
+	counter metric.Float64Counter
+}
+
+// NewKPICounter declares kpi on the given meter provider. It returns an
+// error if the name, unit or attribute keys don't follow the conventions.
+func NewKPICounter(mp metric.MeterProvider, kpi KPI) (*KPICounter, error) {
+	if !kpiNamePattern.MatchString(kpi.Name) {
+		return nil, fmt.Errorf("telemetry: KPI name %q must be snake_case", kpi.Name)
+	}
+	if kpi.Unit == "" {
+		return nil, fmt.Errorf("telemetry: KPI %q unit is required", kpi.Name)
+	}
+	for _, key := range slices.Concat(kpi.Attributes, kpi.Optional) {
+		if !kpiAttributePattern.MatchString(key) {
+			return nil, fmt.Errorf("telemetry: KPI %q attribute %q must be dotted snake_case", kpi.Name, key)
+		}
+	}
+
+	counter, err := mp.Meter(instrumentationName).Float64Counter(kpiNamespace+kpi.Name,
+		metric.WithUnit(kpi.Unit),
+		metric.WithDescription(kpi.Description))
+	if err != nil {
+		return nil, err
+	}
+	return &KPICounter{kpi: kpi, counter: counter}, nil
+}
+
+// Add adds value, which must not be negative, to the counter. Nothing is
+// recorded and an error is returned if a mandatory attribute is missing or
+// an undeclared one is given.
+func (c *KPICounter) Add(ctx context.Context, value float64, attrs ...attribute.KeyValue) error {
+	if value < 0 {
+		return fmt.Errorf("telemetry: KPI %q can't be decreased", c.kpi.Name)
+	}
+	var errs []error
+	for _, key := range c.kpi.Attributes {
+		if !slices.ContainsFunc(attrs, func(kv attribute.KeyValue) bool { return string(kv.Key) == key }) {
+			errs = append(errs, fmt.Errorf("telemetry: KPI %q requires attribute %q", c.kpi.Name, key))
+		}
+	}
+	for _, kv := range attrs {
+		key := string(kv.Key)
+		if !slices.Contains(c.kpi.Attributes, key) && !slices.Contains(c.kpi.Optional, key) {
+			errs = append(errs, fmt.Errorf("telemetry: KPI %q doesn't declare attribute %q", c.kpi.Name, key))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	c.counter.Add(ctx, value, metric.WithAttributes(attrs...))
+	return nil
+}
+
+// Inc adds 1 to the counter, like Add.
+func (c *KPICounter) Inc(ctx context.Context, attrs ...attribute.KeyValue) error {
+	return c.Add(ctx, 1, attrs...)
+}
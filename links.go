@@ -0,0 +1,36 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LinkFrom returns a link to the span of ctx with attrs, to be passed to
+// StartWithLinks. Spans that fan out work, or aggregate the work of many
+// upstream spans, link to them instead of becoming their child.
+func LinkFrom(ctx context.Context, attrs ...attribute.KeyValue) trace.Link {
+	return trace.Link{SpanContext: trace.SpanContextFromContext(ctx), Attributes: attrs}
+}
+
+// LinkFromCarrier returns a link to the span whose trace context was sent in
+// carrier, e.g. the headers of each message of a batch being processed.
+func LinkFromCarrier(ctx context.Context, carrier propagation.TextMapCarrier, attrs ...attribute.KeyValue) trace.Link {
+	return LinkFrom(otel.GetTextMapPropagator().Extract(ctx, carrier), attrs...)
+}
+
+// StartWithLinks starts a span named name as a child of the span of ctx,
+// linked to links. Links without a valid span context, e.g. from messages
+// sent without trace context, are skipped.
+func StartWithLinks(ctx context.Context, name string, links ...trace.Link) (context.Context, trace.Span) {
+	valid := make([]trace.Link, 0, len(links))
+	for _, l := range links {
+		if l.SpanContext.IsValid() {
+			valid = append(valid, l)
+		}
+	}
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithLinks(valid...))
+}
@@ -0,0 +1,59 @@
+package telemetry
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// syslogPriority maps a log severity to the corresponding syslog severity,
+// from 0 (emergency) to 7 (debug).
+func syslogPriority(s log.Severity) int {
+	switch {
+	case s >= log.SeverityFatal1:
+		return 2 // critical
+	case s >= log.SeverityError1:
+		return 3 // error
+	case s >= log.SeverityWarn1:
+		return 4 // warning
+	case s >= log.SeverityInfo1, s == log.SeverityUndefined:
+		return 6 // informational
+	}
+	return 7 // debug
+}
+
+// logLine formats r as a single line: its body followed by its attributes,
+// but the omitted ones, and trace context as key=value pairs.
+func logLine(r *sdklog.Record, omit ...string) string {
+	var b strings.Builder
+	b.WriteString(r.Body().String())
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		if slices.Contains(omit, kv.Key) {
+			return true
+		}
+		b.WriteByte(' ')
+		b.WriteString(kv.Key)
+		b.WriteByte('=')
+		b.WriteString(quoteLogValue(kv.Value.String()))
+		return true
+	})
+	if r.TraceID().IsValid() {
+		b.WriteString(" trace_id=" + r.TraceID().String())
+	}
+	if r.SpanID().IsValid() {
+		b.WriteString(" span_id=" + r.SpanID().String())
+	}
+	return strings.ReplaceAll(b.String(), "\n", `\n`)
+}
+
+// quoteLogValue quotes v if it contains spaces or quotes, to keep the pairs
+// of logLine parseable.
+func quoteLogValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " \"=\n") {
+		return strconv.Quote(v)
+	}
+	return v
+}
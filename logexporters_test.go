@@ -0,0 +1,67 @@
+package telemetry
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/log/logtest"
+)
+
+// logRecord returns a record with body, severity and attributes.
+func logRecord(body string, severity log.Severity, attrs ...log.KeyValue) sdklog.Record {
+	return logtest.RecordFactory{
+		Severity:   severity,
+		Body:       log.StringValue(body),
+		Attributes: attrs,
+	}.NewRecord()
+}
+
+func TestSyslogPriority(t *testing.T) {
+	tests := []struct {
+		severity log.Severity
+		want     int
+	}{
+		{log.SeverityFatal4, 2},
+		{log.SeverityFatal, 2},
+		{log.SeverityError3, 3},
+		{log.SeverityWarn, 4},
+		{log.SeverityInfo2, 6},
+		{log.SeverityUndefined, 6},
+		{log.SeverityDebug4, 7},
+		{log.SeverityTrace, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.severity.String(), func(t *testing.T) {
+			if got := syslogPriority(tt.severity); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		record sdklog.Record
+		omit   []string
+		want   string
+	}{
+		{"body only", logRecord("started", log.SeverityInfo), nil, "started"},
+		{
+			"quoted values",
+			logRecord("login", log.SeverityInfo, log.String("user", "ann"), log.String("agent", `curl "8"`), log.String("empty", "")),
+			nil,
+			`login user=ann agent="curl \"8\"" empty=""`,
+		},
+		{"omitted attributes", logRecord("login", log.SeverityInfo, log.String("user", "ann"), log.Int("tries", 2)), []string{"user"}, "login tries=2"},
+		{"single line", logRecord("panic\nat main", log.SeverityError, log.String("stack", "a\nb")), nil, `panic\nat main stack="a\nb"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logLine(&tt.record, tt.omit...); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// logMinSeverity is the minimum severity of the records exported by the log
+// pipeline. Records without a severity are always exported.
+var logMinSeverity atomic.Int64
+
+// SetLogMinSeverity changes the minimum severity of the records exported by
+// the log pipeline. It takes effect immediately and can be called at any time.
+func SetLogMinSeverity(severity log.Severity) {
+	logMinSeverity.Store(int64(severity))
+}
+
+// LogMinSeverity returns the current minimum severity of the log pipeline.
+func LogMinSeverity() log.Severity {
+	return log.Severity(logMinSeverity.Load())
+}
+
+// WithLogMinSeverity sets the initial minimum severity of exported records.
+// It overrides the TELEMETRY_LOGS_MIN_SEVERITY environment variable.
+func WithLogMinSeverity(severity log.Severity) Option {
+	return func(c *config) {
+		c.logMinSeverity = severity
+	}
+}
+
+// ParseSeverity parses a severity name such as "debug", "info", "warn" or
+// "error", case-insensitively. Numbered variants like "info2" are accepted.
+func ParseSeverity(s string) (log.Severity, error) {
+	name := strings.ToUpper(strings.TrimSpace(s))
+	if name == "WARNING" {
+		name = "WARN"
+	}
+	for sev := log.SeverityTrace1; sev <= log.SeverityFatal4; sev++ {
+		if sev.String() == name {
+			return sev, nil
+		}
+	}
+	return log.SeverityUndefined, fmt.Errorf("telemetry: unknown log severity %q", s)
+}
+
+// severityFilter drops records below logMinSeverity before they reach the
+// wrapped processor.
+type severityFilter struct {
+	sdklog.Processor
+}
+
+func (f severityFilter) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	if !severityEnabled(record.Severity()) {
+		return nil
+	}
+	return f.Processor.OnEmit(ctx, record)
+}
+
+// Enabled lets bridges skip building records that would be dropped.
+func (f severityFilter) Enabled(_ context.Context, param log.EnabledParameters) bool {
+	return severityEnabled(param.Severity)
+}
+
+func severityEnabled(severity log.Severity) bool {
+	return severity == log.SeverityUndefined || severity >= LogMinSeverity()
+}
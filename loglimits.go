@@ -0,0 +1,52 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// WithLogLimits bounds the log records exported by the log pipeline:
+// bodies longer than maxBodyBytes are truncated with the "...[truncated]"
+// marker, and attributes beyond the first maxAttributes are dropped and
+// counted in the record's dropped attributes count. Structured bodies are
+// exported as their truncated string form when they are too large. Zero
+// leaves the corresponding limit unset. The limits can also be set with the
+// TELEMETRY_LOG_MAX_BODY_BYTES and TELEMETRY_LOG_MAX_ATTRIBUTES environment
+// variables.
+func WithLogLimits(maxBodyBytes, maxAttributes int) Option {
+	return func(c *config) {
+		c.logMaxBodyBytes = maxBodyBytes
+		c.logMaxAttributes = maxAttributes
+	}
+}
+
+// logBodyLimiter truncates large record bodies before they reach the next
+// processors.
+type logBodyLimiter struct {
+	limit int
+}
+
+func (l logBodyLimiter) OnEmit(_ context.Context, r *sdklog.Record) error {
+	body := r.Body()
+	switch body.Kind() {
+	case log.KindEmpty, log.KindBool, log.KindInt64, log.KindFloat64:
+	case log.KindString:
+		if s, ok := truncate(body.AsString(), l.limit); ok {
+			r.SetBody(log.StringValue(s))
+		}
+	case log.KindBytes:
+		if b := body.AsBytes(); len(b) > l.limit {
+			r.SetBody(log.BytesValue(b[:l.limit]))
+		}
+	default:
+		if s, ok := truncate(body.String(), l.limit); ok {
+			r.SetBody(log.StringValue(s))
+		}
+	}
+	return nil
+}
+
+func (logBodyLimiter) Shutdown(context.Context) error   { return nil }
+func (logBodyLimiter) ForceFlush(context.Context) error { return nil }
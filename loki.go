@@ -0,0 +1,195 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// lokiTimeout bounds each Loki push request.
+const lokiTimeout = 30 * time.Second
+
+// defaultLokiLabels are the attributes mapped to Loki labels by default.
+var defaultLokiLabels = map[string]string{
+	"service.name":           "service_name",
+	"service.namespace":      "service_namespace",
+	"deployment.environment": "deployment_environment",
+	"k8s.namespace.name":     "k8s_namespace_name",
+}
+
+// WithLoki additionally pushes log records to the Grafana Loki push API at
+// url, such as "http://loki:3100/loki/api/v1/push", with the default label
+// mapping of LokiConfig. It defaults to TELEMETRY_LOKI_URL. Use
+// WithLogExporter and NewLokiExporter for more control.
+func WithLoki(url string) Option {
+	return func(c *config) {
+		c.lokiURL = url
+	}
+}
+
+// LokiConfig configures a LokiExporter.
+type LokiConfig struct {
+	// URL is the push endpoint, e.g. "http://loki:3100/loki/api/v1/push".
+	URL string
+	// TenantID is sent as the X-Scope-OrgID header of multi-tenant
+	// deployments, if set.
+	TenantID string
+	// Labels maps the resource and record attributes to turn into stream
+	// labels to the label names. Keep it to low cardinality attributes;
+	// the others are appended to the log line. By default service.name,
+	// service.namespace, deployment.environment and
+	// k8s.namespace.name are mapped, with dots replaced by underscores.
+	// The severity is always the level label.
+	Labels map[string]string
+}
+
+// LokiExporter is a log exporter pushing records to the Grafana Loki push
+// API, for teams running Loki without an OpenTelemetry collector. Each line
+// holds the body, the attributes that aren't labels and the trace context as
+// key=value pairs. Pass it to WithLogExporter.
+type LokiExporter struct {
+	url    string
+	tenant string
+	labels map[string]string
+	client *http.Client
+
+	mu       sync.Mutex
+	shutdown bool
+}
+
+// NewLokiExporter returns an exporter pushing to the Loki configured by c.
+func NewLokiExporter(c LokiConfig) *LokiExporter {
+	if c.Labels == nil {
+		c.Labels = defaultLokiLabels
+	}
+	labels := make(map[string]string, len(c.Labels))
+	for key, name := range c.Labels {
+		labels[key] = promName(name)
+	}
+	return &LokiExporter{
+		url:    c.URL,
+		tenant: c.TenantID,
+		labels: labels,
+		client: &http.Client{Timeout: lokiTimeout},
+	}
+}
+
+// lokiStream is a stream of the push API JSON payload.
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// Export pushes records, grouped into streams by labels.
+func (e *LokiExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	shutdown := e.shutdown
+	e.mu.Unlock()
+	if shutdown || len(records) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string][]*lokiStream{"streams": e.streams(records)})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.tenant != "" {
+		req.Header.Set("X-Scope-OrgID", e.tenant)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("telemetry: Loki push failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("telemetry: Loki push failed: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// streams groups records into streams by their labels.
+func (e *LokiExporter) streams(records []sdklog.Record) []*lokiStream {
+	byLabels := make(map[string]*lokiStream)
+	var streams []*lokiStream
+	for i := range records {
+		r := &records[i]
+		labels := map[string]string{"level": lokiLevel(r.Severity())}
+		res := r.Resource()
+		for _, kv := range res.Attributes() {
+			if name, ok := e.labels[string(kv.Key)]; ok {
+				labels[name] = kv.Value.Emit()
+			}
+		}
+		var omit []string
+		r.WalkAttributes(func(kv log.KeyValue) bool {
+			if name, ok := e.labels[kv.Key]; ok {
+				labels[name] = kv.Value.String()
+				omit = append(omit, kv.Key)
+			}
+			return true
+		})
+
+		key := lokiStreamKey(labels)
+		s, ok := byLabels[key]
+		if !ok {
+			s = &lokiStream{Stream: labels}
+			byLabels[key] = s
+			streams = append(streams, s)
+		}
+		ts := r.Timestamp()
+		if ts.IsZero() {
+			ts = r.ObservedTimestamp()
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(ts.UnixNano(), 10), logLine(r, omit...)})
+	}
+	return streams
+}
+
+// lokiStreamKey identifies the stream with labels.
+func lokiStreamKey(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for name, value := range labels {
+		pairs = append(pairs, name+"="+strconv.Quote(value))
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// lokiLevel returns the level label of severity s, e.g. "warn".
+func lokiLevel(s log.Severity) string {
+	if s == log.SeverityUndefined {
+		return "unknown"
+	}
+	return strings.ToLower(strings.TrimRight(s.String(), "234"))
+}
+
+// ForceFlush does nothing; the exporter holds no state.
+func (e *LokiExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+// Shutdown makes subsequent exports no-ops.
+func (e *LokiExporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	e.shutdown = true
+	e.mu.Unlock()
+	return nil
+}
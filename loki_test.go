@@ -0,0 +1,117 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/log/logtest"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func TestLokiExporter(t *testing.T) {
+	var (
+		tenant string
+		pushed map[string][]lokiStream
+		status = http.StatusNoContent
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = r.Header.Get("X-Scope-OrgID")
+		pushed = nil
+		if err := json.NewDecoder(r.Body).Decode(&pushed); err != nil {
+			t.Error(err)
+		}
+		if status != http.StatusNoContent {
+			http.Error(w, "entry too far behind", status)
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	exporter := NewLokiExporter(LokiConfig{
+		URL:      srv.URL,
+		TenantID: "team-a",
+		Labels:   map[string]string{"service.name": "service_name", "http.route": "http.route"},
+	})
+	res := resource.NewSchemaless(semconv.ServiceName("checkout"), semconv.ServiceVersion("1.2.0"))
+	ts := time.Unix(1700000000, 5)
+	record := func(severity log.Severity, body string, attrs ...log.KeyValue) sdklog.Record {
+		return logtest.RecordFactory{
+			Timestamp:  ts,
+			Severity:   severity,
+			Body:       log.StringValue(body),
+			Attributes: attrs,
+			Resource:   res,
+		}.NewRecord()
+	}
+	records := []sdklog.Record{
+		record(log.SeverityInfo, "charged", log.String("http.route", "/orders"), log.String("order.id", "o1")),
+		record(log.SeverityInfo2, "refunded", log.String("http.route", "/orders")),
+		record(log.SeverityWarn, "slow"),
+	}
+	if err := exporter.Export(context.Background(), records); err != nil {
+		t.Fatal(err)
+	}
+
+	if tenant != "team-a" {
+		t.Errorf("X-Scope-OrgID = %q, want team-a", tenant)
+	}
+	want := []lokiStream{
+		{
+			Stream: map[string]string{"level": "info", "service_name": "checkout", "http_route": "/orders"},
+			Values: [][2]string{{"1700000000000000005", "charged order.id=o1"}, {"1700000000000000005", "refunded"}},
+		},
+		{
+			Stream: map[string]string{"level": "warn", "service_name": "checkout"},
+			Values: [][2]string{{"1700000000000000005", "slow"}},
+		},
+	}
+	if got := pushed["streams"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("pushed streams %+v, want %+v", got, want)
+	}
+
+	status = http.StatusBadRequest
+	err := exporter.Export(context.Background(), records[:1])
+	if err == nil || !strings.Contains(err.Error(), "400 Bad Request: entry too far behind") {
+		t.Errorf("got %v, want the Loki error", err)
+	}
+
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	pushed = nil
+	if err := exporter.Export(context.Background(), records); err != nil || pushed != nil {
+		t.Errorf("Export after Shutdown pushed %v, %v", pushed, err)
+	}
+}
+
+func TestLokiLevel(t *testing.T) {
+	tests := []struct {
+		severity log.Severity
+		want     string
+	}{
+		{log.SeverityUndefined, "unknown"},
+		{log.SeverityTrace3, "trace"},
+		{log.SeverityDebug, "debug"},
+		{log.SeverityInfo4, "info"},
+		{log.SeverityWarn2, "warn"},
+		{log.SeverityError, "error"},
+		{log.SeverityFatal3, "fatal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.severity.String(), func(t *testing.T) {
+			if got := lokiLevel(tt.severity); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
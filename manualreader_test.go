@@ -0,0 +1,86 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// counterSum adds up the points of the int64 counter name that carry attrs.
+func counterSum(rm *metricdata.ResourceMetrics, name string, attrs []attribute.KeyValue) int64 {
+	var sum int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				match := true
+				for _, kv := range attrs {
+					if v, ok := dp.Attributes.Value(kv.Key); !ok || v != kv.Value {
+						match = false
+					}
+				}
+				if match {
+					sum += dp.Value
+				}
+			}
+		}
+	}
+	return sum
+}
+
+func TestSetupOTelSDKManualReader(t *testing.T) {
+	ctx := context.Background()
+	collect, shutdown, err := SetupOTelSDKManualReader(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(ctx)
+
+	orders, err := otel.Meter("test").Int64Counter("orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eu := attribute.String("region", "eu")
+	tests := []struct {
+		name    string
+		add     int64
+		attrs   []attribute.KeyValue
+		wantAll int64
+		wantEU  int64
+	}{
+		{"first", 2, []attribute.KeyValue{eu}, 2, 2},
+		{"cumulative", 3, []attribute.KeyValue{eu}, 5, 5},
+		{"other series", 4, []attribute.KeyValue{attribute.String("region", "us")}, 9, 5},
+		{"nothing added", 0, nil, 9, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.add > 0 {
+				orders.Add(ctx, tt.add, metric.WithAttributes(tt.attrs...))
+			}
+			rm, err := collect(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := counterSum(&rm, "orders", nil); got != tt.wantAll {
+				t.Errorf("orders = %v, want %v", got, tt.wantAll)
+			}
+			if got := counterSum(&rm, "orders", []attribute.KeyValue{eu}); got != tt.wantEU {
+				t.Errorf("orders{region=eu} = %v, want %v", got, tt.wantEU)
+			}
+		})
+	}
+
+	if err := shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := collect(ctx); err == nil {
+		t.Error("collect after shutdown succeeded")
+	}
+}
@@ -0,0 +1,354 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/metric"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// memoryBatchSize is the maximum number of items exported at once.
+	memoryBatchSize = 512
+	// memoryExportTimeout bounds each background export unless an export
+	// timeout is configured.
+	memoryExportTimeout = 30 * time.Second
+	// memoryDegradeThreshold is the fraction of the limit above which the
+	// DegradeSampling policy stops sampling new traces.
+	memoryDegradeThreshold = 0.5
+	// recordOverhead approximates the fixed size of a span or log record.
+	recordOverhead = 256
+)
+
+// MemoryPolicy selects what happens to new telemetry once the memory limit
+// set by WithMemoryLimit is reached.
+type MemoryPolicy int
+
+const (
+	// DropNew discards incoming spans and log records while the buffer is full.
+	DropNew MemoryPolicy = iota
+	// DropOldest evicts the oldest buffered items to make room for new ones.
+	DropOldest
+	// DegradeSampling stops sampling new traces once the buffer is half full,
+	// and otherwise behaves like DropNew.
+	DegradeSampling
+)
+
+// String returns the name used for p in TELEMETRY_MEMORY_LIMIT_POLICY.
+func (p MemoryPolicy) String() string {
+	switch p {
+	case DropNew:
+		return "drop_new"
+	case DropOldest:
+		return "drop_oldest"
+	case DegradeSampling:
+		return "degrade_sampling"
+	}
+	return fmt.Sprintf("MemoryPolicy(%d)", int(p))
+}
+
+// ParseMemoryPolicy parses a policy name as returned by MemoryPolicy.String.
+func ParseMemoryPolicy(s string) (MemoryPolicy, error) {
+	for _, p := range []MemoryPolicy{DropNew, DropOldest, DegradeSampling} {
+		if strings.EqualFold(s, p.String()) {
+			return p, nil
+		}
+	}
+	return DropNew, fmt.Errorf("telemetry: unknown memory limit policy %q", s)
+}
+
+// WithMemoryLimit caps the estimated size of the spans and log records
+// waiting to be exported to limit bytes per signal, applying policy once it
+// is reached. Without a limit, an unreachable collector lets the buffers grow
+// until the batch processors' queue lengths are reached. Dropped items are
+// counted by the telemetry.memory_limiter.dropped metric. Metrics are
+// aggregated in place and are bounded by their cardinality instead. The
+// limit can also be set in MiB with the TELEMETRY_MEMORY_LIMIT_MIB
+// environment variable and the policy with TELEMETRY_MEMORY_LIMIT_POLICY.
+func WithMemoryLimit(limit int64, policy MemoryPolicy) Option {
+	return func(c *config) {
+		c.memoryLimit = limit
+		c.memoryPolicy = policy
+	}
+}
+
+// envMemoryPolicy reads a memory limit policy from the environment variable
+// key.
+func envMemoryPolicy(key string) MemoryPolicy {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return DropNew
+	}
+	p, err := ParseMemoryPolicy(v)
+	if err != nil {
+		otel.Handle(err)
+	}
+	return p
+}
+
+// memoryQueue is a byte-bounded FIFO of items waiting to be exported. It is
+// drained in batches by a background goroutine every interval, or as soon as
+// a full batch is available.
+type memoryQueue[T any] struct {
+	limit   int64
+	policy  MemoryPolicy
+	timeout time.Duration
+	size    func(T) int64
+	export  func(context.Context, []T) error
+	dropped metric.Int64Counter
+	signal  metric.MeasurementOption
+
+	mu    sync.Mutex
+	items []T
+	sizes []int64
+	bytes int64
+
+	exportMu sync.Mutex
+	wake     chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newMemoryQueue[T any](signal string, limit int64, policy MemoryPolicy, interval, timeout time.Duration,
+	size func(T) int64, export func(context.Context, []T) error) *memoryQueue[T] {
+	dropped, err := otel.Meter(instrumentationName).Int64Counter("telemetry.memory_limiter.dropped",
+		metric.WithUnit("{item}"),
+		metric.WithDescription("Number of telemetry items dropped because the memory limit was reached."))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	if timeout <= 0 {
+		timeout = memoryExportTimeout
+	}
+	q := &memoryQueue[T]{
+		limit:   limit,
+		policy:  policy,
+		timeout: timeout,
+		size:    size,
+		export:  export,
+		dropped: dropped,
+		signal:  metric.WithAttributes(attribute.String("signal", signal)),
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go q.run(interval)
+	return q
+}
+
+// push enqueues item, applying the policy if it doesn't fit.
+func (q *memoryQueue[T]) push(item T) {
+	n := q.size(item)
+
+	q.mu.Lock()
+	var dropped int64
+	if q.policy == DropOldest {
+		for q.bytes+n > q.limit && len(q.items) > 0 {
+			q.bytes -= q.sizes[0]
+			q.items, q.sizes = q.items[1:], q.sizes[1:]
+			dropped++
+		}
+	}
+	if q.bytes+n > q.limit {
+		dropped++
+	} else {
+		q.items = append(q.items, item)
+		q.sizes = append(q.sizes, n)
+		q.bytes += n
+	}
+	full := len(q.items) >= memoryBatchSize
+	q.mu.Unlock()
+
+	if dropped > 0 && q.dropped != nil {
+		q.dropped.Add(context.Background(), dropped, q.signal)
+	}
+	if full {
+		select {
+		case q.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// pressure returns the fraction of the limit currently in use.
+func (q *memoryQueue[T]) pressure() float64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return float64(q.bytes) / float64(q.limit)
+}
+
+func (q *memoryQueue[T]) run(interval time.Duration) {
+	defer close(q.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), q.timeout)
+		if err := q.flush(ctx); err != nil {
+			otel.Handle(err)
+		}
+		cancel()
+	}
+}
+
+// flush exports every queued item.
+func (q *memoryQueue[T]) flush(ctx context.Context) error {
+	q.exportMu.Lock()
+	defer q.exportMu.Unlock()
+
+	var err error
+	for {
+		q.mu.Lock()
+		n := min(len(q.items), memoryBatchSize)
+		batch := q.items[:n:n]
+		for _, s := range q.sizes[:n] {
+			q.bytes -= s
+		}
+		q.items, q.sizes = q.items[n:], q.sizes[n:]
+		q.mu.Unlock()
+
+		if n == 0 {
+			return err
+		}
+		err = errors.Join(err, q.export(ctx, batch))
+		if ctx.Err() != nil {
+			return errors.Join(err, ctx.Err())
+		}
+	}
+}
+
+// shutdown stops the background export and flushes the remaining items.
+func (q *memoryQueue[T]) shutdown(ctx context.Context) error {
+	q.stopOnce.Do(func() { close(q.stop) })
+	select {
+	case <-q.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return q.flush(ctx)
+}
+
+// memoryLimitedSpanProcessor batches sampled spans to exporter through a
+// memoryQueue. It replaces the SDK batch processor when a limit is set.
+type memoryLimitedSpanProcessor struct {
+	exporter trace.SpanExporter
+	queue    *memoryQueue[trace.ReadOnlySpan]
+}
+
+func newMemoryLimitedSpanProcessor(exporter trace.SpanExporter, limit int64, policy MemoryPolicy, interval, timeout time.Duration) *memoryLimitedSpanProcessor {
+	return &memoryLimitedSpanProcessor{
+		exporter: exporter,
+		queue:    newMemoryQueue("traces", limit, policy, interval, timeout, spanSize, exporter.ExportSpans),
+	}
+}
+
+func (p *memoryLimitedSpanProcessor) OnStart(context.Context, trace.ReadWriteSpan) {}
+
+func (p *memoryLimitedSpanProcessor) OnEnd(s trace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.queue.push(s)
+	}
+}
+
+func (p *memoryLimitedSpanProcessor) ForceFlush(ctx context.Context) error {
+	return p.queue.flush(ctx)
+}
+
+func (p *memoryLimitedSpanProcessor) Shutdown(ctx context.Context) error {
+	return errors.Join(p.queue.shutdown(ctx), p.exporter.Shutdown(ctx))
+}
+
+// memoryPressureSampler stops sampling new traces while the span queue is
+// above memoryDegradeThreshold, delegating to base otherwise.
+type memoryPressureSampler struct {
+	base  trace.Sampler
+	queue *memoryQueue[trace.ReadOnlySpan]
+}
+
+func (s memoryPressureSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	parent := oteltrace.SpanContextFromContext(p.ParentContext)
+	if !parent.IsValid() && s.queue.pressure() > memoryDegradeThreshold {
+		return trace.SamplingResult{Decision: trace.Drop}
+	}
+	return s.base.ShouldSample(p)
+}
+
+func (s memoryPressureSampler) Description() string {
+	return "MemoryPressureSampler{" + s.base.Description() + "}"
+}
+
+// memoryLimitedLogProcessor batches log records to exporter through a
+// memoryQueue. It replaces the SDK batch processor when a limit is set.
+type memoryLimitedLogProcessor struct {
+	exporter sdklog.Exporter
+	queue    *memoryQueue[sdklog.Record]
+}
+
+func newMemoryLimitedLogProcessor(exporter sdklog.Exporter, limit int64, policy MemoryPolicy, timeout time.Duration) *memoryLimitedLogProcessor {
+	return &memoryLimitedLogProcessor{
+		exporter: exporter,
+		queue:    newMemoryQueue("logs", limit, policy, time.Second, timeout, recordSize, exporter.Export),
+	}
+}
+
+func (p *memoryLimitedLogProcessor) OnEmit(_ context.Context, r *sdklog.Record) error {
+	p.queue.push(r.Clone())
+	return nil
+}
+
+func (p *memoryLimitedLogProcessor) ForceFlush(ctx context.Context) error {
+	return errors.Join(p.queue.flush(ctx), p.exporter.ForceFlush(ctx))
+}
+
+func (p *memoryLimitedLogProcessor) Shutdown(ctx context.Context) error {
+	return errors.Join(p.queue.shutdown(ctx), p.exporter.Shutdown(ctx))
+}
+
+// spanSize estimates the memory held by s.
+func spanSize(s trace.ReadOnlySpan) int64 {
+	n := int64(recordOverhead + len(s.Name()))
+	n += attributesSize(s.Attributes())
+	for _, e := range s.Events() {
+		n += int64(recordOverhead/4+len(e.Name)) + attributesSize(e.Attributes)
+	}
+	for _, l := range s.Links() {
+		n += recordOverhead/4 + attributesSize(l.Attributes)
+	}
+	return n
+}
+
+func attributesSize(attrs []attribute.KeyValue) int64 {
+	var n int64
+	for _, kv := range attrs {
+		n += int64(len(kv.Key) + len(kv.Value.Emit()))
+	}
+	return n
+}
+
+// recordSize estimates the memory held by r.
+func recordSize(r sdklog.Record) int64 {
+	n := int64(recordOverhead + len(r.Body().String()))
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		n += int64(len(kv.Key) + len(kv.Value.String()))
+		return true
+	})
+	return n
+}
@@ -0,0 +1,68 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BytesMapCarrier adapts a map[string][]byte of message headers to a
+// propagation.TextMapCarrier. Use propagation.MapCarrier for map[string]string.
+type BytesMapCarrier map[string][]byte
+
+// Compile time check that BytesMapCarrier implements the TextMapCarrier.
+var _ propagation.TextMapCarrier = BytesMapCarrier{}
+
+// Get returns the value associated with the passed key.
+func (c BytesMapCarrier) Get(key string) string {
+	return string(c[key])
+}
+
+// Set stores the key-value pair.
+func (c BytesMapCarrier) Set(key, value string) {
+	c[key] = []byte(value)
+}
+
+// Keys lists the keys stored in this carrier.
+func (c BytesMapCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// StartProducerSpan starts a producer span for a message published by system
+// to destination and injects the resulting trace context into carrier, which
+// should then be sent along with the message.
+func StartProducerSpan(ctx context.Context, system, destination string, carrier propagation.TextMapCarrier, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	opts = append([]trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String(system),
+			semconv.MessagingDestinationName(destination),
+			semconv.MessagingOperationTypePublish,
+		),
+	}, opts...)
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, "publish "+destination, opts...)
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return ctx, span
+}
+
+// StartConsumerSpan extracts the trace context sent with a message from
+// carrier and starts a consumer span for processing it as its child.
+func StartConsumerSpan(ctx context.Context, system, destination string, carrier propagation.TextMapCarrier, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+	opts = append([]trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String(system),
+			semconv.MessagingDestinationName(destination),
+			semconv.MessagingOperationTypeDeliver,
+		),
+	}, opts...)
+	return otel.Tracer(instrumentationName).Start(ctx, "process "+destination, opts...)
+}
@@ -0,0 +1,82 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/sdk/metric"
+)
+
+// MetricRule renames or drops the instruments whose name matches Match.
+type MetricRule struct {
+	// Match is an instrument name. It may contain the wildcards * and ?.
+	Match string
+	// Rename is the name the matched instrument is exported under. It cannot
+	// be combined with a wildcard in Match.
+	Rename string
+	// Drop discards all data recorded by the matched instruments.
+	Drop bool
+}
+
+// WithMetricRules adds rules applied to the meter provider as views. They are
+// applied after the rules from the TELEMETRY_METRIC_RULES environment variable.
+func WithMetricRules(rules ...MetricRule) Option {
+	return func(c *config) {
+		c.metricRules = append(c.metricRules, rules...)
+	}
+}
+
+// ParseMetricRules parses a comma separated list of rules of the form
+// "drop:<pattern>" or "rename:<name>=<new name>", as found in the
+// TELEMETRY_METRIC_RULES environment variable.
+func ParseMetricRules(s string) ([]MetricRule, error) {
+	var rules []MetricRule
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		action, arg, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("telemetry: invalid metric rule %q", entry)
+		}
+		switch action {
+		case "drop":
+			rules = append(rules, MetricRule{Match: arg, Drop: true})
+		case "rename":
+			from, to, ok := strings.Cut(arg, "=")
+			if !ok || from == "" || to == "" {
+				return nil, fmt.Errorf("telemetry: invalid metric rename rule %q", entry)
+			}
+			rules = append(rules, MetricRule{Match: from, Rename: to})
+		default:
+			return nil, fmt.Errorf("telemetry: unknown metric rule action %q", action)
+		}
+	}
+	return rules, nil
+}
+
+// newMetricViews converts rules into views for the meter provider.
+func newMetricViews(rules []MetricRule) ([]metric.View, error) {
+	views := make([]metric.View, 0, len(rules))
+	for _, r := range rules {
+		if r.Match == "" {
+			return nil, errors.New("telemetry: metric rule without a match")
+		}
+		var stream metric.Stream
+		switch {
+		case r.Drop:
+			stream.Aggregation = metric.AggregationDrop{}
+		case r.Rename != "":
+			if strings.ContainsAny(r.Match, "*?") {
+				return nil, fmt.Errorf("telemetry: cannot rename wildcard metric rule %q", r.Match)
+			}
+			stream.Name = r.Rename
+		default:
+			continue
+		}
+		views = append(views, metric.NewView(metric.Instrument{Name: r.Match}, stream))
+	}
+	return views, nil
+}
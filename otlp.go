@@ -0,0 +1,439 @@
+package telemetry
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// otlpTraces converts spans to the OTLP protobuf messages, grouped by
+// resource and instrumentation scope, for the exporters that don't go
+// through an OTLP client.
+func otlpTraces(spans []trace.ReadOnlySpan) *tracepb.TracesData {
+	type scopeKey struct {
+		res   attribute.Distinct
+		scope instrumentation.Scope
+	}
+	var (
+		data      tracepb.TracesData
+		resources = make(map[attribute.Distinct]*tracepb.ResourceSpans)
+		scopes    = make(map[scopeKey]*tracepb.ScopeSpans)
+	)
+	for _, s := range spans {
+		res := s.Resource()
+		rs, ok := resources[res.Equivalent()]
+		if !ok {
+			rs = &tracepb.ResourceSpans{Resource: otlpResource(res), SchemaUrl: res.SchemaURL()}
+			resources[res.Equivalent()] = rs
+			data.ResourceSpans = append(data.ResourceSpans, rs)
+		}
+		key := scopeKey{res.Equivalent(), s.InstrumentationScope()}
+		ss, ok := scopes[key]
+		if !ok {
+			ss = &tracepb.ScopeSpans{Scope: otlpScope(key.scope), SchemaUrl: key.scope.SchemaURL}
+			scopes[key] = ss
+			rs.ScopeSpans = append(rs.ScopeSpans, ss)
+		}
+		ss.Spans = append(ss.Spans, otlpSpan(s))
+	}
+	return &data
+}
+
+func otlpSpan(s trace.ReadOnlySpan) *tracepb.Span {
+	sc := s.SpanContext()
+	traceID, spanID := sc.TraceID(), sc.SpanID()
+	span := &tracepb.Span{
+		TraceId:                traceID[:],
+		SpanId:                 spanID[:],
+		TraceState:             sc.TraceState().String(),
+		Flags:                  uint32(sc.TraceFlags()),
+		Name:                   s.Name(),
+		Kind:                   tracepb.Span_SpanKind(s.SpanKind()),
+		StartTimeUnixNano:      otlpTime(s.StartTime()),
+		EndTimeUnixNano:        otlpTime(s.EndTime()),
+		Attributes:             otlpAttributes(s.Attributes()),
+		DroppedAttributesCount: uint32(s.DroppedAttributes()),
+		DroppedEventsCount:     uint32(s.DroppedEvents()),
+		DroppedLinksCount:      uint32(s.DroppedLinks()),
+		Status:                 &tracepb.Status{Message: s.Status().Description},
+	}
+	if parent := s.Parent().SpanID(); parent.IsValid() {
+		span.ParentSpanId = parent[:]
+	}
+	switch s.Status().Code {
+	case codes.Ok:
+		span.Status.Code = tracepb.Status_STATUS_CODE_OK
+	case codes.Error:
+		span.Status.Code = tracepb.Status_STATUS_CODE_ERROR
+	}
+	for _, ev := range s.Events() {
+		span.Events = append(span.Events, &tracepb.Span_Event{
+			TimeUnixNano:           otlpTime(ev.Time),
+			Name:                   ev.Name,
+			Attributes:             otlpAttributes(ev.Attributes),
+			DroppedAttributesCount: uint32(ev.DroppedAttributeCount),
+		})
+	}
+	for _, l := range s.Links() {
+		traceID, spanID := l.SpanContext.TraceID(), l.SpanContext.SpanID()
+		span.Links = append(span.Links, &tracepb.Span_Link{
+			TraceId:                traceID[:],
+			SpanId:                 spanID[:],
+			TraceState:             l.SpanContext.TraceState().String(),
+			Flags:                  uint32(l.SpanContext.TraceFlags()),
+			Attributes:             otlpAttributes(l.Attributes),
+			DroppedAttributesCount: uint32(l.DroppedAttributeCount),
+		})
+	}
+	return span
+}
+
+// otlpLogs converts records to the OTLP protobuf messages, grouped by
+// resource and instrumentation scope.
+func otlpLogs(records []sdklog.Record) *logspb.LogsData {
+	type scopeKey struct {
+		res   attribute.Distinct
+		scope instrumentation.Scope
+	}
+	var (
+		data      logspb.LogsData
+		resources = make(map[attribute.Distinct]*logspb.ResourceLogs)
+		scopes    = make(map[scopeKey]*logspb.ScopeLogs)
+	)
+	for i := range records {
+		r := &records[i]
+		res := r.Resource()
+		rl, ok := resources[res.Equivalent()]
+		if !ok {
+			rl = &logspb.ResourceLogs{Resource: otlpResource(&res), SchemaUrl: res.SchemaURL()}
+			resources[res.Equivalent()] = rl
+			data.ResourceLogs = append(data.ResourceLogs, rl)
+		}
+		key := scopeKey{res.Equivalent(), r.InstrumentationScope()}
+		sl, ok := scopes[key]
+		if !ok {
+			sl = &logspb.ScopeLogs{Scope: otlpScope(key.scope), SchemaUrl: key.scope.SchemaURL}
+			scopes[key] = sl
+			rl.ScopeLogs = append(rl.ScopeLogs, sl)
+		}
+		sl.LogRecords = append(sl.LogRecords, otlpLogRecord(r))
+	}
+	return &data
+}
+
+func otlpLogRecord(r *sdklog.Record) *logspb.LogRecord {
+	record := &logspb.LogRecord{
+		TimeUnixNano:           otlpTime(r.Timestamp()),
+		ObservedTimeUnixNano:   otlpTime(r.ObservedTimestamp()),
+		SeverityNumber:         logspb.SeverityNumber(r.Severity()),
+		SeverityText:           r.SeverityText(),
+		Body:                   otlpLogValue(r.Body()),
+		DroppedAttributesCount: uint32(r.DroppedAttributes()),
+		Flags:                  uint32(r.TraceFlags()),
+	}
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		record.Attributes = append(record.Attributes, &commonpb.KeyValue{Key: kv.Key, Value: otlpLogValue(kv.Value)})
+		return true
+	})
+	if traceID := r.TraceID(); traceID.IsValid() {
+		record.TraceId = traceID[:]
+	}
+	if spanID := r.SpanID(); spanID.IsValid() {
+		record.SpanId = spanID[:]
+	}
+	return record
+}
+
+func otlpLogValue(v log.Value) *commonpb.AnyValue {
+	switch v.Kind() {
+	case log.KindBool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v.AsBool()}}
+	case log.KindInt64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v.AsInt64()}}
+	case log.KindFloat64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v.AsFloat64()}}
+	case log.KindString:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.AsString()}}
+	case log.KindBytes:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BytesValue{BytesValue: v.AsBytes()}}
+	case log.KindSlice:
+		array := &commonpb.ArrayValue{}
+		for _, item := range v.AsSlice() {
+			array.Values = append(array.Values, otlpLogValue(item))
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: array}}
+	case log.KindMap:
+		kvs := &commonpb.KeyValueList{}
+		for _, kv := range v.AsMap() {
+			kvs.Values = append(kvs.Values, &commonpb.KeyValue{Key: kv.Key, Value: otlpLogValue(kv.Value)})
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: kvs}}
+	}
+	return &commonpb.AnyValue{}
+}
+
+// otlpMetrics converts rm to the OTLP protobuf message.
+func otlpMetrics(rm *metricdata.ResourceMetrics) *metricspb.ResourceMetrics {
+	res := rm.Resource
+	if res == nil {
+		res = resource.Empty()
+	}
+	data := &metricspb.ResourceMetrics{Resource: otlpResource(res), SchemaUrl: res.SchemaURL()}
+	for _, sm := range rm.ScopeMetrics {
+		scope := &metricspb.ScopeMetrics{Scope: otlpScope(sm.Scope), SchemaUrl: sm.Scope.SchemaURL}
+		for _, m := range sm.Metrics {
+			if metric := otlpMetric(m); metric != nil {
+				scope.Metrics = append(scope.Metrics, metric)
+			}
+		}
+		data.ScopeMetrics = append(data.ScopeMetrics, scope)
+	}
+	return data
+}
+
+// otlpMetric converts m, returning nil for an unknown aggregation.
+func otlpMetric(m metricdata.Metrics) *metricspb.Metric {
+	metric := &metricspb.Metric{Name: m.Name, Description: m.Description, Unit: m.Unit}
+	switch data := m.Data.(type) {
+	case metricdata.Gauge[int64]:
+		metric.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: otlpNumberPoints(data.DataPoints)}}
+	case metricdata.Gauge[float64]:
+		metric.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: otlpNumberPoints(data.DataPoints)}}
+	case metricdata.Sum[int64]:
+		metric.Data = &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+			DataPoints:             otlpNumberPoints(data.DataPoints),
+			AggregationTemporality: otlpTemporality(data.Temporality),
+			IsMonotonic:            data.IsMonotonic,
+		}}
+	case metricdata.Sum[float64]:
+		metric.Data = &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+			DataPoints:             otlpNumberPoints(data.DataPoints),
+			AggregationTemporality: otlpTemporality(data.Temporality),
+			IsMonotonic:            data.IsMonotonic,
+		}}
+	case metricdata.Histogram[int64]:
+		metric.Data = &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+			DataPoints:             otlpHistogramPoints(data.DataPoints),
+			AggregationTemporality: otlpTemporality(data.Temporality),
+		}}
+	case metricdata.Histogram[float64]:
+		metric.Data = &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+			DataPoints:             otlpHistogramPoints(data.DataPoints),
+			AggregationTemporality: otlpTemporality(data.Temporality),
+		}}
+	case metricdata.ExponentialHistogram[int64]:
+		metric.Data = &metricspb.Metric_ExponentialHistogram{ExponentialHistogram: &metricspb.ExponentialHistogram{
+			DataPoints:             otlpExponentialPoints(data.DataPoints),
+			AggregationTemporality: otlpTemporality(data.Temporality),
+		}}
+	case metricdata.ExponentialHistogram[float64]:
+		metric.Data = &metricspb.Metric_ExponentialHistogram{ExponentialHistogram: &metricspb.ExponentialHistogram{
+			DataPoints:             otlpExponentialPoints(data.DataPoints),
+			AggregationTemporality: otlpTemporality(data.Temporality),
+		}}
+	case metricdata.Summary:
+		summary := &metricspb.Summary{}
+		for _, dp := range data.DataPoints {
+			point := &metricspb.SummaryDataPoint{
+				Attributes:        otlpAttributes(dp.Attributes.ToSlice()),
+				StartTimeUnixNano: otlpTime(dp.StartTime),
+				TimeUnixNano:      otlpTime(dp.Time),
+				Count:             dp.Count,
+				Sum:               dp.Sum,
+			}
+			for _, q := range dp.QuantileValues {
+				point.QuantileValues = append(point.QuantileValues, &metricspb.SummaryDataPoint_ValueAtQuantile{Quantile: q.Quantile, Value: q.Value})
+			}
+			summary.DataPoints = append(summary.DataPoints, point)
+		}
+		metric.Data = &metricspb.Metric_Summary{Summary: summary}
+	default:
+		return nil
+	}
+	return metric
+}
+
+func otlpTemporality(t metricdata.Temporality) metricspb.AggregationTemporality {
+	switch t {
+	case metricdata.DeltaTemporality:
+		return metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA
+	case metricdata.CumulativeTemporality:
+		return metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+	}
+	return metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED
+}
+
+func otlpNumberPoints[N int64 | float64](dps []metricdata.DataPoint[N]) []*metricspb.NumberDataPoint {
+	points := make([]*metricspb.NumberDataPoint, 0, len(dps))
+	for _, dp := range dps {
+		point := &metricspb.NumberDataPoint{
+			Attributes:        otlpAttributes(dp.Attributes.ToSlice()),
+			StartTimeUnixNano: otlpTime(dp.StartTime),
+			TimeUnixNano:      otlpTime(dp.Time),
+			Exemplars:         otlpExemplars(dp.Exemplars),
+		}
+		switch v := any(dp.Value).(type) {
+		case int64:
+			point.Value = &metricspb.NumberDataPoint_AsInt{AsInt: v}
+		case float64:
+			point.Value = &metricspb.NumberDataPoint_AsDouble{AsDouble: v}
+		}
+		points = append(points, point)
+	}
+	return points
+}
+
+func otlpHistogramPoints[N int64 | float64](dps []metricdata.HistogramDataPoint[N]) []*metricspb.HistogramDataPoint {
+	points := make([]*metricspb.HistogramDataPoint, 0, len(dps))
+	for _, dp := range dps {
+		sum := float64(dp.Sum)
+		points = append(points, &metricspb.HistogramDataPoint{
+			Attributes:        otlpAttributes(dp.Attributes.ToSlice()),
+			StartTimeUnixNano: otlpTime(dp.StartTime),
+			TimeUnixNano:      otlpTime(dp.Time),
+			Count:             dp.Count,
+			Sum:               &sum,
+			BucketCounts:      dp.BucketCounts,
+			ExplicitBounds:    dp.Bounds,
+			Exemplars:         otlpExemplars(dp.Exemplars),
+			Min:               otlpExtrema(dp.Min),
+			Max:               otlpExtrema(dp.Max),
+		})
+	}
+	return points
+}
+
+func otlpExponentialPoints[N int64 | float64](dps []metricdata.ExponentialHistogramDataPoint[N]) []*metricspb.ExponentialHistogramDataPoint {
+	points := make([]*metricspb.ExponentialHistogramDataPoint, 0, len(dps))
+	for _, dp := range dps {
+		sum := float64(dp.Sum)
+		points = append(points, &metricspb.ExponentialHistogramDataPoint{
+			Attributes:        otlpAttributes(dp.Attributes.ToSlice()),
+			StartTimeUnixNano: otlpTime(dp.StartTime),
+			TimeUnixNano:      otlpTime(dp.Time),
+			Count:             dp.Count,
+			Sum:               &sum,
+			Scale:             dp.Scale,
+			ZeroCount:         dp.ZeroCount,
+			Positive: &metricspb.ExponentialHistogramDataPoint_Buckets{
+				Offset:       dp.PositiveBucket.Offset,
+				BucketCounts: dp.PositiveBucket.Counts,
+			},
+			Negative: &metricspb.ExponentialHistogramDataPoint_Buckets{
+				Offset:       dp.NegativeBucket.Offset,
+				BucketCounts: dp.NegativeBucket.Counts,
+			},
+			Exemplars:     otlpExemplars(dp.Exemplars),
+			Min:           otlpExtrema(dp.Min),
+			Max:           otlpExtrema(dp.Max),
+			ZeroThreshold: dp.ZeroThreshold,
+		})
+	}
+	return points
+}
+
+// otlpExtrema returns the value of e, nil if it is not set.
+func otlpExtrema[N int64 | float64](e metricdata.Extrema[N]) *float64 {
+	v, ok := e.Value()
+	if !ok {
+		return nil
+	}
+	f := float64(v)
+	return &f
+}
+
+func otlpExemplars[N int64 | float64](exemplars []metricdata.Exemplar[N]) []*metricspb.Exemplar {
+	if len(exemplars) == 0 {
+		return nil
+	}
+	out := make([]*metricspb.Exemplar, 0, len(exemplars))
+	for _, e := range exemplars {
+		exemplar := &metricspb.Exemplar{
+			FilteredAttributes: otlpAttributes(e.FilteredAttributes),
+			TimeUnixNano:       otlpTime(e.Time),
+			SpanId:             e.SpanID,
+			TraceId:            e.TraceID,
+		}
+		switch v := any(e.Value).(type) {
+		case int64:
+			exemplar.Value = &metricspb.Exemplar_AsInt{AsInt: v}
+		case float64:
+			exemplar.Value = &metricspb.Exemplar_AsDouble{AsDouble: v}
+		}
+		out = append(out, exemplar)
+	}
+	return out
+}
+
+// otlpTime returns t as nanoseconds since the epoch, 0 for the zero time.
+func otlpTime(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(max(t.UnixNano(), 0))
+}
+
+func otlpResource(res *resource.Resource) *resourcepb.Resource {
+	return &resourcepb.Resource{Attributes: otlpAttributes(res.Attributes())}
+}
+
+func otlpScope(scope instrumentation.Scope) *commonpb.InstrumentationScope {
+	return &commonpb.InstrumentationScope{
+		Name:       scope.Name,
+		Version:    scope.Version,
+		Attributes: otlpAttributes(scope.Attributes.ToSlice()),
+	}
+}
+
+func otlpAttributes(attrs []attribute.KeyValue) []*commonpb.KeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	kvs := make([]*commonpb.KeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		kvs = append(kvs, &commonpb.KeyValue{Key: string(kv.Key), Value: otlpValue(kv.Value)})
+	}
+	return kvs
+}
+
+func otlpValue(v attribute.Value) *commonpb.AnyValue {
+	switch v.Type() {
+	case attribute.BOOL:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v.AsBool()}}
+	case attribute.INT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v.AsInt64()}}
+	case attribute.FLOAT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v.AsFloat64()}}
+	case attribute.STRING:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.AsString()}}
+	case attribute.BOOLSLICE:
+		return otlpArray(v.AsBoolSlice(), attribute.BoolValue)
+	case attribute.INT64SLICE:
+		return otlpArray(v.AsInt64Slice(), attribute.Int64Value)
+	case attribute.FLOAT64SLICE:
+		return otlpArray(v.AsFloat64Slice(), attribute.Float64Value)
+	case attribute.STRINGSLICE:
+		return otlpArray(v.AsStringSlice(), attribute.StringValue)
+	}
+	return &commonpb.AnyValue{}
+}
+
+func otlpArray[T any](values []T, value func(T) attribute.Value) *commonpb.AnyValue {
+	array := &commonpb.ArrayValue{Values: make([]*commonpb.AnyValue, 0, len(values))}
+	for _, v := range values {
+		array.Values = append(array.Values, otlpValue(value(v)))
+	}
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: array}}
+}
@@ -0,0 +1,513 @@
+package telemetry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// parquetType is the type of a parquetTable column.
+type parquetType int
+
+const (
+	parquetInt32 parquetType = iota
+	parquetInt64
+	parquetDouble
+	parquetString
+)
+
+// physical returns the Parquet physical type of t.
+func (t parquetType) physical() int32 {
+	switch t {
+	case parquetInt32:
+		return 1
+	case parquetInt64:
+		return 2
+	case parquetDouble:
+		return 5
+	}
+	return 6 // BYTE_ARRAY
+}
+
+// The Parquet encodings, converted type and codec used by parquetTable.
+const (
+	parquetPlain = 0
+	parquetRLE   = 3
+	parquetUTF8  = 0
+	parquetGzip  = 2
+)
+
+// parquetColumn declares a column of a parquetTable.
+type parquetColumn struct {
+	name string
+	typ  parquetType
+}
+
+// parquetTable buffers rows of required columns and writes them as a
+// Parquet file of a single row group, one gzipped PLAIN page per column.
+// It covers what the archive exporters need rather than the format.
+type parquetTable struct {
+	schema []parquetColumn
+	values []bytes.Buffer
+	rows   int
+}
+
+func newParquetTable(schema ...parquetColumn) *parquetTable {
+	return &parquetTable{schema: schema, values: make([]bytes.Buffer, len(schema))}
+}
+
+// append adds a row, with a value per column: int32, int64, float64 or
+// string, according to the column types.
+func (t *parquetTable) append(values ...any) {
+	for i, v := range values {
+		col := &t.values[i]
+		switch v := v.(type) {
+		case int32:
+			col.Write(binary.LittleEndian.AppendUint32(nil, uint32(v)))
+		case int64:
+			col.Write(binary.LittleEndian.AppendUint64(nil, uint64(v)))
+		case float64:
+			col.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(v)))
+		case string:
+			col.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(v))))
+			col.WriteString(v)
+		}
+	}
+	t.rows++
+}
+
+// encode returns the Parquet file of the rows.
+func (t *parquetTable) encode() ([]byte, error) {
+	var (
+		file   bytes.Buffer
+		chunks []thriftStruct
+		total  int64
+	)
+	file.WriteString("PAR1")
+	schema := []thriftStruct{{{4, "schema"}, {5, int32(len(t.schema))}}}
+	for i, col := range t.schema {
+		element := thriftStruct{{1, col.typ.physical()}, {3, int32(0)}, {4, col.name}}
+		if col.typ == parquetString {
+			element = append(element, thriftField{6, int32(parquetUTF8)})
+		}
+		schema = append(schema, element)
+
+		values := t.values[i].Bytes()
+		var page bytes.Buffer
+		zw := gzip.NewWriter(&page)
+		if _, err := zw.Write(values); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		header := thriftStruct{
+			{1, int32(0)}, // DATA_PAGE
+			{2, int32(len(values))},
+			{3, int32(page.Len())},
+			{5, thriftStruct{{1, int32(t.rows)}, {2, int32(parquetPlain)}, {3, int32(parquetRLE)}, {4, int32(parquetRLE)}}},
+		}.encode()
+
+		offset := int64(file.Len())
+		file.Write(header)
+		file.Write(page.Bytes())
+		uncompressed := int64(len(header) + len(values))
+		total += uncompressed
+		chunks = append(chunks, thriftStruct{
+			{2, offset},
+			{3, thriftStruct{
+				{1, col.typ.physical()},
+				{2, []int32{parquetPlain, parquetRLE}},
+				{3, []string{col.name}},
+				{4, int32(parquetGzip)},
+				{5, int64(t.rows)},
+				{6, uncompressed},
+				{7, int64(len(header) + page.Len())},
+				{9, offset},
+			}},
+		})
+	}
+
+	footer := thriftStruct{
+		{1, int32(1)},
+		{2, schema},
+		{3, int64(t.rows)},
+		{4, []thriftStruct{{{1, chunks}, {2, total}, {3, int64(t.rows)}}}},
+		{6, "telemetry"},
+	}.encode()
+	file.Write(footer)
+	file.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	file.WriteString("PAR1")
+	return file.Bytes(), nil
+}
+
+// thriftStruct is a struct encoded with the Thrift compact protocol, as the
+// Parquet metadata is. Fields must be in increasing id order.
+type thriftStruct []thriftField
+
+type thriftField struct {
+	id    int16
+	value any
+}
+
+// The Thrift compact protocol types.
+const (
+	thriftTypeI32    = 5
+	thriftTypeI64    = 6
+	thriftTypeBinary = 8
+	thriftTypeList   = 9
+	thriftTypeStruct = 12
+)
+
+func (s thriftStruct) encode() []byte {
+	return s.append(nil)
+}
+
+func (s thriftStruct) append(b []byte) []byte {
+	var last int16
+	for _, f := range s {
+		typ := thriftType(f.value)
+		if delta := f.id - last; delta > 0 && delta <= 15 {
+			b = append(b, byte(delta)<<4|typ)
+		} else {
+			b = append(b, typ)
+			b = binary.AppendVarint(b, int64(f.id))
+		}
+		last = f.id
+		b = thriftAppend(b, f.value)
+	}
+	return append(b, 0)
+}
+
+func thriftType(v any) byte {
+	switch v.(type) {
+	case int32:
+		return thriftTypeI32
+	case int64:
+		return thriftTypeI64
+	case string:
+		return thriftTypeBinary
+	case thriftStruct:
+		return thriftTypeStruct
+	case []int32, []string, []thriftStruct:
+		return thriftTypeList
+	}
+	panic(fmt.Sprintf("telemetry: no thrift type for %T", v))
+}
+
+func thriftAppend(b []byte, v any) []byte {
+	switch v := v.(type) {
+	case int32:
+		return binary.AppendVarint(b, int64(v))
+	case int64:
+		return binary.AppendVarint(b, v)
+	case string:
+		b = binary.AppendUvarint(b, uint64(len(v)))
+		return append(b, v...)
+	case thriftStruct:
+		return v.append(b)
+	case []int32:
+		b = thriftListHeader(b, thriftTypeI32, len(v))
+		for _, e := range v {
+			b = thriftAppend(b, e)
+		}
+	case []string:
+		b = thriftListHeader(b, thriftTypeBinary, len(v))
+		for _, e := range v {
+			b = thriftAppend(b, e)
+		}
+	case []thriftStruct:
+		b = thriftListHeader(b, thriftTypeStruct, len(v))
+		for _, e := range v {
+			b = e.append(b)
+		}
+	}
+	return b
+}
+
+func thriftListHeader(b []byte, typ byte, n int) []byte {
+	if n < 15 {
+		return append(b, byte(n)<<4|typ)
+	}
+	b = append(b, 0xf0|typ)
+	return binary.AppendUvarint(b, uint64(n))
+}
+
+// parquetSpans returns the spans of rs as a table with a row per span.
+func parquetSpans(rs []*tracepb.ResourceSpans) *parquetTable {
+	t := newParquetTable(
+		parquetColumn{"trace_id", parquetString},
+		parquetColumn{"span_id", parquetString},
+		parquetColumn{"parent_span_id", parquetString},
+		parquetColumn{"trace_state", parquetString},
+		parquetColumn{"name", parquetString},
+		parquetColumn{"kind", parquetInt32},
+		parquetColumn{"start_time_unix_nano", parquetInt64},
+		parquetColumn{"end_time_unix_nano", parquetInt64},
+		parquetColumn{"status_code", parquetInt32},
+		parquetColumn{"status_message", parquetString},
+		parquetColumn{"service_name", parquetString},
+		parquetColumn{"scope_name", parquetString},
+		parquetColumn{"scope_version", parquetString},
+		parquetColumn{"attributes", parquetString},
+		parquetColumn{"resource_attributes", parquetString},
+		parquetColumn{"events", parquetString},
+		parquetColumn{"links", parquetString},
+	)
+	for _, r := range rs {
+		service, resAttrs := parquetResource(r.Resource)
+		for _, ss := range r.ScopeSpans {
+			for _, s := range ss.Spans {
+				events := make([]map[string]any, 0, len(s.Events))
+				for _, e := range s.Events {
+					events = append(events, map[string]any{
+						"time_unix_nano": e.TimeUnixNano,
+						"name":           e.Name,
+						"attributes":     parquetAttributes(e.Attributes),
+					})
+				}
+				links := make([]map[string]any, 0, len(s.Links))
+				for _, l := range s.Links {
+					links = append(links, map[string]any{
+						"trace_id":    hex.EncodeToString(l.TraceId),
+						"span_id":     hex.EncodeToString(l.SpanId),
+						"trace_state": l.TraceState,
+						"attributes":  parquetAttributes(l.Attributes),
+					})
+				}
+				t.append(
+					hex.EncodeToString(s.TraceId),
+					hex.EncodeToString(s.SpanId),
+					hex.EncodeToString(s.ParentSpanId),
+					s.TraceState,
+					s.Name,
+					int32(s.Kind),
+					int64(s.StartTimeUnixNano),
+					int64(s.EndTimeUnixNano),
+					int32(s.GetStatus().GetCode()),
+					s.GetStatus().GetMessage(),
+					service,
+					ss.GetScope().GetName(),
+					ss.GetScope().GetVersion(),
+					parquetJSON(parquetAttributes(s.Attributes)),
+					resAttrs,
+					parquetJSON(events),
+					parquetJSON(links),
+				)
+			}
+		}
+	}
+	return t
+}
+
+// parquetLogs returns the records of rl as a table with a row per record.
+func parquetLogs(rl []*logspb.ResourceLogs) *parquetTable {
+	t := newParquetTable(
+		parquetColumn{"time_unix_nano", parquetInt64},
+		parquetColumn{"observed_time_unix_nano", parquetInt64},
+		parquetColumn{"severity_number", parquetInt32},
+		parquetColumn{"severity_text", parquetString},
+		parquetColumn{"body", parquetString},
+		parquetColumn{"trace_id", parquetString},
+		parquetColumn{"span_id", parquetString},
+		parquetColumn{"service_name", parquetString},
+		parquetColumn{"scope_name", parquetString},
+		parquetColumn{"scope_version", parquetString},
+		parquetColumn{"attributes", parquetString},
+		parquetColumn{"resource_attributes", parquetString},
+	)
+	for _, r := range rl {
+		service, resAttrs := parquetResource(r.Resource)
+		for _, sl := range r.ScopeLogs {
+			for _, l := range sl.LogRecords {
+				body := ""
+				if l.Body != nil {
+					if s, ok := l.Body.Value.(*commonpb.AnyValue_StringValue); ok {
+						body = s.StringValue
+					} else {
+						body = parquetJSON(parquetValue(l.Body))
+					}
+				}
+				t.append(
+					int64(l.TimeUnixNano),
+					int64(l.ObservedTimeUnixNano),
+					int32(l.SeverityNumber),
+					l.SeverityText,
+					body,
+					hex.EncodeToString(l.TraceId),
+					hex.EncodeToString(l.SpanId),
+					service,
+					sl.GetScope().GetName(),
+					sl.GetScope().GetVersion(),
+					parquetJSON(parquetAttributes(l.Attributes)),
+					resAttrs,
+				)
+			}
+		}
+	}
+	return t
+}
+
+// parquetMetrics returns the data points of rm as a table with a row per
+// data point. Value holds the value of gauges and sums and the sum of
+// histograms and summaries, whose buckets or quantiles are in the buckets
+// column; min and max are NaN when not recorded.
+func parquetMetrics(rm []*metricspb.ResourceMetrics) *parquetTable {
+	t := newParquetTable(
+		parquetColumn{"metric_name", parquetString},
+		parquetColumn{"metric_description", parquetString},
+		parquetColumn{"metric_unit", parquetString},
+		parquetColumn{"type", parquetString},
+		parquetColumn{"aggregation_temporality", parquetInt32},
+		parquetColumn{"is_monotonic", parquetInt32},
+		parquetColumn{"start_time_unix_nano", parquetInt64},
+		parquetColumn{"time_unix_nano", parquetInt64},
+		parquetColumn{"value", parquetDouble},
+		parquetColumn{"count", parquetInt64},
+		parquetColumn{"min", parquetDouble},
+		parquetColumn{"max", parquetDouble},
+		parquetColumn{"buckets", parquetString},
+		parquetColumn{"service_name", parquetString},
+		parquetColumn{"scope_name", parquetString},
+		parquetColumn{"scope_version", parquetString},
+		parquetColumn{"attributes", parquetString},
+		parquetColumn{"resource_attributes", parquetString},
+	)
+	extrema := func(v *float64) float64 {
+		if v == nil {
+			return math.NaN()
+		}
+		return *v
+	}
+	for _, r := range rm {
+		service, resAttrs := parquetResource(r.Resource)
+		for _, sm := range r.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				row := func(typ string, temporality metricspb.AggregationTemporality, monotonic bool,
+					attrs []*commonpb.KeyValue, start, end uint64, value float64, count uint64, lo, hi float64, buckets any) {
+					var isMonotonic int32
+					if monotonic {
+						isMonotonic = 1
+					}
+					bucketsJSON := ""
+					if buckets != nil {
+						bucketsJSON = parquetJSON(buckets)
+					}
+					t.append(m.Name, m.Description, m.Unit, typ, int32(temporality), isMonotonic,
+						int64(start), int64(end), value, int64(count), lo, hi, bucketsJSON,
+						service, sm.GetScope().GetName(), sm.GetScope().GetVersion(),
+						parquetJSON(parquetAttributes(attrs)), resAttrs)
+				}
+				number := func(dp *metricspb.NumberDataPoint) float64 {
+					if v, ok := dp.Value.(*metricspb.NumberDataPoint_AsInt); ok {
+						return float64(v.AsInt)
+					}
+					return dp.GetAsDouble()
+				}
+
+				switch data := m.Data.(type) {
+				case *metricspb.Metric_Gauge:
+					for _, dp := range data.Gauge.DataPoints {
+						row("gauge", 0, false, dp.Attributes, dp.StartTimeUnixNano, dp.TimeUnixNano,
+							number(dp), 0, math.NaN(), math.NaN(), nil)
+					}
+				case *metricspb.Metric_Sum:
+					for _, dp := range data.Sum.DataPoints {
+						row("sum", data.Sum.AggregationTemporality, data.Sum.IsMonotonic, dp.Attributes,
+							dp.StartTimeUnixNano, dp.TimeUnixNano, number(dp), 0, math.NaN(), math.NaN(), nil)
+					}
+				case *metricspb.Metric_Histogram:
+					for _, dp := range data.Histogram.DataPoints {
+						row("histogram", data.Histogram.AggregationTemporality, false, dp.Attributes,
+							dp.StartTimeUnixNano, dp.TimeUnixNano, dp.GetSum(), dp.Count, extrema(dp.Min), extrema(dp.Max),
+							map[string]any{"bounds": dp.ExplicitBounds, "counts": dp.BucketCounts})
+					}
+				case *metricspb.Metric_ExponentialHistogram:
+					for _, dp := range data.ExponentialHistogram.DataPoints {
+						row("exponential_histogram", data.ExponentialHistogram.AggregationTemporality, false, dp.Attributes,
+							dp.StartTimeUnixNano, dp.TimeUnixNano, dp.GetSum(), dp.Count, extrema(dp.Min), extrema(dp.Max),
+							map[string]any{
+								"scale":           dp.Scale,
+								"zero_count":      dp.ZeroCount,
+								"positive_offset": dp.GetPositive().GetOffset(),
+								"positive_counts": dp.GetPositive().GetBucketCounts(),
+								"negative_offset": dp.GetNegative().GetOffset(),
+								"negative_counts": dp.GetNegative().GetBucketCounts(),
+							})
+					}
+				case *metricspb.Metric_Summary:
+					for _, dp := range data.Summary.DataPoints {
+						quantiles := make(map[string]float64, len(dp.QuantileValues))
+						for _, q := range dp.QuantileValues {
+							quantiles[fmt.Sprint(q.Quantile)] = q.Value
+						}
+						row("summary", 0, false, dp.Attributes, dp.StartTimeUnixNano, dp.TimeUnixNano,
+							dp.Sum, dp.Count, math.NaN(), math.NaN(), quantiles)
+					}
+				}
+			}
+		}
+	}
+	return t
+}
+
+// parquetResource returns the service name of res and its attributes as
+// JSON.
+func parquetResource(res *resourcepb.Resource) (service, attrs string) {
+	m := parquetAttributes(res.GetAttributes())
+	service, _ = m["service.name"].(string)
+	return service, parquetJSON(m)
+}
+
+// parquetAttributes returns kvs as a map, to be encoded as JSON.
+func parquetAttributes(kvs []*commonpb.KeyValue) map[string]any {
+	m := make(map[string]any, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = parquetValue(kv.Value)
+	}
+	return m
+}
+
+func parquetValue(v *commonpb.AnyValue) any {
+	switch v := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return v.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return v.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return v.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return v.BytesValue
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]any, 0, len(v.ArrayValue.GetValues()))
+		for _, e := range v.ArrayValue.GetValues() {
+			values = append(values, parquetValue(e))
+		}
+		return values
+	case *commonpb.AnyValue_KvlistValue:
+		return parquetAttributes(v.KvlistValue.GetValues())
+	}
+	return nil
+}
+
+// parquetJSON returns v as JSON; NaN and infinite numbers, which JSON can't
+// hold, give an empty string.
+func parquetJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
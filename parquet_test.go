@@ -0,0 +1,206 @@
+package telemetry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// thriftReader decodes the Thrift compact protocol, into maps of field ids
+// for structs.
+type thriftReader struct {
+	b []byte
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b)
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case thriftTypeI32, thriftTypeI64:
+		v, n := binary.Varint(r.b)
+		r.b = r.b[n:]
+		return v
+	case thriftTypeBinary:
+		n := r.uvarint()
+		v := string(r.b[:n])
+		r.b = r.b[n:]
+		return v
+	case thriftTypeList:
+		header := r.b[0]
+		r.b = r.b[1:]
+		n := uint64(header >> 4)
+		if n == 15 {
+			n = r.uvarint()
+		}
+		list := make([]any, n)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+		return list
+	case thriftTypeStruct:
+		return r.readStruct()
+	}
+	panic("unexpected thrift type")
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	s := make(map[int16]any)
+	var last int16
+	for {
+		header := r.b[0]
+		r.b = r.b[1:]
+		if header == 0 {
+			return s
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			v, n := binary.Varint(r.b)
+			r.b = r.b[n:]
+			id = int16(v)
+		}
+		s[id] = r.value(header & 0x0f)
+		last = id
+	}
+}
+
+// readParquet returns the values of the columns of a file written by
+// parquetTable, by column name.
+func readParquet(t *testing.T, file []byte) (rows int64, columns map[string][]any) {
+	t.Helper()
+	if !bytes.HasPrefix(file, []byte("PAR1")) || !bytes.HasSuffix(file, []byte("PAR1")) {
+		t.Fatal("missing magic")
+	}
+	size := binary.LittleEndian.Uint32(file[len(file)-8:])
+	footer := (&thriftReader{file[len(file)-8-int(size) : len(file)-8]}).readStruct()
+
+	schema := footer[2].([]any)
+	columns = make(map[string][]any)
+	chunks := footer[4].([]any)[0].(map[int16]any)[1].([]any)
+	for i, chunk := range chunks {
+		meta := chunk.(map[int16]any)[3].(map[int16]any)
+		name := schema[i+1].(map[int16]any)[4].(string)
+		if got := meta[3].([]any)[0]; got != name {
+			t.Fatalf("column %d is %v in the row group and %v in the schema", i, got, name)
+		}
+
+		r := &thriftReader{file[meta[9].(int64):]}
+		header := r.readStruct()
+		zr, err := gzip.NewReader(bytes.NewReader(r.b[:header[3].(int64)]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(page)) != header[2].(int64) {
+			t.Fatalf("column %s: %d bytes, header says %d", name, len(page), header[2])
+		}
+		for range header[5].(map[int16]any)[1].(int64) {
+			switch meta[1].(int64) {
+			case 1:
+				columns[name] = append(columns[name], int32(binary.LittleEndian.Uint32(page)))
+				page = page[4:]
+			case 2:
+				columns[name] = append(columns[name], int64(binary.LittleEndian.Uint64(page)))
+				page = page[8:]
+			case 5:
+				columns[name] = append(columns[name], math.Float64frombits(binary.LittleEndian.Uint64(page)))
+				page = page[8:]
+			case 6:
+				n := binary.LittleEndian.Uint32(page)
+				columns[name] = append(columns[name], string(page[4:4+n]))
+				page = page[4+n:]
+			}
+		}
+	}
+	return footer[3].(int64), columns
+}
+
+func TestParquetSpans(t *testing.T) {
+	res := resource.NewSchemaless(attribute.String("service.name", "checkout"))
+	traceID := trace.TraceID{1}
+	spans := tracetest.SpanStubs{
+		{
+			Name:        "GET /orders",
+			SpanContext: trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{1}}),
+			SpanKind:    trace.SpanKindServer,
+			StartTime:   time.Unix(1, 0),
+			EndTime:     time.Unix(2, 0),
+			Attributes:  []attribute.KeyValue{attribute.Int("http.response.status_code", 200)},
+			Resource:    res,
+		},
+		{
+			Name:        "SELECT orders",
+			SpanContext: trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{2}}),
+			Parent:      trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{1}}),
+			Resource:    res,
+		},
+	}.Snapshots()
+
+	file, err := parquetSpans(otlpTraces(spans).ResourceSpans).encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, columns := readParquet(t, file)
+	if rows != 2 {
+		t.Fatalf("rows = %d, want 2", rows)
+	}
+	for _, tt := range []struct {
+		column string
+		want   []any
+	}{
+		{"name", []any{"GET /orders", "SELECT orders"}},
+		{"trace_id", []any{traceID.String(), traceID.String()}},
+		{"parent_span_id", []any{"", trace.SpanID{1}.String()}},
+		{"kind", []any{int32(trace.SpanKindServer), int32(trace.SpanKindUnspecified)}},
+		{"start_time_unix_nano", []any{int64(time.Second), int64(0)}},
+		{"service_name", []any{"checkout", "checkout"}},
+		{"attributes", []any{`{"http.response.status_code":200}`, `{}`}},
+		{"events", []any{`[]`, `[]`}},
+	} {
+		got := columns[tt.column]
+		if len(got) != len(tt.want) {
+			t.Errorf("%s = %v, want %v", tt.column, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s[%d] = %#v, want %#v", tt.column, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestArchiveParquet(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	exp := NewArchiveSpanExporter(ArchiveConfig{Store: store, Format: ArchiveParquet, Interval: time.Hour})
+	if err := exp.ExportSpans(ctx, tracetest.SpanStubs{{Name: "checkout"}}.Snapshots()); err != nil {
+		t.Fatal(err)
+	}
+	if err := exp.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.objects) != 1 || !strings.HasSuffix(store.objects[0], ".parquet") {
+		t.Fatalf("objects = %v", store.objects)
+	}
+	if rows, columns := readParquet(t, store.data[0]); rows != 1 || columns["name"][0] != "checkout" {
+		t.Errorf("rows = %d, names = %v", rows, columns["name"])
+	}
+}
@@ -0,0 +1,116 @@
+package telemetry
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// WithPeerServices maps the addresses client spans connect to onto logical
+// peer.service names, so backend service maps show dependencies instead of
+// IP addresses. Keys are "host:port" or "host" patterns where "*" matches
+// any sequence of characters, e.g. "db-*.internal:5432" => "orders-db".
+// More specific (longer) patterns win. Mappings can also be set with the
+// TELEMETRY_PEER_SERVICES environment variable as a comma separated list of
+// pattern=service pairs. The mapping only runs with VerbosityDetailed.
+func WithPeerServices(services map[string]string) Option {
+	return func(c *config) {
+		if c.peerServices == nil {
+			c.peerServices = make(map[string]string)
+		}
+		for pattern, service := range services {
+			c.peerServices[pattern] = service
+		}
+	}
+}
+
+// envPeerServices reads peer service mappings from the environment variable
+// key.
+func envPeerServices(key string) map[string]string {
+	var services map[string]string
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pattern, service, ok := strings.Cut(entry, "=")
+		if !ok {
+			otel.Handle(fmt.Errorf("telemetry: invalid peer service mapping %q", entry))
+			continue
+		}
+		if services == nil {
+			services = make(map[string]string)
+		}
+		services[pattern] = service
+	}
+	return services
+}
+
+type peerServiceRule struct {
+	pattern string
+	service string
+}
+
+// peerServiceMapper is a span processor setting peer.service on client spans
+// whose server address matches one of its rules.
+type peerServiceMapper struct {
+	rules []peerServiceRule
+}
+
+func newPeerServiceMapper(services map[string]string) peerServiceMapper {
+	var m peerServiceMapper
+	for pattern, service := range services {
+		m.rules = append(m.rules, peerServiceRule{pattern: pattern, service: service})
+	}
+	slices.SortFunc(m.rules, func(a, b peerServiceRule) int {
+		if c := cmp.Compare(len(b.pattern), len(a.pattern)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.pattern, b.pattern)
+	})
+	return m
+}
+
+func (m peerServiceMapper) OnStart(_ context.Context, s trace.ReadWriteSpan) {
+	if s.SpanKind() != oteltrace.SpanKindClient || !verbose(VerbosityDetailed) {
+		return
+	}
+
+	var host, port string
+	for _, kv := range s.Attributes() {
+		switch kv.Key {
+		case semconv.PeerServiceKey:
+			return
+		case semconv.ServerAddressKey, "net.peer.name":
+			host = kv.Value.Emit()
+		case semconv.ServerPortKey, "net.peer.port":
+			port = kv.Value.Emit()
+		}
+	}
+	if host == "" {
+		return
+	}
+	hostPort := host
+	if port != "" {
+		hostPort = host + ":" + port
+	}
+
+	for _, r := range m.rules {
+		if globMatch(r.pattern, hostPort) || globMatch(r.pattern, host) {
+			s.SetAttributes(semconv.PeerService(r.service))
+			return
+		}
+	}
+}
+
+func (peerServiceMapper) OnEnd(trace.ReadOnlySpan)         {}
+func (peerServiceMapper) Shutdown(context.Context) error   { return nil }
+func (peerServiceMapper) ForceFlush(context.Context) error { return nil }
@@ -0,0 +1,62 @@
+package telemetry
+
+import (
+	"context"
+	"runtime/pprof"
+	"sync/atomic"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// pprofLabels reports whether instrumented operations set pprof labels.
+var pprofLabels atomic.Bool
+
+// WithPprofLabels makes the operations instrumented by this package
+// (Middleware, RunJob and WorkerPool.Process) run with the pprof labels
+// trace_id and span_name, so CPU profiles can be sliced by endpoint and
+// correlated back to traces. Middleware only sets span_name when the route
+// is known before serving, as for an *http.ServeMux. It can also be enabled
+// with the TELEMETRY_PPROF_LABELS environment variable.
+func WithPprofLabels() Option {
+	return func(c *config) {
+		c.pprofLabels = true
+	}
+}
+
+// DoWithProfileLabels runs fn with the pprof labels trace_id and span_name
+// of the span in ctx, for operations instrumented outside this package.
+// Without a recording span in ctx, fn runs unlabeled.
+func DoWithProfileLabels(ctx context.Context, fn func(context.Context)) {
+	doWithProfileLabels(ctx, true, fn)
+}
+
+// doWithProfileLabels runs fn with the trace_id label of the span in ctx,
+// and its span_name label if named, which is false while the span still has
+// a temporary name.
+func doWithProfileLabels(ctx context.Context, named bool, fn func(context.Context)) {
+	span := trace.SpanFromContext(ctx)
+	sc := span.SpanContext()
+	if !sc.IsValid() {
+		fn(ctx)
+		return
+	}
+	labels := []string{"trace_id", sc.TraceID().String()}
+	if cs, ok := span.(clockSpan); ok {
+		span = cs.Span
+	}
+	if s, ok := span.(sdktrace.ReadOnlySpan); ok && named {
+		labels = append(labels, "span_name", s.Name())
+	}
+	pprof.Do(ctx, pprof.Labels(labels...), fn)
+}
+
+// profile runs fn with the pprof labels of the span in ctx if WithPprofLabels
+// is enabled, leaving out span_name unless named.
+func profile(ctx context.Context, named bool, fn func(context.Context)) {
+	if pprofLabels.Load() {
+		doWithProfileLabels(ctx, named, fn)
+		return
+	}
+	fn(ctx)
+}
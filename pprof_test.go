@@ -0,0 +1,78 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// fixedClock is a Clock always returning the same time.
+type fixedClock struct{ t time.Time }
+
+func (c fixedClock) Now() time.Time { return c.t }
+
+func TestDoWithProfileLabelsUnwrapsClockSpans(t *testing.T) {
+	tp := clockTracerProvider{TracerProvider: sdktrace.NewTracerProvider(), clock: fixedClock{time.Unix(0, 0)}}
+	ctx, span := tp.Tracer("test").Start(context.Background(), "work")
+	defer span.End()
+
+	DoWithProfileLabels(ctx, func(ctx context.Context) {
+		if got, _ := pprof.Label(ctx, "span_name"); got != "work" {
+			t.Errorf("got span_name %q, want work", got)
+		}
+		if got, _ := pprof.Label(ctx, "trace_id"); got != span.SpanContext().TraceID().String() {
+			t.Errorf("got trace_id %q, want %s", got, span.SpanContext().TraceID())
+		}
+	})
+}
+
+func TestMiddlewareProfileLabels(t *testing.T) {
+	prevTP := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	defer otel.SetTracerProvider(prevTP)
+	prev := pprofLabels.Load()
+	pprofLabels.Store(true)
+	defer pprofLabels.Store(prev)
+
+	// The handler reads the labels of its goroutine from the goroutine
+	// profile, as CPU profiles do.
+	var profile bytes.Buffer
+	handler := func(http.ResponseWriter, *http.Request) {
+		pprof.Lookup("goroutine").WriteTo(&profile, 1)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orders/{id}", handler)
+
+	tests := []struct {
+		name         string
+		handler      http.Handler
+		wantSpanName bool
+	}{
+		{"ServeMux", mux, true},
+		{"other handler", http.HandlerFunc(handler), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile.Reset()
+			Middleware(tt.handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/42", nil))
+			if !strings.Contains(profile.String(), `"trace_id":`) {
+				t.Error("no goroutine is labeled with trace_id")
+			}
+			named := strings.Contains(profile.String(), `"span_name":"GET /orders/{id}"`)
+			if named != tt.wantSpanName {
+				t.Errorf("labeled with the route: got %v, want %v", named, tt.wantSpanName)
+			}
+			if strings.Contains(profile.String(), `"span_name":"GET"`) {
+				t.Error("labeled with the temporary span name")
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// PrivacyMode selects what happens to identity-bearing attributes before
+// export.
+type PrivacyMode int
+
+const (
+	// PrivacyOff exports attributes as recorded.
+	PrivacyOff PrivacyMode = iota
+	// PrivacyHash replaces the values of span and log attributes with a
+	// salted hash, so they can still be correlated but not read. Metric
+	// attributes are dropped, since hashing keeps their cardinality.
+	PrivacyHash
+	// PrivacyDrop removes the attributes from spans, metrics and logs.
+	PrivacyDrop
+)
+
+// defaultPrivacyKeys are the attributes protected when WithPrivacy is given
+// no keys.
+var defaultPrivacyKeys = []string{
+	"user.*",
+	"enduser.*",
+	"client.address",
+	"source.address",
+	"network.peer.address",
+	"http.client_ip",
+}
+
+// String returns the name used for m in TELEMETRY_PRIVACY_MODE.
+func (m PrivacyMode) String() string {
+	switch m {
+	case PrivacyOff:
+		return "off"
+	case PrivacyHash:
+		return "hash"
+	case PrivacyDrop:
+		return "drop"
+	}
+	return fmt.Sprintf("PrivacyMode(%d)", int(m))
+}
+
+// ParsePrivacyMode parses a mode name as returned by PrivacyMode.String.
+func ParsePrivacyMode(s string) (PrivacyMode, error) {
+	for _, m := range []PrivacyMode{PrivacyOff, PrivacyHash, PrivacyDrop} {
+		if strings.EqualFold(s, m.String()) {
+			return m, nil
+		}
+	}
+	return PrivacyOff, fmt.Errorf("telemetry: unknown privacy mode %q", s)
+}
+
+// WithPrivacy hashes or drops, depending on mode, the span, metric and log
+// attributes matching keys before export, and before spans are kept for
+// DumpRecentTraces, streamed by StreamSpans or printed by
+// EnableDebugExporter. Span event and link attributes are filtered as well. Keys are attribute names where
+// "*" matches any sequence of characters; without keys, user and client
+// identifiers such as user.*, enduser.* and client.address are protected.
+// Hashes are salted with salt, which should be kept secret so that values
+// such as email addresses can't be recovered by guessing. The mode, keys and
+// salt can also be set with the TELEMETRY_PRIVACY_MODE,
+// TELEMETRY_PRIVACY_ATTRIBUTES (comma separated) and TELEMETRY_PRIVACY_SALT
+// environment variables.
+func WithPrivacy(mode PrivacyMode, salt string, keys ...string) Option {
+	return func(c *config) {
+		c.privacy = newPrivacyFilter(mode, salt, keys)
+	}
+}
+
+// envPrivacy reads the privacy configuration from the environment.
+func envPrivacy() *privacyFilter {
+	v, ok := os.LookupEnv("TELEMETRY_PRIVACY_MODE")
+	if !ok {
+		return nil
+	}
+	mode, err := ParsePrivacyMode(v)
+	if err != nil {
+		otel.Handle(err)
+	}
+	var keys []string
+	for _, k := range strings.Split(os.Getenv("TELEMETRY_PRIVACY_ATTRIBUTES"), ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return newPrivacyFilter(mode, os.Getenv("TELEMETRY_PRIVACY_SALT"), keys)
+}
+
+// privacyFilter rewrites the protected attributes of each signal.
+type privacyFilter struct {
+	mode PrivacyMode
+	salt string
+	keys []string
+}
+
+func newPrivacyFilter(mode PrivacyMode, salt string, keys []string) *privacyFilter {
+	if mode == PrivacyOff {
+		return nil
+	}
+	if len(keys) == 0 {
+		keys = defaultPrivacyKeys
+	}
+	return &privacyFilter{mode: mode, salt: salt, keys: keys}
+}
+
+// protected reports whether the attribute key must be hashed or dropped.
+func (f *privacyFilter) protected(key string) bool {
+	for _, pattern := range f.keys {
+		if globMatch(pattern, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *privacyFilter) hash(value string) string {
+	sum := sha256.Sum256([]byte(f.salt + value))
+	return hex.EncodeToString(sum[:16])
+}
+
+// attributes returns attrs with the protected attributes hashed or dropped,
+// or attrs itself if none is protected.
+func (f *privacyFilter) attributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+	var out []attribute.KeyValue
+	for i, kv := range attrs {
+		if !f.protected(string(kv.Key)) {
+			if out != nil {
+				out = append(out, kv)
+			}
+			continue
+		}
+		if out == nil {
+			out = append(make([]attribute.KeyValue, 0, len(attrs)), attrs[:i]...)
+		}
+		if f.mode == PrivacyHash {
+			out = append(out, kv.Key.String(f.hash(kv.Value.Emit())))
+		}
+	}
+	if out == nil {
+		return attrs
+	}
+	return out
+}
+
+// views wraps views so that every metric stream drops the protected
+// attributes, on top of the attribute filter of the view. As with the SDK,
+// each of views matching an instrument creates a stream, and instruments
+// matching none get the default stream.
+func (f *privacyFilter) views(views []metric.View) []metric.View {
+	filter := func(kv attribute.KeyValue) bool { return !f.protected(string(kv.Key)) }
+	private := func(stream metric.Stream) metric.Stream {
+		if next := stream.AttributeFilter; next != nil {
+			stream.AttributeFilter = func(kv attribute.KeyValue) bool { return filter(kv) && next(kv) }
+		} else {
+			stream.AttributeFilter = filter
+		}
+		return stream
+	}
+
+	out := make([]metric.View, 0, len(views)+1)
+	for _, v := range views {
+		out = append(out, func(inst metric.Instrument) (metric.Stream, bool) {
+			stream, ok := v(inst)
+			if !ok {
+				return stream, false
+			}
+			return private(stream), true
+		})
+	}
+	return append(out, func(inst metric.Instrument) (metric.Stream, bool) {
+		for _, v := range views {
+			if _, ok := v(inst); ok {
+				return metric.Stream{}, false
+			}
+		}
+		return private(metric.Stream{
+			Name:        inst.Name,
+			Description: inst.Description,
+			Unit:        inst.Unit,
+		}), true
+	})
+}
+
+// span returns s with its protected attributes filtered, or s itself if f is
+// nil. Every outlet of finished spans goes through it: the exporters, the
+// recent span ring, the span streams and the debug exporter.
+func (f *privacyFilter) span(s trace.ReadOnlySpan) trace.ReadOnlySpan {
+	if f == nil {
+		return s
+	}
+	return privateSpan{ReadOnlySpan: s, filter: f}
+}
+
+// privacySpanExporter passes spans to the wrapped exporter with the
+// protected attributes of the spans, their events and links filtered.
+type privacySpanExporter struct {
+	trace.SpanExporter
+	filter *privacyFilter
+}
+
+func (e *privacySpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	filtered := make([]trace.ReadOnlySpan, len(spans))
+	for i, s := range spans {
+		filtered[i] = e.filter.span(s)
+	}
+	return e.SpanExporter.ExportSpans(ctx, filtered)
+}
+
+// privateSpan filters the attributes of a finished span.
+type privateSpan struct {
+	trace.ReadOnlySpan
+	filter *privacyFilter
+}
+
+func (s privateSpan) Attributes() []attribute.KeyValue {
+	return s.filter.attributes(s.ReadOnlySpan.Attributes())
+}
+
+func (s privateSpan) Events() []trace.Event {
+	events := s.ReadOnlySpan.Events()
+	out := make([]trace.Event, len(events))
+	for i, e := range events {
+		e.Attributes = s.filter.attributes(e.Attributes)
+		out[i] = e
+	}
+	return out
+}
+
+func (s privateSpan) Links() []trace.Link {
+	links := s.ReadOnlySpan.Links()
+	out := make([]trace.Link, len(links))
+	for i, l := range links {
+		l.Attributes = s.filter.attributes(l.Attributes)
+		out[i] = l
+	}
+	return out
+}
+
+// privacyLogProcessor filters the protected attributes of records before
+// they reach the next processors.
+type privacyLogProcessor struct {
+	filter *privacyFilter
+}
+
+func (p privacyLogProcessor) OnEmit(_ context.Context, r *sdklog.Record) error {
+	var attrs []log.KeyValue
+	changed := false
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		if !p.filter.protected(kv.Key) {
+			attrs = append(attrs, kv)
+			return true
+		}
+		changed = true
+		if p.filter.mode == PrivacyHash {
+			attrs = append(attrs, log.String(kv.Key, p.filter.hash(kv.Value.String())))
+		}
+		return true
+	})
+	if changed {
+		r.SetAttributes(attrs...)
+	}
+	return nil
+}
+
+func (privacyLogProcessor) Shutdown(context.Context) error   { return nil }
+func (privacyLogProcessor) ForceFlush(context.Context) error { return nil }
@@ -0,0 +1,147 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestPrivacyFilterAttributes(t *testing.T) {
+	attrs := []attribute.KeyValue{
+		attribute.String("http.route", "/orders"),
+		attribute.String("user.email", "jane@example.com"),
+		attribute.String("client.address", "192.0.2.1"),
+	}
+	hashed := newPrivacyFilter(PrivacyHash, "salt", nil)
+	tests := []struct {
+		name   string
+		filter *privacyFilter
+		want   []attribute.KeyValue
+	}{
+		{"drop", newPrivacyFilter(PrivacyDrop, "", nil), attrs[:1]},
+		{"hash", hashed, []attribute.KeyValue{
+			attrs[0],
+			attribute.String("user.email", hashed.hash("jane@example.com")),
+			attribute.String("client.address", hashed.hash("192.0.2.1")),
+		}},
+		{"custom keys", newPrivacyFilter(PrivacyDrop, "", []string{"http.*"}), attrs[1:]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.attributes(attrs); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if newPrivacyFilter(PrivacyOff, "", nil) != nil {
+		t.Error("PrivacyOff returned a filter")
+	}
+}
+
+func TestPrivacyAppliesToEverySpanOutlet(t *testing.T) {
+	const secret = "jane@example.com"
+	ctx := context.Background()
+	var exported, debug bytes.Buffer
+	shutdown, err := SetupOTelSDKStdout(ctx,
+		WithStdoutWriters(&exported, io.Discard, io.Discard),
+		WithSimpleSpanProcessor(),
+		WithRecentSpans(10),
+		WithPrivacy(PrivacyDrop, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(ctx)
+	if err := EnableDebugExporter(&debug); err != nil {
+		t.Fatal(err)
+	}
+	stream, err := StreamSpans()
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamed := make(chan []byte, 2)
+	stream.mu.Lock()
+	stream.subs[streamed] = struct{}{}
+	stream.mu.Unlock()
+
+	tracer := otel.Tracer("test")
+	_, linked := tracer.Start(ctx, "linked")
+	linked.End()
+	_, span := tracer.Start(ctx, "work",
+		oteltrace.WithAttributes(attribute.String("user.email", secret)),
+		oteltrace.WithLinks(oteltrace.Link{
+			SpanContext: linked.SpanContext(),
+			Attributes:  []attribute.KeyValue{attribute.String("user.email", secret)},
+		}))
+	span.AddEvent("login", oteltrace.WithAttributes(attribute.String("user.email", secret)))
+	span.End()
+
+	var dumped bytes.Buffer
+	if err := DumpRecentTraces(&dumped); err != nil {
+		t.Fatal(err)
+	}
+	outlets := map[string]string{
+		"exporter":         exported.String(),
+		"debug exporter":   debug.String(),
+		"recent span ring": dumped.String(),
+		"span stream":      string(<-streamed) + string(<-streamed),
+	}
+	for name, out := range outlets {
+		if !strings.Contains(out, "work") {
+			t.Errorf("%s: span missing from %q", name, out)
+		}
+		if strings.Contains(out, secret) {
+			t.Errorf("%s: protected attribute leaked in %q", name, out)
+		}
+	}
+}
+
+func TestPrivacyViews(t *testing.T) {
+	filter := newPrivacyFilter(PrivacyDrop, "", nil)
+	rename := func(name string) metric.View {
+		return metric.NewView(metric.Instrument{Name: "requests"}, metric.Stream{Name: name})
+	}
+	reader := metric.NewManualReader()
+	mp := metric.NewMeterProvider(
+		metric.WithReader(reader),
+		metric.WithView(filter.views([]metric.View{rename("requests.a"), rename("requests.b")})...))
+	meter := mp.Meter("test")
+	attrs := otelmetric.WithAttributes(attribute.String("user.id", "42"), attribute.String("http.route", "/orders"))
+	for _, name := range []string{"requests", "errors"} {
+		counter, err := meter.Int64Counter(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counter.Add(context.Background(), 1, attrs)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		names = append(names, m.Name)
+		for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+			if dp.Attributes.HasValue("user.id") {
+				t.Errorf("%s: protected attribute kept", m.Name)
+			}
+			if !dp.Attributes.HasValue("http.route") {
+				t.Errorf("%s: unprotected attribute dropped", m.Name)
+			}
+		}
+	}
+	slices.Sort(names)
+	if want := []string{"errors", "requests.a", "requests.b"}; !slices.Equal(names, want) {
+		t.Errorf("got streams %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,148 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// defaultProfilingInterval is the length of each captured profile.
+const defaultProfilingInterval = 10 * time.Second
+
+// WithProfiling continuously captures CPU and heap profiles of interval
+// length and uploads them to the Pyroscope-compatible server at url, tagged
+// with the service name. Profiling stops when the pipeline shuts down. It
+// defaults to TELEMETRY_PYROSCOPE_URL and TELEMETRY_PROFILING_INTERVAL, or
+// 10s. CPU profiles are skipped while another CPU profile is running, e.g.
+// one requested through net/http/pprof.
+func WithProfiling(url string, interval time.Duration) Option {
+	return func(c *config) {
+		c.profilingURL = url
+		if interval > 0 {
+			c.profilingInterval = interval
+		}
+	}
+}
+
+// profiler periodically captures and uploads profiles.
+type profiler struct {
+	url      string
+	app      string
+	interval time.Duration
+	client   *http.Client
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func startProfiler(url string, interval time.Duration, res *resource.Resource) *profiler {
+	app := "unknown_service"
+	if v, ok := res.Set().Value(semconv.ServiceNameKey); ok {
+		app = v.AsString()
+	}
+	p := &profiler{
+		url:      strings.TrimSuffix(url, "/"),
+		app:      app,
+		interval: interval,
+		client:   &http.Client{Timeout: interval},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *profiler) run() {
+	defer close(p.done)
+	for {
+		from := time.Now()
+		var cpu bytes.Buffer
+		cpuErr := pprof.StartCPUProfile(&cpu)
+		if cpuErr != nil {
+			otel.Handle(fmt.Errorf("telemetry: skipping CPU profile: %w", cpuErr))
+		}
+
+		stopped := false
+		select {
+		case <-p.stop:
+			stopped = true
+		case <-time.After(p.interval):
+		}
+		until := time.Now()
+
+		if cpuErr == nil {
+			pprof.StopCPUProfile()
+			if err := p.upload(p.app+".cpu", from, until, &cpu); err != nil {
+				otel.Handle(err)
+			}
+		}
+		var heap bytes.Buffer
+		if err := pprof.Lookup("heap").WriteTo(&heap, 0); err != nil {
+			otel.Handle(err)
+		} else if err := p.upload(p.app+".heap", from, until, &heap); err != nil {
+			otel.Handle(err)
+		}
+
+		if stopped {
+			return
+		}
+	}
+}
+
+// upload sends a pprof encoded profile to the ingest endpoint.
+func (p *profiler) upload(name string, from, until time.Time, profile io.Reader) error {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("profile", "profile.pprof")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, profile); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	q := url.Values{}
+	q.Set("name", name)
+	q.Set("from", strconv.FormatInt(from.Unix(), 10))
+	q.Set("until", strconv.FormatInt(until.Unix(), 10))
+	q.Set("format", "pprof")
+	q.Set("spyName", "gospy")
+	resp, err := p.client.Post(p.url+"/ingest?"+q.Encode(), w.FormDataContentType(), &body)
+	if err != nil {
+		return fmt.Errorf("telemetry: profile upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("telemetry: profile upload failed: %s", resp.Status)
+	}
+	return nil
+}
+
+// shutdown stops profiling after uploading the profiles in progress.
+func (p *profiler) shutdown(ctx context.Context) error {
+	select {
+	case <-p.stop:
+	default:
+		close(p.stop)
+	}
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
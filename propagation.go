@@ -0,0 +1,72 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Extract returns a copy of ctx carrying the trace context and baggage found
+// in header, using the propagator installed by the setup.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Inject writes the trace context and baggage of ctx into header, using the
+// propagator installed by the setup.
+func Inject(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// defaultPropagators are used when neither WithPropagators nor
+// OTEL_PROPAGATORS is set.
+var defaultPropagators = []string{"tracecontext", "baggage"}
+
+// WithPropagators selects the propagators installed by the setup, in order,
+// by name: tracecontext, baggage, b3 (single header), b3multi and none. It
+// defaults to the OTEL_PROPAGATORS environment variable, a comma separated
+// list of the same names, or to tracecontext and baggage.
+func WithPropagators(names ...string) Option {
+	return func(c *config) {
+		c.propagators = names
+	}
+}
+
+// envPropagators reads the propagator names from the environment variable
+// key.
+func envPropagators(key string) []string {
+	v, ok := os.LookupEnv(key)
+	if !ok || strings.TrimSpace(v) == "" {
+		return defaultPropagators
+	}
+	return strings.Split(v, ",")
+}
+
+// newPropagator builds the composite of the named propagators. Unknown names
+// are reported and skipped.
+func newPropagator(names []string) propagation.TextMapPropagator {
+	var props []propagation.TextMapPropagator
+	for _, name := range names {
+		switch name = strings.ToLower(strings.TrimSpace(name)); name {
+		case "tracecontext":
+			props = append(props, propagation.TraceContext{})
+		case "baggage":
+			props = append(props, propagation.Baggage{})
+		case "b3":
+			props = append(props, b3Propagator{single: true})
+		case "b3multi":
+			props = append(props, b3Propagator{})
+		case "none":
+			return propagation.NewCompositeTextMapPropagator()
+		case "":
+		default:
+			otel.Handle(fmt.Errorf("telemetry: unknown propagator %q", name))
+		}
+	}
+	return propagation.NewCompositeTextMapPropagator(props...)
+}
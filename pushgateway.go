@@ -0,0 +1,85 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// WithPushgateway pushes the final value of every metric to the Prometheus
+// Pushgateway at url when the pipeline shuts down, grouped under job, for
+// batch jobs that exit before the periodic reader fires. An empty job uses
+// the service name. The configured metric exporter also receives a final
+// export at shutdown. It defaults to TELEMETRY_PUSHGATEWAY_URL and
+// TELEMETRY_PUSHGATEWAY_JOB.
+func WithPushgateway(url, job string) Option {
+	return func(c *config) {
+		c.pushgatewayURL = url
+		c.pushgatewayJob = job
+	}
+}
+
+// labelEscaper escapes label values in the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// pushToGateway collects the metrics of reader and replaces the group of job
+// on the Pushgateway at gateway with them.
+func pushToGateway(ctx context.Context, gateway, job string, reader metric.Reader) error {
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		return err
+	}
+	if job == "" && rm.Resource != nil {
+		if v, ok := rm.Resource.Set().Value(semconv.ServiceNameKey); ok {
+			job = v.AsString()
+		}
+	}
+	if job == "" {
+		job = "unknown_job"
+	}
+
+	var body bytes.Buffer
+	walkPromSeries(&rm, func(name string, labels []promLabel, value float64, _ time.Time) {
+		body.WriteString(name)
+		if len(labels) > 0 {
+			body.WriteByte('{')
+			for i, l := range labels {
+				if i > 0 {
+					body.WriteByte(',')
+				}
+				fmt.Fprintf(&body, "%s=\"%s\"", l.name, labelEscaper.Replace(l.value))
+			}
+			body.WriteByte('}')
+		}
+		body.WriteByte(' ')
+		body.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+		body.WriteByte('\n')
+	})
+
+	target := strings.TrimSuffix(gateway, "/") + "/metrics/job/" + url.PathEscape(job)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("telemetry: push to Pushgateway failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("telemetry: push to Pushgateway failed: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
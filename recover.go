@@ -0,0 +1,58 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RecoverAndRecord records a panic on the span in ctx, flushes the
+// telemetry pipeline and re-panics. It must be deferred directly:
+//
+//	defer telemetry.RecoverAndRecord(ctx)
+func RecoverAndRecord(ctx context.Context) {
+	if r := recover(); r != nil {
+		recordPanic(ctx, r, debug.Stack())
+		panic(r)
+	}
+}
+
+// RecoverToError behaves like RecoverAndRecord but, instead of re-panicking,
+// stores the panic as an error in *errp. It must be deferred directly:
+//
+//	defer telemetry.RecoverToError(ctx, &err)
+func RecoverToError(ctx context.Context, errp *error) {
+	if r := recover(); r != nil {
+		err := recordPanic(ctx, r, debug.Stack())
+		if errp != nil {
+			*errp = err
+		}
+	}
+}
+
+// recordPanic adds the panic value and stack to the active span as an
+// exception event, marks the span as failed and flushes buffered telemetry.
+// The span is left for its owner to end.
+func recordPanic(ctx context.Context, r any, stack []byte) error {
+	err, ok := r.(error)
+	if !ok {
+		err = fmt.Errorf("panic: %v", r)
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent(semconv.ExceptionEventName, trace.WithAttributes(
+		semconv.ExceptionType(fmt.Sprintf("%T", r)),
+		semconv.ExceptionMessage(err.Error()),
+		semconv.ExceptionStacktrace(string(stack)),
+		attribute.Bool("exception.escaped", true),
+	))
+	span.SetStatus(codes.Error, err.Error())
+
+	_ = ForceFlush(ctx)
+	return err
+}
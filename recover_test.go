@@ -0,0 +1,40 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRecoverToErrorLeavesSpanOpen(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "work")
+
+	err := func() (err error) {
+		defer RecoverToError(ctx, &err)
+		panic(errors.New("boom"))
+	}()
+	if err == nil || err.Error() != "boom" {
+		t.Fatalf("got error %v, want boom", err)
+	}
+	if len(sr.Ended()) != 0 {
+		t.Fatal("the span was ended by RecoverToError")
+	}
+
+	span.End()
+	ended := sr.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("got %d ended spans, want 1", len(ended))
+	}
+	if got := ended[0].Status().Code; got != codes.Error {
+		t.Errorf("got status %v, want Error", got)
+	}
+	if events := ended[0].Events(); len(events) != 1 || events[0].Name != "exception" {
+		t.Errorf("got events %v, want one exception", events)
+	}
+}
@@ -0,0 +1,242 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// remoteWriteTimeout bounds each remote write request.
+const remoteWriteTimeout = 30 * time.Second
+
+// WithRemoteWrite additionally pushes metrics to the Prometheus remote write
+// endpoint url, such as a Mimir or Thanos receiver, on the SDK's default
+// export interval. It defaults to TELEMETRY_PROMETHEUS_REMOTE_WRITE_URL.
+func WithRemoteWrite(url string) Option {
+	return func(c *config) {
+		c.remoteWriteURL = url
+	}
+}
+
+// RemoteWriteExporter is a metric exporter pushing cumulative metrics with
+// the Prometheus remote write 1.0 protocol. Monotonic sums become counters
+// with a _total suffix, other sums and gauges become gauges, and histograms
+// become _bucket, _sum and _count series. The service name and instance ID
+// of the resource become the job and instance labels.
+type RemoteWriteExporter struct {
+	url    string
+	client *http.Client
+
+	mu       sync.Mutex
+	shutdown bool
+}
+
+// NewRemoteWriteExporter returns an exporter pushing to the remote write
+// endpoint url.
+func NewRemoteWriteExporter(url string) *RemoteWriteExporter {
+	return &RemoteWriteExporter{
+		url:    url,
+		client: &http.Client{Timeout: remoteWriteTimeout},
+	}
+}
+
+// Temporality returns cumulative temporality, which remote write requires.
+func (e *RemoteWriteExporter) Temporality(metric.InstrumentKind) metricdata.Temporality {
+	return metricdata.CumulativeTemporality
+}
+
+// Aggregation returns the default aggregation for kind.
+func (e *RemoteWriteExporter) Aggregation(kind metric.InstrumentKind) metric.Aggregation {
+	return metric.DefaultAggregationSelector(kind)
+}
+
+// Export sends rm to the endpoint.
+func (e *RemoteWriteExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	e.mu.Lock()
+	shutdown := e.shutdown
+	e.mu.Unlock()
+	if shutdown {
+		return nil
+	}
+
+	body := snappy.Encode(nil, encodeWriteRequest(rm))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("telemetry: remote write failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("telemetry: remote write failed: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// ForceFlush does nothing; the exporter holds no state.
+func (e *RemoteWriteExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+// Shutdown makes subsequent exports no-ops.
+func (e *RemoteWriteExporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	e.shutdown = true
+	e.mu.Unlock()
+	return nil
+}
+
+// promLabel is a Prometheus label pair.
+type promLabel struct {
+	name, value string
+}
+
+// encodeWriteRequest encodes rm as a prometheus.WriteRequest message.
+func encodeWriteRequest(rm *metricdata.ResourceMetrics) []byte {
+	base := resourceLabels(rm.Resource)
+	var buf []byte
+	walkPromSeries(rm, func(name string, labels []promLabel, value float64, ts time.Time) {
+		all := append(slices.Clone(base), labels...)
+		all = append(all, promLabel{"__name__", name})
+		slices.SortFunc(all, func(a, b promLabel) int { return strings.Compare(a.name, b.name) })
+		buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, encodeTimeSeries(all, value, ts))
+	})
+	return buf
+}
+
+// walkPromSeries calls series for every Prometheus series of rm.
+func walkPromSeries(rm *metricdata.ResourceMetrics, series seriesFunc) {
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			name := promName(m.Name)
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				writeSum(series, name, data.IsMonotonic, data.DataPoints)
+			case metricdata.Sum[float64]:
+				writeSum(series, name, data.IsMonotonic, data.DataPoints)
+			case metricdata.Gauge[int64]:
+				writeGauge(series, name, data.DataPoints)
+			case metricdata.Gauge[float64]:
+				writeGauge(series, name, data.DataPoints)
+			case metricdata.Histogram[int64]:
+				writeHistogram(series, name, data.DataPoints)
+			case metricdata.Histogram[float64]:
+				writeHistogram(series, name, data.DataPoints)
+			}
+		}
+	}
+}
+
+type seriesFunc func(name string, labels []promLabel, value float64, ts time.Time)
+
+func writeSum[N int64 | float64](series seriesFunc, name string, monotonic bool, points []metricdata.DataPoint[N]) {
+	if monotonic && !strings.HasSuffix(name, "_total") {
+		name += "_total"
+	}
+	writeGauge(series, name, points)
+}
+
+func writeGauge[N int64 | float64](series seriesFunc, name string, points []metricdata.DataPoint[N]) {
+	for _, p := range points {
+		series(name, attributeLabels(p.Attributes), float64(p.Value), p.Time)
+	}
+}
+
+func writeHistogram[N int64 | float64](series seriesFunc, name string, points []metricdata.HistogramDataPoint[N]) {
+	for _, p := range points {
+		labels := attributeLabels(p.Attributes)
+		var cumulative uint64
+		for i, bound := range p.Bounds {
+			cumulative += p.BucketCounts[i]
+			le := promLabel{"le", strconv.FormatFloat(bound, 'g', -1, 64)}
+			series(name+"_bucket", append(slices.Clone(labels), le), float64(cumulative), p.Time)
+		}
+		series(name+"_bucket", append(slices.Clone(labels), promLabel{"le", "+Inf"}), float64(p.Count), p.Time)
+		series(name+"_sum", labels, float64(p.Sum), p.Time)
+		series(name+"_count", labels, float64(p.Count), p.Time)
+	}
+}
+
+// encodeTimeSeries encodes a prometheus.TimeSeries message with one sample.
+func encodeTimeSeries(labels []promLabel, value float64, ts time.Time) []byte {
+	var b []byte
+	for _, l := range labels {
+		var lb []byte
+		lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.name)
+		lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.value)
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, lb)
+	}
+	var sb []byte
+	sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+	sb = protowire.AppendFixed64(sb, math.Float64bits(value))
+	sb = protowire.AppendTag(sb, 2, protowire.VarintType)
+	sb = protowire.AppendVarint(sb, uint64(ts.UnixMilli()))
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	return protowire.AppendBytes(b, sb)
+}
+
+// resourceLabels returns the job and instance labels derived from res.
+func resourceLabels(res *resource.Resource) []promLabel {
+	var labels []promLabel
+	if res == nil {
+		return labels
+	}
+	job, _ := res.Set().Value(semconv.ServiceNameKey)
+	if ns, ok := res.Set().Value(semconv.ServiceNamespaceKey); ok && job.AsString() != "" {
+		labels = append(labels, promLabel{"job", ns.AsString() + "/" + job.AsString()})
+	} else if job.AsString() != "" {
+		labels = append(labels, promLabel{"job", job.AsString()})
+	}
+	if instance, ok := res.Set().Value(semconv.ServiceInstanceIDKey); ok {
+		labels = append(labels, promLabel{"instance", instance.AsString()})
+	}
+	return labels
+}
+
+func attributeLabels(set attribute.Set) []promLabel {
+	labels := make([]promLabel, 0, set.Len())
+	for _, kv := range set.ToSlice() {
+		labels = append(labels, promLabel{promName(string(kv.Key)), kv.Value.Emit()})
+	}
+	return labels
+}
+
+// promName replaces the characters that are invalid in Prometheus metric and
+// label names with underscores.
+func promName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		valid := c == '_' || c == ':' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && '0' <= c && c <= '9'
+		if !valid {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,215 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decodeWriteRequest returns the series of a prometheus.WriteRequest message
+// in the text exposition format, followed by the timestamp.
+func decodeWriteRequest(t *testing.T, b []byte) []string {
+	t.Helper()
+	// fields calls field for each field of the message b.
+	fields := func(b []byte, field func(num protowire.Number, typ protowire.Type, v []byte, n uint64)) {
+		for len(b) > 0 {
+			num, typ, n := protowire.ConsumeTag(b)
+			if n < 0 {
+				t.Fatal(protowire.ParseError(n))
+			}
+			b = b[n:]
+			switch typ {
+			case protowire.BytesType:
+				v, n := protowire.ConsumeBytes(b)
+				field(num, typ, v, 0)
+				b = b[n:]
+			case protowire.Fixed64Type:
+				v, n := protowire.ConsumeFixed64(b)
+				field(num, typ, nil, v)
+				b = b[n:]
+			case protowire.VarintType:
+				v, n := protowire.ConsumeVarint(b)
+				field(num, typ, nil, v)
+				b = b[n:]
+			default:
+				t.Fatalf("unexpected wire type %v", typ)
+			}
+		}
+	}
+
+	var series []string
+	fields(b, func(_ protowire.Number, _ protowire.Type, ts []byte, _ uint64) {
+		var (
+			name   string
+			labels []string
+			sample string
+		)
+		fields(ts, func(num protowire.Number, _ protowire.Type, v []byte, _ uint64) {
+			switch num {
+			case 1:
+				var label [2]string
+				fields(v, func(num protowire.Number, _ protowire.Type, v []byte, _ uint64) {
+					label[num-1] = string(v)
+				})
+				if label[0] == "__name__" {
+					name = label[1]
+				} else {
+					labels = append(labels, fmt.Sprintf("%s=%q", label[0], label[1]))
+				}
+			case 2:
+				var value float64
+				var ms uint64
+				fields(v, func(num protowire.Number, _ protowire.Type, _ []byte, n uint64) {
+					if num == 1 {
+						value = math.Float64frombits(n)
+					} else {
+						ms = n
+					}
+				})
+				sample = fmt.Sprintf("%g %d", value, ms)
+			}
+		})
+		series = append(series, fmt.Sprintf("%s{%s} %s", name, strings.Join(labels, ","), sample))
+	})
+	return series
+}
+
+func TestEncodeWriteRequest(t *testing.T) {
+	ts := time.UnixMilli(1700000000000)
+	route := attribute.NewSet(attribute.String("http.route", "/orders"))
+	tests := []struct {
+		name     string
+		resource *resource.Resource
+		metric   metricdata.Metrics
+		want     []string
+	}{
+		{
+			name: "monotonic sum",
+			metric: metricdata.Metrics{Name: "telemetry.http.server.request.count", Data: metricdata.Sum[int64]{
+				IsMonotonic: true,
+				DataPoints:  []metricdata.DataPoint[int64]{{Attributes: route, Time: ts, Value: 42}},
+			}},
+			want: []string{`telemetry_http_server_request_count_total{http_route="/orders"} 42 1700000000000`},
+		},
+		{
+			name: "existing total suffix",
+			metric: metricdata.Metrics{Name: "jobs_total", Data: metricdata.Sum[float64]{
+				IsMonotonic: true,
+				DataPoints:  []metricdata.DataPoint[float64]{{Time: ts, Value: 1.5}},
+			}},
+			want: []string{`jobs_total{} 1.5 1700000000000`},
+		},
+		{
+			name: "non-monotonic sum and gauge",
+			metric: metricdata.Metrics{Name: "queue.depth", Data: metricdata.Sum[int64]{
+				DataPoints: []metricdata.DataPoint[int64]{{Time: ts, Value: -3}},
+			}},
+			want: []string{`queue_depth{} -3 1700000000000`},
+		},
+		{
+			name: "histogram",
+			metric: metricdata.Metrics{Name: "latency", Data: metricdata.Histogram[float64]{
+				DataPoints: []metricdata.HistogramDataPoint[float64]{{
+					Attributes:   route,
+					Time:         ts,
+					Count:        6,
+					Sum:          7.5,
+					Bounds:       []float64{0.5, 1},
+					BucketCounts: []uint64{1, 2, 3},
+				}},
+			}},
+			want: []string{
+				`latency_bucket{http_route="/orders",le="0.5"} 1 1700000000000`,
+				`latency_bucket{http_route="/orders",le="1"} 3 1700000000000`,
+				`latency_bucket{http_route="/orders",le="+Inf"} 6 1700000000000`,
+				`latency_sum{http_route="/orders"} 7.5 1700000000000`,
+				`latency_count{http_route="/orders"} 6 1700000000000`,
+			},
+		},
+		{
+			name: "resource labels",
+			resource: resource.NewSchemaless(
+				semconv.ServiceName("checkout"),
+				semconv.ServiceNamespace("shop"),
+				semconv.ServiceInstanceID("pod-1"),
+			),
+			metric: metricdata.Metrics{Name: "up", Data: metricdata.Gauge[int64]{
+				DataPoints: []metricdata.DataPoint[int64]{{Time: ts, Value: 1}},
+			}},
+			want: []string{`up{instance="pod-1",job="shop/checkout"} 1 1700000000000`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm := &metricdata.ResourceMetrics{
+				Resource:     tt.resource,
+				ScopeMetrics: []metricdata.ScopeMetrics{{Metrics: []metricdata.Metrics{tt.metric}}},
+			}
+			got := decodeWriteRequest(t, encodeWriteRequest(rm))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("series:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestRemoteWriteExporter(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"accepted", http.StatusNoContent, false},
+		{"rejected", http.StatusBadRequest, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var series []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Content-Encoding") != "snappy" || r.Header.Get("X-Prometheus-Remote-Write-Version") != "0.1.0" {
+					t.Errorf("headers = %v", r.Header)
+				}
+				body, _ := io.ReadAll(r.Body)
+				data, err := snappy.Decode(nil, body)
+				if err != nil {
+					t.Error(err)
+				}
+				series = decodeWriteRequest(t, data)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			exp := NewRemoteWriteExporter(srv.URL)
+			rm := &metricdata.ResourceMetrics{ScopeMetrics: []metricdata.ScopeMetrics{{Metrics: []metricdata.Metrics{{
+				Name: "up",
+				Data: metricdata.Gauge[int64]{DataPoints: []metricdata.DataPoint[int64]{{Time: time.UnixMilli(1), Value: 1}}},
+			}}}}}
+			if err := exp.Export(context.Background(), rm); (err != nil) != tt.wantErr {
+				t.Errorf("Export() = %v, want error %v", err, tt.wantErr)
+			}
+			if want := []string{"up{} 1 1"}; !slices.Equal(series, want) {
+				t.Errorf("series = %v, want %v", series, want)
+			}
+
+			exp.Shutdown(context.Background())
+			series = nil
+			if err := exp.Export(context.Background(), rm); err != nil || series != nil {
+				t.Errorf("export after shutdown = %v, sent %v", err, series)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// WithResourceAttributes adds attrs to the resource describing the service,
+// shared by all signals. They take precedence over every other source,
+// including OTEL_RESOURCE_ATTRIBUTES.
+func WithResourceAttributes(attrs ...attribute.KeyValue) Option {
+	return func(c *config) {
+		c.resourceAttrs = append(c.resourceAttrs, attrs...)
+	}
+}
+
+// WithResource merges res, e.g. the output of a third-party detector, into
+// the resource describing the service. Its attributes take precedence over
+// the SDK defaults but not over the environment or WithResourceAttributes.
+// If its schema URL conflicts with the one used by this package, its
+// attributes are still merged and the resource keeps this package's schema
+// URL.
+func WithResource(res *resource.Resource) Option {
+	return func(c *config) {
+		c.resources = append(c.resources, res)
+	}
+}
+
+// instanceID identifies this process among the replicas of the service. It
+// is generated once, so every setup in the process reports the same
+// service.instance.id. It can be overridden like any other attribute.
+var instanceID = sync.OnceValue(func() string {
+	return uuid.NewString()
+})
+
+// ParseResourceAttributes parses a comma separated list of key=value pairs
+// in the format of OTEL_RESOURCE_ATTRIBUTES, where keys and values may be
+// percent-encoded. Invalid pairs are skipped and reported in the error.
+func ParseResourceAttributes(s string) ([]attribute.KeyValue, error) {
+	var attrs []attribute.KeyValue
+	var errs []error
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		key, kerr := url.PathUnescape(strings.TrimSpace(k))
+		value, verr := url.PathUnescape(strings.TrimSpace(v))
+		if !ok || key == "" || kerr != nil || verr != nil {
+			errs = append(errs, fmt.Errorf("telemetry: invalid resource attribute %q", pair))
+			continue
+		}
+		attrs = append(attrs, attribute.String(key, value))
+	}
+	return attrs, errors.Join(errs...)
+}
+
+// newResource builds the resource shared by all signals, identified by the
+// semantic conventions schema URL this package emits attributes for. From
+// lowest to highest precedence, attributes come from the SDK defaults and
+// the generated service.instance.id, the detectors, the resources passed to
+// WithResource, the Kubernetes downward API variables, see
+// kubernetesAttributes, OTEL_RESOURCE_ATTRIBUTES, OTEL_SERVICE_NAME and
+// WithResourceAttributes, so deploy-time attributes fill in for, but don't
+// override, the ones set in code.
+func newResource(ctx context.Context, cfg config) (*resource.Resource, error) {
+	id := instanceID()
+	if cfg.stdoutDeterministic {
+		id = uuid.Nil.String()
+	}
+	res, err := mergeResources(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceInstanceID(id)))
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.detectors) > 0 {
+		if res, err = mergeResources(res, detectResource(ctx, cfg.detectors)); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range cfg.resources {
+		if res, err = mergeResources(res, r); err != nil {
+			return nil, err
+		}
+	}
+
+	envAttrs, err := kubernetesAttributes()
+	if err != nil {
+		otel.Handle(err)
+	}
+	attrs, err := ParseResourceAttributes(os.Getenv("OTEL_RESOURCE_ATTRIBUTES"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	envAttrs = append(envAttrs, attrs...)
+	if name := strings.TrimSpace(os.Getenv("OTEL_SERVICE_NAME")); name != "" {
+		envAttrs = append(envAttrs, semconv.ServiceName(name))
+	}
+	if res, err = mergeResources(res, resource.NewWithAttributes(semconv.SchemaURL, envAttrs...)); err != nil {
+		return nil, err
+	}
+
+	return mergeResources(res, resource.NewWithAttributes(semconv.SchemaURL, cfg.resourceAttrs...))
+}
+
+// mergeResources merges b into a, b's attributes winning. Unlike
+// resource.Merge, conflicting schema URLs are resolved by keeping
+// semconv.SchemaURL, under which this package's attributes are emitted.
+func mergeResources(a, b *resource.Resource) (*resource.Resource, error) {
+	res, err := resource.Merge(a, b)
+	if !errors.Is(err, resource.ErrSchemaURLConflict) {
+		return res, err
+	}
+	return resource.Merge(
+		resource.NewWithAttributes(semconv.SchemaURL, a.Attributes()...),
+		resource.NewWithAttributes(semconv.SchemaURL, b.Attributes()...),
+	)
+}
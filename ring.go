@@ -0,0 +1,130 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recentSpans holds the spans that most recently finished.
+var recentSpans = newSpanRing(0)
+
+// WithRecentSpans keeps the n most recently finished spans in memory for
+// DumpRecentTraces, including the spans the sampler dropped: they are
+// recorded, though not exported, which costs as much as sampling every
+// span. Zero, the default, disables the ring. It overrides the
+// TELEMETRY_RECENT_SPANS environment variable.
+func WithRecentSpans(n int) Option {
+	return func(c *config) {
+		c.recentSpans = n
+	}
+}
+
+// DumpRecentTraces writes the spans that most recently finished to w as JSON
+// lines, oldest first. Spans are kept independently of the sampler and the
+// exporters, so they can be dumped after a fatal error even if the collector
+// never got them. It writes nothing unless WithRecentSpans enabled the ring.
+func DumpRecentTraces(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, span := range recentSpans.snapshot() {
+		if err := enc.Encode(tracetest.SpanStubFromReadOnlySpan(span)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordingSampler turns the drop decisions of its base sampler into record
+// only decisions, so the ring receives the dropped spans while the exporters,
+// which only receive sampled spans, don't.
+type recordingSampler struct {
+	base trace.Sampler
+}
+
+// newRecordingSampler wraps base, or the default sampler of the SDK if nil.
+// The samplers the SDK selects from OTEL_TRACES_SAMPLER are left unwrapped,
+// as they can't be built here.
+func newRecordingSampler(base trace.Sampler) trace.Sampler {
+	if base == nil {
+		if os.Getenv("OTEL_TRACES_SAMPLER") != "" {
+			return nil
+		}
+		base = trace.ParentBased(trace.AlwaysSample())
+	}
+	return recordingSampler{base: base}
+}
+
+func (s recordingSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	res := s.base.ShouldSample(p)
+	if res.Decision == trace.Drop {
+		res.Decision = trace.RecordOnly
+	}
+	return res
+}
+
+func (s recordingSampler) Description() string {
+	return s.base.Description()
+}
+
+// spanRing is a span processor keeping the last finished spans in a
+// fixed-size ring.
+type spanRing struct {
+	mu     sync.Mutex
+	spans  []trace.ReadOnlySpan
+	next   int
+	full   bool
+	filter *privacyFilter
+}
+
+var _ trace.SpanProcessor = (*spanRing)(nil)
+
+func newSpanRing(size int) *spanRing {
+	return &spanRing{spans: make([]trace.ReadOnlySpan, size)}
+}
+
+// reset empties the ring, changes its capacity and sets the privacy filter
+// of the spans it keeps.
+func (r *spanRing) reset(size int, filter *privacyFilter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = make([]trace.ReadOnlySpan, size)
+	r.next, r.full = 0, false
+	r.filter = filter
+}
+
+func (r *spanRing) snapshot() []trace.ReadOnlySpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]trace.ReadOnlySpan(nil), r.spans[:r.next]...)
+	}
+	return append(append([]trace.ReadOnlySpan(nil), r.spans[r.next:]...), r.spans[:r.next]...)
+}
+
+func (r *spanRing) OnStart(context.Context, trace.ReadWriteSpan) {}
+
+func (r *spanRing) OnEnd(span trace.ReadOnlySpan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.spans) == 0 {
+		return
+	}
+	r.spans[r.next] = r.filter.span(span)
+	r.next = (r.next + 1) % len(r.spans)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *spanRing) Shutdown(context.Context) error {
+	return nil
+}
+
+func (r *spanRing) ForceFlush(context.Context) error {
+	return nil
+}
@@ -0,0 +1,53 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestDumpRecentTracesKeepsDroppedSpans(t *testing.T) {
+	tests := []struct {
+		name        string
+		recent      int
+		wantDumped  bool
+		wantPrinted bool
+	}{
+		{"ring disabled by default", 0, false, false},
+		{"ring enabled", 10, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			var traces bytes.Buffer
+			shutdown, err := SetupOTelSDKStdout(ctx,
+				WithStdoutWriters(&traces, io.Discard, io.Discard),
+				WithSimpleSpanProcessor(),
+				WithSampler(trace.NeverSample()),
+				WithRecentSpans(tt.recent))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer shutdown(ctx)
+
+			_, span := otel.Tracer("test").Start(ctx, "dropped")
+			span.End()
+
+			var dump bytes.Buffer
+			if err := DumpRecentTraces(&dump); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(dump.String(), `"dropped"`); got != tt.wantDumped {
+				t.Errorf("span dumped: got %v, want %v", got, tt.wantDumped)
+			}
+			if got := strings.Contains(traces.String(), `"dropped"`); got != tt.wantPrinted {
+				t.Errorf("span exported: got %v, want %v", got, tt.wantPrinted)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package telemetry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+)
+
+// rotationTimeFormat suffixes the names of rotated files. It sorts in
+// chronological order.
+const rotationTimeFormat = "20060102T150405.000000000"
+
+// LogRotation configures the rotation of the log file set with
+// WithStdoutFiles. Zero fields disable the corresponding limit.
+type LogRotation struct {
+	// MaxBytes rotates the file before it grows past this size.
+	MaxBytes int64
+	// Interval rotates the file once it has been written to for this long.
+	Interval time.Duration
+	// MaxBackups is the number of rotated files kept, the oldest being
+	// deleted first.
+	MaxBackups int
+	// MaxAge deletes the rotated files older than this.
+	MaxAge time.Duration
+}
+
+// WithLogRotation rotates the log file set with WithStdoutFiles, or the
+// TELEMETRY_STDOUT_LOGS_FILE environment variable, when it reaches a size or
+// an age, renaming it with a timestamp suffix and deleting the oldest rotated
+// files past the retention, so that logs can be kept on hosts without a
+// collector without filling the disk. The limits can also be set with the
+// TELEMETRY_STDOUT_LOGS_MAX_MIB, TELEMETRY_STDOUT_LOGS_ROTATE_INTERVAL,
+// TELEMETRY_STDOUT_LOGS_MAX_BACKUPS and TELEMETRY_STDOUT_LOGS_MAX_AGE
+// environment variables.
+func WithLogRotation(r LogRotation) Option {
+	return func(c *config) {
+		c.logRotation = r
+	}
+}
+
+// envLogRotation reads the log rotation limits from the environment.
+func envLogRotation() LogRotation {
+	return LogRotation{
+		MaxBytes:   int64(envInt("TELEMETRY_STDOUT_LOGS_MAX_MIB", 0)) << 20,
+		Interval:   envDuration("TELEMETRY_STDOUT_LOGS_ROTATE_INTERVAL", 0),
+		MaxBackups: envInt("TELEMETRY_STDOUT_LOGS_MAX_BACKUPS", 0),
+		MaxAge:     envDuration("TELEMETRY_STDOUT_LOGS_MAX_AGE", 0),
+	}
+}
+
+// rotatingFile is a file rotated according to a LogRotation. Writes are
+// never split across files, so each record exported stays whole.
+type rotatingFile struct {
+	path     string
+	rotation LogRotation
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+func openRotatingFile(path string, rotation LogRotation) (*rotatingFile, error) {
+	f := &rotatingFile{path: path, rotation: rotation}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the file at f.path for appending. f.mu must be held, or f not
+// shared yet.
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size, f.opened = file, info.Size(), time.Now()
+	return nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	full := f.rotation.MaxBytes > 0 && f.size > 0 && f.size+int64(len(p)) > f.rotation.MaxBytes
+	old := f.rotation.Interval > 0 && time.Since(f.opened) >= f.rotation.Interval
+	if full || old {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate renames the current file with a timestamp suffix, opens a new one
+// and applies the retention. f.mu must be held.
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	rotated := f.path + "." + time.Now().UTC().Format(rotationTimeFormat)
+	if err := os.Rename(f.path, rotated); err != nil {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	if err := f.prune(); err != nil {
+		// Failing to delete old files doesn't prevent logging.
+		otel.Handle(err)
+	}
+	return nil
+}
+
+// prune deletes the rotated files past MaxBackups or MaxAge.
+func (f *rotatingFile) prune() error {
+	if f.rotation.MaxBackups <= 0 && f.rotation.MaxAge <= 0 {
+		return nil
+	}
+	rotated, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return err
+	}
+	rotated = slices.DeleteFunc(rotated, func(name string) bool {
+		_, err := time.Parse(rotationTimeFormat, name[len(f.path)+1:])
+		return err != nil
+	})
+	// Newest first.
+	slices.Sort(rotated)
+	slices.Reverse(rotated)
+
+	var errs []error
+	for i, name := range rotated {
+		expired := f.rotation.MaxBackups > 0 && i >= f.rotation.MaxBackups
+		if !expired && f.rotation.MaxAge > 0 {
+			info, err := os.Stat(name)
+			expired = err == nil && time.Since(info.ModTime()) > f.rotation.MaxAge
+		}
+		if expired {
+			if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
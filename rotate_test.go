@@ -0,0 +1,147 @@
+package telemetry
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rotatedFiles returns the contents of the rotated files of path, oldest
+// first.
+func rotatedFiles(t *testing.T, path string) []string {
+	t.Helper()
+	names, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(names)
+	var contents []string
+	for _, name := range names {
+		if _, err := time.Parse(rotationTimeFormat, strings.TrimPrefix(name, path+".")); err != nil {
+			continue
+		}
+		b, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, string(b))
+	}
+	return contents
+}
+
+func TestRotatingFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		rotation    LogRotation
+		writes      []string
+		wantCurrent string
+		wantRotated []string
+	}{
+		{
+			name:        "under the size limit",
+			rotation:    LogRotation{MaxBytes: 16},
+			writes:      []string{"aaaa\n", "bbbb\n"},
+			wantCurrent: "aaaa\nbbbb\n",
+		},
+		{
+			name:        "rotates before exceeding the size",
+			rotation:    LogRotation{MaxBytes: 8},
+			writes:      []string{"aaaa\n", "bbbb\n", "cccc\n"},
+			wantCurrent: "cccc\n",
+			wantRotated: []string{"aaaa\n", "bbbb\n"},
+		},
+		{
+			name:        "keeps oversized records whole",
+			rotation:    LogRotation{MaxBytes: 4},
+			writes:      []string{"aaaaaaaa\n", "bbbbbbbb\n"},
+			wantCurrent: "bbbbbbbb\n",
+			wantRotated: []string{"aaaaaaaa\n"},
+		},
+		{
+			name:        "keeps MaxBackups rotated files",
+			rotation:    LogRotation{MaxBytes: 4, MaxBackups: 2},
+			writes:      []string{"aaaa\n", "bbbb\n", "cccc\n", "dddd\n"},
+			wantCurrent: "dddd\n",
+			wantRotated: []string{"bbbb\n", "cccc\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "logs.jsonl")
+			f, err := openRotatingFile(path, tt.rotation)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range tt.writes {
+				if _, err := f.Write([]byte(w)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := f.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			current, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(current) != tt.wantCurrent {
+				t.Errorf("current file = %q, want %q", current, tt.wantCurrent)
+			}
+			if got := rotatedFiles(t, path); !slices.Equal(got, tt.wantRotated) {
+				t.Errorf("rotated files = %q, want %q", got, tt.wantRotated)
+			}
+		})
+	}
+}
+
+func TestRotatingFileInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.jsonl")
+	f, err := openRotatingFile(path, LogRotation{Interval: time.Hour, MaxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// A rotated file older than MaxAge, left by a previous run.
+	stale := path + "." + time.Now().Add(-48*time.Hour).UTC().Format(rotationTimeFormat)
+	if err := os.WriteFile(stale, []byte("stale\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(stale, time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	// A file that is not one of the rotated files.
+	other := path + ".bak"
+	if err := os.WriteFile(other, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Write([]byte("first\n"))
+	f.Write([]byte("second\n"))
+	if got := rotatedFiles(t, path); !slices.Equal(got, []string{"stale\n"}) {
+		t.Fatalf("rotated before the interval: %q", got)
+	}
+
+	f.mu.Lock()
+	f.opened = f.opened.Add(-time.Hour)
+	f.mu.Unlock()
+	f.Write([]byte("third\n"))
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(current) != "third\n" {
+		t.Errorf("current file = %q, want %q", current, "third\n")
+	}
+	if got := rotatedFiles(t, path); !slices.Equal(got, []string{"first\nsecond\n"}) {
+		t.Errorf("rotated files = %q, want %q", got, []string{"first\nsecond\n"})
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("file that is not a rotated file pruned: %v", err)
+	}
+}
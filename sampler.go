@@ -0,0 +1,196 @@
+package telemetry
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// WithSampler sets the sampler of the tracer provider. By default the
+// sampler is configured by the OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG
+// environment variables, which additionally accept "ratelimiting",
+// "parentbased_ratelimiting", "adaptive" and "parentbased_adaptive" with the
+// number of traces per second as argument. The ratio and adaptive samplers
+// record the sampling probability in the sampling.probability span attribute
+// and the "ot" tracestate entry. The rate limiting sampler, whose decisions
+// don't depend on the trace, only records its estimated probability in the
+// attribute of the spans it samples.
+func WithSampler(sampler trace.Sampler) Option {
+	return func(c *config) {
+		c.sampler = sampler
+	}
+}
+
+// RateLimitingSampler returns a sampler recording at most perSecond traces
+// per second on average, with bursts of up to burst traces. Unlike a ratio
+// based sampler it bounds the load on the collector during traffic spikes
+// while recording everything during quiet periods. Use it as the root
+// sampler of trace.ParentBased to keep traces complete. The probability it
+// records is estimated from the decisions of the previous second.
+func RateLimitingSampler(perSecond float64, burst int) trace.Sampler {
+	return &rateLimitingSampler{
+		rate:   perSecond,
+		burst:  float64(max(burst, 1)),
+		tokens: float64(max(burst, 1)),
+		last:   time.Now(),
+	}
+}
+
+type rateLimitingSampler struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	// The effective sampling probability is estimated from the decisions
+	// of the previous one-second window.
+	windowStart time.Time
+	seen        int
+	sampled     int
+	probability float64
+}
+
+func (s *rateLimitingSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	result := trace.SamplingResult{
+		Decision:   trace.Drop,
+		Tracestate: oteltrace.SpanContextFromContext(p.ParentContext).TraceState(),
+	}
+	ok, probability := s.take()
+	if ok {
+		result.Decision = trace.RecordAndSample
+	}
+	return estimatedProbability(result, probability)
+}
+
+// take consumes a token from the bucket if one is available, and returns
+// the estimated sampling probability.
+func (s *rateLimitingSampler) take() (bool, float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.windowStart) >= time.Second {
+		s.probability = 1
+		if s.seen > 0 {
+			s.probability = float64(s.sampled) / float64(s.seen)
+		}
+		s.windowStart, s.seen, s.sampled = now, 0, 0
+	}
+	s.seen++
+
+	s.tokens = min(s.burst, s.tokens+now.Sub(s.last).Seconds()*s.rate)
+	s.last = now
+	if s.tokens < 1 {
+		return false, s.probability
+	}
+	s.tokens--
+	s.sampled++
+	return true, s.probability
+}
+
+func (s *rateLimitingSampler) Description() string {
+	return fmt.Sprintf("RateLimitingSampler{%g/s,burst:%g}", s.rate, s.burst)
+}
+
+// HookSampler returns a sampler delegating the decision to fn, for bespoke
+// sampling logic that doesn't warrant a full trace.Sampler implementation.
+// The parent's trace state is propagated unchanged.
+func HookSampler(fn func(trace.SamplingParameters) trace.SamplingDecision) trace.Sampler {
+	return hookSampler(fn)
+}
+
+type hookSampler func(trace.SamplingParameters) trace.SamplingDecision
+
+func (fn hookSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	return trace.SamplingResult{
+		Decision:   fn(p),
+		Tracestate: oteltrace.SpanContextFromContext(p.ParentContext).TraceState(),
+	}
+}
+
+func (fn hookSampler) Description() string {
+	return "HookSampler"
+}
+
+// newSampler returns the sampler configured by cfg, or nil to keep the SDK
+// default. Sampling rules fall back to the configured sampler; they can't
+// wrap the samplers the SDK selects from OTEL_TRACES_SAMPLER, so they are
+// ignored when one is set.
+func newSampler(cfg config) trace.Sampler {
+	if len(cfg.samplingRules) == 0 {
+		return cfg.sampler
+	}
+	fallback := cfg.sampler
+	if fallback == nil {
+		if name := os.Getenv("OTEL_TRACES_SAMPLER"); name != "" {
+			otel.Handle(fmt.Errorf("telemetry: sampling rules ignored, OTEL_TRACES_SAMPLER %q is built by the SDK", name))
+			return nil
+		}
+		fallback = trace.AlwaysSample()
+	}
+	return trace.ParentBased(RuleBasedSampler(fallback, cfg.samplingRules...))
+}
+
+// envSampler returns the sampler selected by OTEL_TRACES_SAMPLER when it
+// names one of this package's samplers, or nil to let the SDK handle it.
+func envSampler() trace.Sampler {
+	name := os.Getenv("OTEL_TRACES_SAMPLER")
+	switch name {
+	case "traceidratio", "parentbased_traceidratio":
+		return envRatioSampler(name)
+	case "ratelimiting", "parentbased_ratelimiting", "adaptive", "parentbased_adaptive":
+	default:
+		return nil
+	}
+
+	rate := 100.0
+	if arg, ok := os.LookupEnv("OTEL_TRACES_SAMPLER_ARG"); ok {
+		v, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			otel.Handle(fmt.Errorf("telemetry: invalid OTEL_TRACES_SAMPLER_ARG %q: %w", arg, err))
+		} else {
+			rate = v
+		}
+	}
+
+	var sampler trace.Sampler
+	switch name {
+	case "adaptive", "parentbased_adaptive":
+		sampler = AdaptiveSampler(rate)
+	default:
+		sampler = RateLimitingSampler(rate, int(max(rate, 1)))
+	}
+	if strings.HasPrefix(name, "parentbased_") {
+		sampler = trace.ParentBased(sampler)
+	}
+	return sampler
+}
+
+// envRatioSampler returns the trace ID ratio sampler selected by name,
+// recording the sampling probability on spans.
+func envRatioSampler(name string) trace.Sampler {
+	ratio := 1.0
+	if arg, ok := os.LookupEnv("OTEL_TRACES_SAMPLER_ARG"); ok {
+		v, err := strconv.ParseFloat(arg, 64)
+		if err != nil || v < 0 || v > 1 {
+			otel.Handle(fmt.Errorf("telemetry: invalid OTEL_TRACES_SAMPLER_ARG %q", arg))
+		} else {
+			ratio = v
+		}
+	}
+
+	sampler := TraceIDRatioSampler(ratio)
+	if name == "parentbased_traceidratio" {
+		sampler = trace.ParentBased(sampler)
+	}
+	return sampler
+}
@@ -0,0 +1,71 @@
+package telemetry
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// adaptiveWindow is how often AdaptiveSampler adjusts its probability.
+const adaptiveWindow = time.Second
+
+// AdaptiveSampler returns a sampler aiming at perSecond sampled spans per
+// second whatever the traffic: every second, its sampling probability is set
+// to perSecond divided by the smoothed rate of the spans it saw, capped to
+// 1. The decision depends on the trace randomness and the probability is
+// recorded like TraceIDRatioSampler does. Used as the
+// root sampler of trace.ParentBased, it counts root spans, so perSecond is
+// then a number of traces per second.
+func AdaptiveSampler(perSecond float64) trace.Sampler {
+	return &adaptiveSampler{
+		target:      max(perSecond, 0),
+		windowStart: time.Now(),
+		probability: 1,
+	}
+}
+
+type adaptiveSampler struct {
+	target float64
+
+	mu          sync.Mutex
+	windowStart time.Time
+	seen        int
+	rate        float64
+	probability float64
+}
+
+func (s *adaptiveSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	return consistentSample(p, s.observe())
+}
+
+// observe counts a span and returns the current sampling probability,
+// adjusting it once a window has elapsed.
+func (s *adaptiveSampler) observe() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if elapsed := now.Sub(s.windowStart); elapsed >= adaptiveWindow {
+		observed := float64(s.seen) / elapsed.Seconds()
+		if s.rate == 0 {
+			s.rate = observed
+		} else {
+			// Smooth the rate so a single burst doesn't swing the
+			// probability back and forth.
+			s.rate = (s.rate + observed) / 2
+		}
+		s.probability = 1
+		if s.rate > s.target {
+			s.probability = s.target / s.rate
+		}
+		s.windowStart, s.seen = now, 0
+	}
+	s.seen++
+	return s.probability
+}
+
+func (s *adaptiveSampler) Description() string {
+	return fmt.Sprintf("AdaptiveSampler{%g/s}", s.target)
+}
@@ -0,0 +1,165 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// SamplingProbabilityKey is the span attribute holding the probability with
+// which the span's trace was sampled, so backends can extrapolate counts.
+const SamplingProbabilityKey = attribute.Key("sampling.probability")
+
+// thresholdMax is the number of distinct 56-bit randomness values, as used
+// by the OpenTelemetry tracestate sampling threshold.
+const thresholdMax = 1 << 56
+
+// TraceIDRatioSampler samples the given fraction of traces and records the
+// sampling probability on the spans it samples. Decisions follow the
+// consistent probability sampling of the OpenTelemetry specification: a
+// trace is sampled when its 56-bit randomness, the explicit "rv" value of the
+// "ot" tracestate entry or else the last 7 bytes of the trace ID, is at
+// least the rejection threshold of the fraction, which is recorded as the
+// "th" value of the entry. Samplers of any service agreeing on the fraction
+// thus keep the same traces.
+func TraceIDRatioSampler(fraction float64) trace.Sampler {
+	return ratioSampler(min(max(fraction, 0), 1))
+}
+
+type ratioSampler float64
+
+func (s ratioSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	return consistentSample(p, float64(s))
+}
+
+func (s ratioSampler) Description() string {
+	return fmt.Sprintf("TraceIDRatioSampler{%g}", float64(s))
+}
+
+// DynamicRatioSampler is like TraceIDRatioSampler, but calls ratio for the
+// fraction of traces to sample at every decision, so it can be changed at
+// runtime, e.g. from a feature flag.
+func DynamicRatioSampler(ratio func() float64) trace.Sampler {
+	return dynamicRatioSampler(ratio)
+}
+
+type dynamicRatioSampler func() float64
+
+func (fn dynamicRatioSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	return consistentSample(p, min(max(fn(), 0), 1))
+}
+
+func (fn dynamicRatioSampler) Description() string {
+	return "DynamicRatioSampler"
+}
+
+// consistentSample samples the trace of p with probability, comparing its
+// randomness with the threshold of probability, and records the threshold in
+// the tracestate of a sampled result, from which children and downstream
+// services inherit it.
+func consistentSample(p trace.SamplingParameters, probability float64) trace.SamplingResult {
+	res := trace.SamplingResult{
+		Decision:   trace.Drop,
+		Tracestate: oteltrace.SpanContextFromContext(p.ParentContext).TraceState(),
+	}
+	th := probabilityThreshold(probability)
+	if traceRandomness(p.TraceID, res.Tracestate) < th {
+		return res
+	}
+	res.Decision = trace.RecordAndSample
+	value := "th:" + strings.TrimRight(fmt.Sprintf("%014x", th), "0")
+	if value == "th:" {
+		value = "th:0"
+	}
+	res.Tracestate = withOTValue(res.Tracestate, "th:", value)
+	return res
+}
+
+// estimatedProbability records probability, estimated rather than used for a
+// decision consistent with the trace ID, as an attribute of a sampled
+// result. The threshold of an upstream sampler is removed from the
+// tracestate, as it no longer describes the sampling of the trace.
+func estimatedProbability(res trace.SamplingResult, probability float64) trace.SamplingResult {
+	res.Tracestate = withOTValue(res.Tracestate, "th:", "")
+	if res.Decision == trace.RecordAndSample && probability > 0 {
+		res.Attributes = append(res.Attributes, SamplingProbabilityKey.Float64(probability))
+	}
+	return res
+}
+
+// probabilityThreshold returns the rejection threshold of probability: the
+// randomness values below it are not sampled.
+func probabilityThreshold(probability float64) uint64 {
+	return thresholdMax - uint64(math.Round(probability*thresholdMax))
+}
+
+// traceRandomness returns the 56-bit randomness of a trace: the "rv" value
+// of the "ot" tracestate entry if valid, or else the last 7 bytes of its ID.
+func traceRandomness(id oteltrace.TraceID, ts oteltrace.TraceState) uint64 {
+	for _, f := range strings.Split(ts.Get("ot"), ";") {
+		if hex, ok := strings.CutPrefix(f, "rv:"); ok && len(hex) == 14 {
+			if rv, err := strconv.ParseUint(hex, 16, 64); err == nil {
+				return rv
+			}
+		}
+	}
+	return binary.BigEndian.Uint64(id[8:16]) & (thresholdMax - 1)
+}
+
+// withOTValue replaces the value of the "ot" tracestate entry starting with
+// prefix by value, or removes it if value is empty.
+func withOTValue(ts oteltrace.TraceState, prefix, value string) oteltrace.TraceState {
+	var fields []string
+	if value != "" {
+		fields = append(fields, value)
+	}
+	for _, f := range strings.Split(ts.Get("ot"), ";") {
+		if f != "" && !strings.HasPrefix(f, prefix) {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return ts.Delete("ot")
+	}
+	if updated, err := ts.Insert("ot", strings.Join(fields, ";")); err == nil {
+		return updated
+	}
+	return ts
+}
+
+// traceStateProbability returns the sampling probability recorded in ts.
+func traceStateProbability(ts oteltrace.TraceState) (float64, bool) {
+	for _, f := range strings.Split(ts.Get("ot"), ";") {
+		hex, ok := strings.CutPrefix(f, "th:")
+		if !ok || len(hex) == 0 || len(hex) > 14 {
+			continue
+		}
+		th, err := strconv.ParseUint(hex+strings.Repeat("0", 14-len(hex)), 16, 64)
+		if err != nil {
+			return 0, false
+		}
+		return float64(thresholdMax-th) / thresholdMax, true
+	}
+	return 0, false
+}
+
+// probabilityStamper is a span processor copying the sampling probability
+// from the span's tracestate to the sampling.probability attribute.
+type probabilityStamper struct{}
+
+func (probabilityStamper) OnStart(_ context.Context, s trace.ReadWriteSpan) {
+	if p, ok := traceStateProbability(s.SpanContext().TraceState()); ok {
+		s.SetAttributes(SamplingProbabilityKey.Float64(p))
+	}
+}
+
+func (probabilityStamper) OnEnd(trace.ReadOnlySpan)         {}
+func (probabilityStamper) Shutdown(context.Context) error   { return nil }
+func (probabilityStamper) ForceFlush(context.Context) error { return nil }
@@ -0,0 +1,116 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// traceWithRandomness returns a trace ID whose last 7 bytes are randomness.
+func traceWithRandomness(randomness uint64) oteltrace.TraceID {
+	id := oteltrace.TraceID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	for i := 15; i >= 9; i-- {
+		id[i] = byte(randomness)
+		randomness >>= 8
+	}
+	return id
+}
+
+// parentContext returns a context with a remote parent carrying tracestate.
+func parentContext(t *testing.T, tracestate string) context.Context {
+	t.Helper()
+	ts, err := oteltrace.ParseTraceState(tracestate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID:    oteltrace.TraceID{1},
+		SpanID:     oteltrace.SpanID{1},
+		TraceState: ts,
+		Remote:     true,
+	})
+	return oteltrace.ContextWithSpanContext(context.Background(), sc)
+}
+
+func TestTraceIDRatioSampler(t *testing.T) {
+	tests := []struct {
+		name           string
+		fraction       float64
+		randomness     uint64
+		tracestate     string
+		wantSampled    bool
+		wantTracestate string
+	}{
+		{"always", 1, 0, "", true, "ot=th:0"},
+		{"never", 0, thresholdMax - 1, "", false, ""},
+		{"half at the threshold", 0.5, 0x80000000000000, "", true, "ot=th:8"},
+		{"half below the threshold", 0.5, 0x7fffffffffffff, "", false, ""},
+		{"quarter", 0.25, 0xc0000000000000, "", true, "ot=th:c"},
+		{"tenth", 0.1, 0xf0000000000000, "", true, "ot=th:e6666666666666"},
+		{"explicit randomness", 0.5, 0, "ot=rv:80000000000000", true, "ot=th:8;rv:80000000000000"},
+		{"upstream threshold replaced", 0.5, 0xffffffffffffff, "ot=th:c;p:1,vendor=x", true, "ot=th:8;p:1,vendor=x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := TraceIDRatioSampler(tt.fraction).ShouldSample(trace.SamplingParameters{
+				ParentContext: parentContext(t, tt.tracestate),
+				TraceID:       traceWithRandomness(tt.randomness),
+			})
+			if got := res.Decision == trace.RecordAndSample; got != tt.wantSampled {
+				t.Errorf("sampled: got %v, want %v", got, tt.wantSampled)
+			}
+			if !tt.wantSampled {
+				return
+			}
+			if got := res.Tracestate.String(); got != tt.wantTracestate {
+				t.Errorf("got tracestate %q, want %q", got, tt.wantTracestate)
+			}
+			p, ok := traceStateProbability(res.Tracestate)
+			if !ok || p != tt.fraction {
+				t.Errorf("got probability %v (%v) from the tracestate, want %v", p, ok, tt.fraction)
+			}
+		})
+	}
+}
+
+func TestSamplersAgreeOnTraces(t *testing.T) {
+	// A trace sampled at a probability is sampled at any higher one.
+	for _, randomness := range []uint64{0, 1 << 20, 0x40000000000000, 0x80000000000000, 0xfffffffffffff0} {
+		id := traceWithRandomness(randomness)
+		sampled := false
+		for _, fraction := range []float64{0.01, 0.1, 0.25, 0.5, 0.75, 1} {
+			res := DynamicRatioSampler(func() float64 { return fraction }).ShouldSample(trace.SamplingParameters{
+				ParentContext: context.Background(),
+				TraceID:       id,
+			})
+			got := res.Decision == trace.RecordAndSample
+			if sampled && !got {
+				t.Errorf("randomness %x sampled below %v but not at it", randomness, fraction)
+			}
+			sampled = got
+		}
+		if !sampled {
+			t.Errorf("randomness %x not sampled at probability 1", randomness)
+		}
+	}
+}
+
+func TestRateLimitingSamplerEstimatesProbability(t *testing.T) {
+	res := RateLimitingSampler(10, 1).ShouldSample(trace.SamplingParameters{
+		ParentContext: parentContext(t, "ot=th:8;rv:80000000000000"),
+		TraceID:       traceWithRandomness(0),
+	})
+	if res.Decision != trace.RecordAndSample {
+		t.Fatalf("got decision %v, want RecordAndSample", res.Decision)
+	}
+	if got := res.Tracestate.String(); got != "ot=rv:80000000000000" {
+		t.Errorf("got tracestate %q, want the threshold removed", got)
+	}
+	want := []attribute.KeyValue{SamplingProbabilityKey.Float64(1)}
+	if len(res.Attributes) != 1 || res.Attributes[0] != want[0] {
+		t.Errorf("got attributes %v, want %v", res.Attributes, want)
+	}
+}
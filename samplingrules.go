@@ -0,0 +1,148 @@
+package telemetry
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SamplingRule samples the spans matching it at a fixed ratio.
+type SamplingRule struct {
+	// Key is the attribute the rule matches on, e.g. "http.route",
+	// "url.path" or "rpc.method". If empty the span name is matched.
+	Key string
+	// Pattern is matched against the attribute value or span name. A "*"
+	// matches any sequence of characters.
+	Pattern string
+	// Ratio is the fraction of matching traces to sample, from 0 to 1.
+	Ratio float64
+}
+
+// WithSamplingRules adds per-route or per-operation sampling rules, e.g. to
+// drop 99% of health checks while keeping every checkout. Rules are
+// evaluated in order and the first match decides; unmatched spans use the
+// configured sampler. Rules from the TELEMETRY_SAMPLING_RULES environment
+// variable are evaluated first.
+//
+// Rules only see the attributes a span starts with. The http.route attribute
+// is among them for Middleware wrapping an *http.ServeMux, and for the
+// telemetrychi (installed with Use), telemetryecho and telemetrygin
+// middleware. telemetryfiber and Middleware wrapping other handlers only set
+// it after routing, so match their url.path attribute instead.
+func WithSamplingRules(rules ...SamplingRule) Option {
+	return func(c *config) {
+		c.samplingRules = append(c.samplingRules, rules...)
+	}
+}
+
+// ParseSamplingRules parses a comma separated list of rules of the form
+// "<key>=<pattern>:<ratio>", where key "name" matches the span name, e.g.
+// "http.route=/healthz:0.01,name=POST /checkout:1".
+func ParseSamplingRules(s string) ([]SamplingRule, error) {
+	var rules []SamplingRule
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, rest, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("telemetry: invalid sampling rule %q", entry)
+		}
+		i := strings.LastIndex(rest, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("telemetry: sampling rule %q has no ratio", entry)
+		}
+		ratio, err := strconv.ParseFloat(rest[i+1:], 64)
+		if err != nil {
+			return nil, fmt.Errorf("telemetry: invalid ratio in sampling rule %q: %w", entry, err)
+		}
+		if key == "name" {
+			key = ""
+		}
+		rules = append(rules, SamplingRule{Key: key, Pattern: rest[:i], Ratio: ratio})
+	}
+	return rules, nil
+}
+
+// RuleBasedSampler returns a sampler applying the first rule matching a span
+// and delegating to fallback when none does.
+func RuleBasedSampler(fallback trace.Sampler, rules ...SamplingRule) trace.Sampler {
+	s := &ruleBasedSampler{fallback: fallback}
+	for _, r := range rules {
+		s.rules = append(s.rules, compiledRule{
+			SamplingRule: r,
+			sampler:      TraceIDRatioSampler(r.Ratio),
+		})
+	}
+	return s
+}
+
+type compiledRule struct {
+	SamplingRule
+	sampler trace.Sampler
+}
+
+func (r compiledRule) matches(p trace.SamplingParameters) bool {
+	if r.Key == "" {
+		return globMatch(r.Pattern, p.Name)
+	}
+	for _, kv := range p.Attributes {
+		if string(kv.Key) == r.Key {
+			return globMatch(r.Pattern, kv.Value.Emit())
+		}
+	}
+	return false
+}
+
+type ruleBasedSampler struct {
+	fallback trace.Sampler
+	rules    []compiledRule
+}
+
+func (s *ruleBasedSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	for _, r := range s.rules {
+		if r.matches(p) {
+			return r.sampler.ShouldSample(p)
+		}
+	}
+	return s.fallback.ShouldSample(p)
+}
+
+func (s *ruleBasedSampler) Description() string {
+	return fmt.Sprintf("RuleBasedSampler{rules:%d,fallback:%s}", len(s.rules), s.fallback.Description())
+}
+
+// envSamplingRules reads sampling rules from the environment variable key.
+func envSamplingRules(key string) []SamplingRule {
+	rules, err := ParseSamplingRules(os.Getenv(key))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return rules
+}
+
+// globMatch reports whether s matches pattern, where "*" matches any
+// sequence of characters, including none.
+func globMatch(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
@@ -0,0 +1,117 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestParseSamplingRules(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []SamplingRule
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{
+			in: "http.route=/healthz:0.01, name=POST /checkout:1",
+			want: []SamplingRule{
+				{Key: "http.route", Pattern: "/healthz", Ratio: 0.01},
+				{Key: "", Pattern: "POST /checkout", Ratio: 1},
+			},
+		},
+		{in: "url.path=/a:b:0.5", want: []SamplingRule{{Key: "url.path", Pattern: "/a:b", Ratio: 0.5}}},
+		{in: "/healthz:0.01", wantErr: true},
+		{in: "http.route=/healthz", wantErr: true},
+		{in: "http.route=/healthz:x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseSamplingRules(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteSamplingRulesWithServeMux(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(RuleBasedSampler(sdktrace.AlwaysSample(),
+			SamplingRule{Key: "http.route", Pattern: "/healthz", Ratio: 0})),
+		sdktrace.WithSpanProcessor(sr))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(prev)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(http.ResponseWriter, *http.Request) {})
+	mux.HandleFunc("GET /orders/{id}", func(http.ResponseWriter, *http.Request) {})
+	handler := Middleware(mux)
+
+	tests := []struct {
+		path        string
+		wantSampled bool
+		wantName    string
+	}{
+		{"/healthz", false, ""},
+		{"/orders/42", true, "GET /orders/{id}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			sr.Reset()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+			spans := sr.Ended()
+			if !tt.wantSampled {
+				if len(spans) != 0 {
+					t.Errorf("got %d sampled spans, want none", len(spans))
+				}
+				return
+			}
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			if got := spans[0].Name(); got != tt.wantName {
+				t.Errorf("got name %q, want %q", got, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestNewSamplerWithRules(t *testing.T) {
+	rules := []SamplingRule{{Key: "http.route", Pattern: "/healthz", Ratio: 0}}
+	tests := []struct {
+		name    string
+		sampler string
+		arg     string
+		want    string
+	}{
+		{"default fallback", "", "", "ParentBased{root:RuleBasedSampler{rules:1,fallback:AlwaysOnSampler}"},
+		{"package sampler", "traceidratio", "0.5", "ParentBased{root:RuleBasedSampler{rules:1,fallback:TraceIDRatioSampler{0.5}}"},
+		{"SDK sampler", "always_off", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_SAMPLER", tt.sampler)
+			t.Setenv("OTEL_TRACES_SAMPLER_ARG", tt.arg)
+			sampler := newSampler(newConfig([]Option{WithSamplingRules(rules...)}))
+			var got string
+			if sampler != nil {
+				got = sampler.Description()
+			}
+			if !strings.HasPrefix(got, tt.want) || (tt.want == "") != (got == "") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
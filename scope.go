@@ -0,0 +1,36 @@
+package telemetry
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer returns a tracer of the configured tracer provider for the
+// instrumentation scope name, usually the import path of the instrumented
+// package, at version. Libraries can call it instead of otel.Tracer; tracers
+// obtained before the setup start recording once it is done.
+//
+// The attrs are attached to the scope rather than to every span, e.g. to
+// let backends attribute the telemetry cost and errors to owning teams:
+//
+//	tracer := telemetry.Tracer("example.com/orders", "1.2.0",
+//		attribute.String("team", "checkout"))
+func Tracer(name, version string, attrs ...attribute.KeyValue) trace.Tracer {
+	return otel.GetTracerProvider().Tracer(name,
+		trace.WithInstrumentationVersion(version),
+		trace.WithInstrumentationAttributes(attrs...),
+		trace.WithSchemaURL(semconv.SchemaURL))
+}
+
+// Meter returns a meter of the configured meter provider for the
+// instrumentation scope name at version with the scope attributes attrs,
+// like Tracer.
+func Meter(name, version string, attrs ...attribute.KeyValue) metric.Meter {
+	return otel.GetMeterProvider().Meter(name,
+		metric.WithInstrumentationVersion(version),
+		metric.WithInstrumentationAttributes(attrs...),
+		metric.WithSchemaURL(semconv.SchemaURL))
+}
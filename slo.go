@@ -0,0 +1,261 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+const (
+	// sloSamples is the number of times per window the counters of an SLO
+	// are read.
+	sloSamples = 60
+	// sloSampleTimeout bounds a read of the counters.
+	sloSampleTimeout = 10 * time.Second
+)
+
+// SLO declares a service level objective over an existing pair of counters,
+// such as the telemetry.http.server.request.count and
+// telemetry.http.server.error.count metrics of Middleware.
+type SLO struct {
+	// Name identifies the objective in the emitted metrics.
+	Name string
+	// Target is the fraction of events that must succeed, e.g. 0.999.
+	Target float64
+	// Window is the period over which the burn rate and the error budget
+	// are computed.
+	Window time.Duration
+	// TotalMetric is the name of the counter of all events. The count of a
+	// histogram, such as http.server.request.duration, can be used as well.
+	TotalMetric string
+	// ErrorMetric is the name of the counter of failed events.
+	ErrorMetric string
+	// Attributes restricts both metrics to the data points carrying all of
+	// them, e.g. the http.route of a single endpoint.
+	Attributes []attribute.KeyValue
+}
+
+// SLOTracker reports the error budget of an SLO from the values of its
+// counters.
+type SLOTracker struct {
+	slo          SLO
+	registration metric.Registration
+	collect      func(context.Context) (metricdata.ResourceMetrics, error)
+	stop         chan struct{}
+	done         chan struct{}
+
+	mu      sync.Mutex
+	samples []sloSample
+}
+
+// sloSample is a reading of the cumulative values of the counters of an SLO.
+type sloSample struct {
+	at          time.Time
+	total, errs float64
+}
+
+// NewSLOTracker declares slo on the given meter provider. The tracker emits
+// the slo.burn_rate and slo.error_budget.remaining gauges, computed from the
+// increase of the counters of slo during the last slo.Window. The counters
+// are read from the pipeline set up by this package, like SnapshotMetrics
+// does, 60 times per window, so the gauges lag behind the counters by up to
+// a sixtieth of the window.
+func NewSLOTracker(mp metric.MeterProvider, slo SLO) (*SLOTracker, error) {
+	if slo.Name == "" {
+		return nil, errors.New("telemetry: SLO name is required")
+	}
+	if slo.TotalMetric == "" || slo.ErrorMetric == "" {
+		return nil, fmt.Errorf("telemetry: SLO %q requires a total and an error metric", slo.Name)
+	}
+	if slo.Target <= 0 || slo.Target >= 1 {
+		return nil, fmt.Errorf("telemetry: SLO %q target must be between 0 and 1, got %v", slo.Name, slo.Target)
+	}
+	if slo.Window < sloSamples*time.Millisecond {
+		return nil, fmt.Errorf("telemetry: SLO %q window %v is too short", slo.Name, slo.Window)
+	}
+
+	t := &SLOTracker{
+		slo:     slo,
+		collect: SnapshotMetrics,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	meter := mp.Meter(instrumentationName)
+	burnRate, err := meter.Float64ObservableGauge("slo.burn_rate",
+		metric.WithUnit("1"),
+		metric.WithDescription("Rate at which the SLO error budget is consumed; 1 means exactly on budget."))
+	if err != nil {
+		return nil, err
+	}
+	remaining, err := meter.Float64ObservableGauge("slo.error_budget.remaining",
+		metric.WithUnit("1"),
+		metric.WithDescription("Fraction of the SLO error budget of the window left; negative once overspent."))
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := metric.WithAttributes(
+		attribute.String("slo.name", slo.Name),
+		attribute.Float64("slo.target", slo.Target),
+		attribute.String("slo.window", slo.Window.String()),
+	)
+	t.registration, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveFloat64(burnRate, t.BurnRate(), attrs)
+		o.ObserveFloat64(remaining, t.RemainingBudget(), attrs)
+		return nil
+	}, burnRate, remaining)
+	if err != nil {
+		return nil, err
+	}
+	go t.run(slo.Window / sloSamples)
+	return t, nil
+}
+
+// BurnRate returns the error ratio of the events in the window divided by the
+// error budget (1 - Target).
+func (t *SLOTracker) BurnRate() float64 {
+	total, errs := t.window()
+	if total == 0 {
+		return 0
+	}
+	return (errs / total) / (1 - t.slo.Target)
+}
+
+// RemainingBudget returns the fraction of the error budget of the window
+// left: 1 minus the failed events in the window divided by the failures the
+// target allows for the events of the window. It is negative once the budget
+// is overspent.
+func (t *SLOTracker) RemainingBudget() float64 {
+	total, errs := t.window()
+	if total == 0 {
+		return 1
+	}
+	allowed := (1 - t.slo.Target) * total
+	return 1 - errs/allowed
+}
+
+// Unregister stops reporting the SLO metrics.
+func (t *SLOTracker) Unregister() error {
+	select {
+	case <-t.stop:
+	default:
+		close(t.stop)
+	}
+	<-t.done
+	return t.registration.Unregister()
+}
+
+// run reads the counters every interval until Unregister.
+func (t *SLOTracker) run(interval time.Duration) {
+	defer close(t.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), sloSampleTimeout)
+		rm, err := t.collect(ctx)
+		cancel()
+		switch {
+		case err == nil:
+			t.sample(time.Now(), &rm)
+		case !errors.Is(err, ErrNotInitialized):
+			otel.Handle(err)
+		}
+
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sample records the values of the counters in rm, read at now, keeping the
+// newest sample at least a window old as the start of the window.
+func (t *SLOTracker) sample(now time.Time, rm *metricdata.ResourceMetrics) {
+	s := sloSample{
+		at:    now,
+		total: sloSum(rm, t.slo.TotalMetric, t.slo.Attributes),
+		errs:  sloSum(rm, t.slo.ErrorMetric, t.slo.Attributes),
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n := len(t.samples); n > 0 && (s.total < t.samples[n-1].total || s.errs < t.samples[n-1].errs) {
+		// The counters restarted, such as with a new pipeline.
+		t.samples = t.samples[:0]
+	}
+	t.samples = append(t.samples, s)
+	start := 0
+	for i := 1; i < len(t.samples) && !t.samples[i].at.After(now.Add(-t.slo.Window)); i++ {
+		start = i
+	}
+	t.samples = t.samples[start:]
+}
+
+// window returns the increase of the counters during the window.
+func (t *SLOTracker) window() (total, errs float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.samples) < 2 {
+		return 0, 0
+	}
+	first, last := t.samples[0], t.samples[len(t.samples)-1]
+	return last.total - first.total, last.errs - first.errs
+}
+
+// sloSum returns the sum of the data points of the counter or histogram name
+// in rm that carry all of attrs.
+func sloSum(rm *metricdata.ResourceMetrics, name string, attrs []attribute.KeyValue) float64 {
+	matches := func(set attribute.Set) bool {
+		for _, kv := range attrs {
+			if v, ok := set.Value(kv.Key); !ok || v != kv.Value {
+				return false
+			}
+		}
+		return true
+	}
+
+	var sum float64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					if matches(dp.Attributes) {
+						sum += float64(dp.Value)
+					}
+				}
+			case metricdata.Sum[float64]:
+				for _, dp := range data.DataPoints {
+					if matches(dp.Attributes) {
+						sum += dp.Value
+					}
+				}
+			case metricdata.Histogram[int64]:
+				for _, dp := range data.DataPoints {
+					if matches(dp.Attributes) {
+						sum += float64(dp.Count)
+					}
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					if matches(dp.Attributes) {
+						sum += float64(dp.Count)
+					}
+				}
+			}
+		}
+	}
+	return sum
+}
@@ -0,0 +1,119 @@
+package telemetry
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// sloMetrics returns the cumulative request and error counters of route.
+func sloMetrics(requests, errs int64) *metricdata.ResourceMetrics {
+	counter := func(name string, value int64) metricdata.Metrics {
+		return metricdata.Metrics{Name: name, Data: metricdata.Sum[int64]{
+			IsMonotonic: true,
+			DataPoints: []metricdata.DataPoint[int64]{
+				{Attributes: attribute.NewSet(attribute.String("http.route", "/orders")), Value: value},
+				{Attributes: attribute.NewSet(attribute.String("http.route", "/healthz")), Value: 1000},
+			},
+		}}
+	}
+	return &metricdata.ResourceMetrics{ScopeMetrics: []metricdata.ScopeMetrics{{
+		Metrics: []metricdata.Metrics{
+			counter("telemetry.http.server.request.count", requests),
+			counter("telemetry.http.server.error.count", errs),
+		},
+	}}}
+}
+
+func TestSLOTracker(t *testing.T) {
+	type reading struct {
+		after          time.Duration
+		requests, errs int64
+	}
+	tests := []struct {
+		name          string
+		readings      []reading
+		wantBurnRate  float64
+		wantRemaining float64
+	}{
+		{
+			name:          "no events",
+			readings:      []reading{{0, 0, 0}, {time.Minute, 0, 0}},
+			wantBurnRate:  0,
+			wantRemaining: 1,
+		},
+		{
+			name:          "on budget",
+			readings:      []reading{{0, 0, 0}, {time.Minute, 1000, 10}},
+			wantBurnRate:  1,
+			wantRemaining: 0,
+		},
+		{
+			name:          "quarter of the budget",
+			readings:      []reading{{0, 0, 0}, {time.Minute, 2000, 5}},
+			wantBurnRate:  0.25,
+			wantRemaining: 0.75,
+		},
+		{
+			name:          "overspent",
+			readings:      []reading{{0, 0, 0}, {time.Minute, 100, 3}},
+			wantBurnRate:  3,
+			wantRemaining: -2,
+		},
+		{
+			name: "events before the window",
+			readings: []reading{
+				{0, 0, 0},
+				{30 * time.Minute, 100, 50},
+				{61 * time.Minute, 1100, 55},
+				{90 * time.Minute, 2100, 60},
+			},
+			wantBurnRate:  0.5,
+			wantRemaining: 0.5,
+		},
+		{
+			name:          "counters restarted",
+			readings:      []reading{{0, 0, 0}, {time.Minute, 5000, 500}, {2 * time.Minute, 0, 0}, {3 * time.Minute, 1000, 1}},
+			wantBurnRate:  0.1,
+			wantRemaining: 0.9,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker, err := NewSLOTracker(noop.NewMeterProvider(), SLO{
+				Name:        "orders",
+				Target:      0.99,
+				Window:      time.Hour,
+				TotalMetric: "telemetry.http.server.request.count",
+				ErrorMetric: "telemetry.http.server.error.count",
+				Attributes:  []attribute.KeyValue{attribute.String("http.route", "/orders")},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tracker.Unregister()
+
+			start := time.Now()
+			for _, r := range tt.readings {
+				tracker.sample(start.Add(r.after), sloMetrics(r.requests, r.errs))
+			}
+			if got := tracker.BurnRate(); math.Abs(got-tt.wantBurnRate) > 1e-9 {
+				t.Errorf("BurnRate() = %v, want %v", got, tt.wantBurnRate)
+			}
+			if got := tracker.RemainingBudget(); math.Abs(got-tt.wantRemaining) > 1e-9 {
+				t.Errorf("RemainingBudget() = %v, want %v", got, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestSLOTrackerRequiresMetrics(t *testing.T) {
+	_, err := NewSLOTracker(noop.NewMeterProvider(), SLO{Name: "orders", Target: 0.99, Window: time.Hour})
+	if err == nil {
+		t.Fatal("got no error for an SLO without metrics")
+	}
+}
@@ -0,0 +1,17 @@
+package telemetry
+
+import (
+	"log/slog"
+
+	"go.opentelemetry.io/contrib/bridges/otelslog"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// NewSlogHandler returns a slog.Handler that emits records through the global
+// logger provider. Levels are mapped to OpenTelemetry severities, and records
+// logged with a context carrying a span (e.g. slog.InfoContext) are
+// correlated with it through their trace and span IDs.
+func NewSlogHandler() slog.Handler {
+	return otelslog.NewHandler(instrumentationName,
+		otelslog.WithLoggerProvider(global.GetLoggerProvider()))
+}
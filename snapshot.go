@@ -0,0 +1,42 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// SnapshotMetrics returns the current aggregated value of every metric,
+// independently of the export schedule, e.g. for debug endpoints and support
+// bundles. Values are cumulative since the pipeline was set up.
+func SnapshotMetrics(ctx context.Context) (metricdata.ResourceMetrics, error) {
+	providers.Lock()
+	reader, clock := providers.snapshot, providers.clock
+	providers.Unlock()
+
+	var rm metricdata.ResourceMetrics
+	if reader == nil {
+		return rm, ErrNotInitialized
+	}
+	if err := reader.Collect(ctx, &rm); err != nil {
+		return rm, err
+	}
+	if clock != nil {
+		stampMetrics(&rm, clock.Now())
+	}
+	return rm, nil
+}
+
+// WriteMetricsSnapshot writes the metrics returned by SnapshotMetrics to w as
+// indented JSON.
+func WriteMetricsSnapshot(ctx context.Context, w io.Writer) error {
+	rm, err := SnapshotMetrics(ctx)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(rm)
+}
@@ -0,0 +1,101 @@
+package telemetry
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// idPlaceholder replaces identifier path segments in normalized span names.
+const idPlaceholder = "{id}"
+
+// WithSpanNameNormalizer rewrites span names with fn before export, to keep
+// high-cardinality names such as raw URLs out of the backend's operation
+// list. A nil fn uses NormalizeSpanName. Setting the
+// TELEMETRY_NORMALIZE_SPAN_NAMES environment variable to true enables
+// NormalizeSpanName by default.
+func WithSpanNameNormalizer(fn func(name string) string) Option {
+	return func(c *config) {
+		if fn == nil {
+			fn = NormalizeSpanName
+		}
+		c.spanNameNormalizer = fn
+	}
+}
+
+// NormalizeSpanName turns the URL paths in name into templates: query
+// strings are removed and path segments that look like identifiers (numbers,
+// UUIDs and long hexadecimal strings) are replaced with "{id}", so
+// "GET /users/42/orders?page=2" becomes "GET /users/{id}/orders".
+func NormalizeSpanName(name string) string {
+	words := strings.Split(name, " ")
+	for i, w := range words {
+		if strings.HasPrefix(w, "/") {
+			words[i] = normalizePath(w)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func normalizePath(p string) string {
+	p, _, _ = strings.Cut(p, "?")
+	p, _, _ = strings.Cut(p, "#")
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		if isIdentifier(s) {
+			segments[i] = idPlaceholder
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// isIdentifier reports whether a path segment looks like a generated
+// identifier rather than part of the route.
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	if strings.Trim(s, "0123456789") == "" {
+		return true
+	}
+	if len(s) == 36 && s[8] == '-' && s[13] == '-' && s[18] == '-' && s[23] == '-' {
+		return isHex(strings.ReplaceAll(s, "-", ""))
+	}
+	return len(s) >= 16 && isHex(s)
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// spanNameNormalizer is a span exporter renaming spans with normalize before
+// passing them to the wrapped exporter.
+type spanNameNormalizer struct {
+	trace.SpanExporter
+	normalize func(string) string
+}
+
+func (n *spanNameNormalizer) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	renamed := make([]trace.ReadOnlySpan, len(spans))
+	for i, s := range spans {
+		renamed[i] = s
+		if name := n.normalize(s.Name()); name != s.Name() {
+			renamed[i] = renamedSpan{ReadOnlySpan: s, name: name}
+		}
+	}
+	return n.SpanExporter.ExportSpans(ctx, renamed)
+}
+
+// renamedSpan overrides the name of a finished span.
+type renamedSpan struct {
+	trace.ReadOnlySpan
+	name string
+}
+
+func (s renamedSpan) Name() string { return s.name }
@@ -0,0 +1,126 @@
+package telemetry
+
+import "strings"
+
+// SanitizeSQL replaces the string and numeric literals of query with "?",
+// so statements can be attached to spans without leaking the data they
+// carry: "SELECT * FROM users WHERE email = 'a@b.c' AND age > 30" becomes
+// "SELECT * FROM users WHERE email = ? AND age > ?". Identifiers, quoted or
+// not, bind parameters such as $1 and comments are kept, and PostgreSQL
+// dollar-quoted and E'...' strings are replaced as a whole. Backslashes only
+// escape quotes in E'...' strings, as in standard SQL.
+func SanitizeSQL(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'':
+			i = skipQuoted(query, i, '\'', false)
+			b.WriteByte('?')
+		case (c == 'E' || c == 'e') && i+1 < len(query) && query[i+1] == '\'' && (i == 0 || !isIdentByte(query[i-1]) && !isDigit(query[i-1])):
+			// PostgreSQL escape string, in which backslashes escape.
+			i = skipQuoted(query, i+1, '\'', true)
+			b.WriteByte('?')
+		case c == '"' || c == '`':
+			end := skipQuoted(query, i, c, false)
+			b.WriteString(query[i:end])
+			i = end
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end
+		case c == '$':
+			if tag, ok := dollarTag(query[i:]); ok {
+				end := strings.Index(query[i+len(tag):], tag)
+				if end < 0 {
+					i = len(query)
+				} else {
+					i += len(tag) + end + len(tag)
+				}
+				b.WriteByte('?')
+				continue
+			}
+			// Bind parameter, e.g. $1.
+			j := i + 1
+			for j < len(query) && isDigit(query[j]) {
+				j++
+			}
+			b.WriteString(query[i:j])
+			i = j
+		case c == '0' && i+1 < len(query) && (query[i+1] == 'x' || query[i+1] == 'X'):
+			j := i + 2
+			for j < len(query) && (isDigit(query[j]) || isIdentByte(query[j])) {
+				j++
+			}
+			b.WriteByte('?')
+			i = j
+		case isDigit(c) || (c == '.' && i+1 < len(query) && isDigit(query[i+1])):
+			j := i
+			for j < len(query) && (isDigit(query[j]) || query[j] == '.' || query[j] == 'e' || query[j] == 'E' ||
+				((query[j] == '+' || query[j] == '-') && (query[j-1] == 'e' || query[j-1] == 'E'))) {
+				j++
+			}
+			b.WriteByte('?')
+			i = j
+		case isIdentByte(c):
+			j := i
+			for j < len(query) && (isIdentByte(query[j]) || isDigit(query[j])) {
+				j++
+			}
+			b.WriteString(query[i:j])
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// skipQuoted returns the index just past the quoted section starting at
+// query[i], where a doubled quote is an escaped quote. Backslashes escape
+// the next character only if backslash is set, as in PostgreSQL E'...'
+// strings; elsewhere the standard treats them as plain characters.
+func skipQuoted(query string, i int, quote byte, backslash bool) int {
+	for j := i + 1; j < len(query); j++ {
+		if backslash && query[j] == '\\' {
+			j++
+			continue
+		}
+		if query[j] == quote {
+			if j+1 < len(query) && query[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(query)
+}
+
+// dollarTag returns the opening tag of a dollar-quoted string, such as $$
+// or $body$, at the start of s.
+func dollarTag(s string) (string, bool) {
+	for j := 1; j < len(s); j++ {
+		switch {
+		case s[j] == '$':
+			return s[:j+1], true
+		case isIdentByte(s[j]) || (j > 1 && isDigit(s[j])):
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || c >= 0x80
+}
@@ -0,0 +1,74 @@
+package telemetry
+
+import "testing"
+
+func TestSanitizeSQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "literals",
+			query: "SELECT * FROM users WHERE email = 'a@b.c' AND age > 30",
+			want:  "SELECT * FROM users WHERE email = ? AND age > ?",
+		},
+		{
+			name:  "trailing backslash",
+			query: `WHERE p = 'C:\' AND pw = 'secret'`,
+			want:  "WHERE p = ? AND pw = ?",
+		},
+		{
+			name:  "doubled quote",
+			query: "WHERE name = 'O''Brien' AND pw = 'secret'",
+			want:  "WHERE name = ? AND pw = ?",
+		},
+		{
+			name:  "escape string",
+			query: `WHERE name = E'O\'Brien' AND pw = e'sec\\ret'`,
+			want:  "WHERE name = ? AND pw = ?",
+		},
+		{
+			name:  "identifier ending in e",
+			query: `SELECT note'x' FROM t WHERE type = 'a\'`,
+			want:  "SELECT note? FROM t WHERE type = ?",
+		},
+		{
+			name:  "quoted identifiers",
+			query: "SELECT \"user\".\"e'mail\", `order` FROM \"user\" WHERE id = 7",
+			want:  "SELECT \"user\".\"e'mail\", `order` FROM \"user\" WHERE id = ?",
+		},
+		{
+			name:  "numbers",
+			query: "UPDATE t SET a = 1.5e-3, b = .25, c = 0xFF WHERE id = -42",
+			want:  "UPDATE t SET a = ?, b = ?, c = ? WHERE id = -?",
+		},
+		{
+			name:  "bind parameters and identifiers with digits",
+			query: "SELECT col1 FROM t2 WHERE a = $1 AND b = ?",
+			want:  "SELECT col1 FROM t2 WHERE a = $1 AND b = ?",
+		},
+		{
+			name:  "dollar quoting",
+			query: "SELECT $$secret$$, $tag$it's$tag$ FROM t",
+			want:  "SELECT ?, ? FROM t",
+		},
+		{
+			name:  "comments",
+			query: "SELECT 1 -- 'keep'\nFROM t",
+			want:  "SELECT ? -- 'keep'\nFROM t",
+		},
+		{
+			name:  "unterminated string",
+			query: "WHERE pw = 'secret",
+			want:  "WHERE pw = ?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeSQL(tt.query); got != tt.want {
+				t.Errorf("SanitizeSQL(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
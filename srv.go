@@ -0,0 +1,154 @@
+package telemetry
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+const (
+	// srvScheme is the gRPC target scheme of the SRV resolver.
+	srvScheme = "telemetry-srv"
+	// defaultSRVRefresh is how often the SRV records are resolved again.
+	defaultSRVRefresh = 30 * time.Second
+	// minSRVResolveInterval throttles the resolutions gRPC asks for when
+	// connections fail.
+	minSRVResolveInterval = 5 * time.Second
+)
+
+// WithSRVDiscovery makes SetupOTelSDKGrpc discover the collectors from the
+// DNS SRV records of name, such as "_otlp._tcp.collectors.example.com",
+// instead of connecting to the endpoint. The records are resolved again
+// every refresh, 30s if zero, and whenever the connection fails, so
+// collectors can move without reconfiguring the services. The connection
+// goes to a reachable collector of the lowest priority, picked by weight.
+// An https:// prefix enables TLS like WithEndpoint; the certificates of the
+// collectors must then be valid for the domain of name, e.g.
+// collectors.example.com. It defaults to TELEMETRY_COLLECTOR_SRV and
+// TELEMETRY_COLLECTOR_SRV_REFRESH.
+func WithSRVDiscovery(name string, refresh time.Duration) Option {
+	return func(c *config) {
+		c.collectorSRV = name
+		c.collectorSRVRefresh = refresh
+	}
+}
+
+// srvAuthority returns the domain of the SRV record name, without its
+// service and protocol labels.
+func srvAuthority(name string) string {
+	for {
+		label, rest, ok := strings.Cut(name, ".")
+		if !ok || !strings.HasPrefix(label, "_") {
+			return strings.TrimSuffix(name, ".")
+		}
+		name = rest
+	}
+}
+
+// srvBuilder builds the resolvers of the srvScheme targets.
+type srvBuilder struct {
+	refresh time.Duration
+	// lookup resolves a name into addresses; lookupSRV if nil.
+	lookup func(ctx context.Context, name string) ([]resolver.Address, error)
+}
+
+func (b *srvBuilder) Scheme() string {
+	return srvScheme
+}
+
+func (b *srvBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	refresh := b.refresh
+	if refresh <= 0 {
+		refresh = defaultSRVRefresh
+	}
+	lookup := b.lookup
+	if lookup == nil {
+		lookup = lookupSRV
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &srvResolver{
+		name:    target.Endpoint(),
+		cc:      cc,
+		refresh: refresh,
+		lookup:  lookup,
+		now:     make(chan struct{}, 1),
+		cancel:  cancel,
+	}
+	r.wg.Add(1)
+	go r.watch(ctx)
+	return r, nil
+}
+
+// srvResolver resolves the SRV records of a name into the addresses of a
+// gRPC connection, on an interval and on demand.
+type srvResolver struct {
+	name    string
+	cc      resolver.ClientConn
+	refresh time.Duration
+	lookup  func(ctx context.Context, name string) ([]resolver.Address, error)
+	now     chan struct{}
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func (r *srvResolver) watch(ctx context.Context) {
+	defer r.wg.Done()
+	for {
+		resolved := time.Now()
+		addrs, err := r.lookup(ctx, r.name)
+		if err != nil {
+			r.cc.ReportError(err)
+		} else {
+			r.cc.UpdateState(resolver.State{Addresses: addrs})
+		}
+
+		timer := time.NewTimer(r.refresh)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-r.now:
+			timer.Stop()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(minSRVResolveInterval - time.Since(resolved)):
+			}
+		case <-timer.C:
+		}
+	}
+}
+
+// ResolveNow resolves the records again without waiting for the interval.
+func (r *srvResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.now <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops the resolution.
+func (r *srvResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// lookupSRV resolves the SRV records of name into addresses, ordered by
+// priority and shuffled by weight within a priority, as RFC 2782 specifies.
+func lookupSRV(ctx context.Context, name string) ([]resolver.Address, error) {
+	_, records, err := net.DefaultResolver.LookupSRV(ctx, "", "", name)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]resolver.Address, 0, len(records))
+	for _, rec := range records {
+		host := strings.TrimSuffix(rec.Target, ".")
+		addrs = append(addrs, resolver.Address{Addr: net.JoinHostPort(host, strconv.Itoa(int(rec.Port)))})
+	}
+	return addrs, nil
+}
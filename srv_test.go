@@ -0,0 +1,166 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/resolver"
+)
+
+// fakeSRV serves the records of a lookup, counting the lookups.
+type fakeSRV struct {
+	mu      sync.Mutex
+	addrs   []string
+	err     error
+	lookups int
+}
+
+func (f *fakeSRV) set(err error, addrs ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.addrs, f.err = addrs, err
+}
+
+func (f *fakeSRV) lookup(context.Context, string) ([]resolver.Address, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lookups++
+	if f.err != nil {
+		return nil, f.err
+	}
+	var addrs []resolver.Address
+	for _, a := range f.addrs {
+		addrs = append(addrs, resolver.Address{Addr: a})
+	}
+	return addrs, nil
+}
+
+// resolverUpdates records the addresses and errors a resolver reports.
+type resolverUpdates struct {
+	resolver.ClientConn
+	updates chan []string
+	errs    chan error
+}
+
+func (c *resolverUpdates) UpdateState(s resolver.State) error {
+	var addrs []string
+	for _, a := range s.Addresses {
+		addrs = append(addrs, a.Addr)
+	}
+	c.updates <- addrs
+	return nil
+}
+
+func (c *resolverUpdates) ReportError(err error) {
+	c.errs <- err
+}
+
+func TestSRVAuthority(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"_otlp._tcp.collectors.example.com", "collectors.example.com"},
+		{"_otlp._tcp.collectors.example.com.", "collectors.example.com"},
+		{"collectors.example.com", "collectors.example.com"},
+		{"_otlp", "_otlp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := srvAuthority(tt.name); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSRVResolverRefreshes(t *testing.T) {
+	srv := &fakeSRV{}
+	srv.set(nil, "10.0.0.1:4317", "10.0.0.2:4317")
+	cc := &resolverUpdates{updates: make(chan []string, 8), errs: make(chan error, 8)}
+	b := &srvBuilder{refresh: 50 * time.Millisecond, lookup: srv.lookup}
+	r, err := b.Build(resolver.Target{URL: url.URL{Scheme: srvScheme, Path: "/_otlp._tcp.example.com"}}, cc, resolver.BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	next := func() ([]string, error) {
+		t.Helper()
+		select {
+		case addrs := <-cc.updates:
+			return addrs, nil
+		case err := <-cc.errs:
+			return nil, err
+		case <-time.After(5 * time.Second):
+			t.Fatal("no resolution")
+			return nil, nil
+		}
+	}
+	if got, err := next(); err != nil || !slices.Equal(got, []string{"10.0.0.1:4317", "10.0.0.2:4317"}) {
+		t.Errorf("first resolution = %v, %v", got, err)
+	}
+
+	lookupErr := errors.New("no such host")
+	srv.set(lookupErr)
+	if _, err := next(); !errors.Is(err, lookupErr) {
+		t.Errorf("failed resolution reported %v, want %v", err, lookupErr)
+	}
+
+	srv.set(nil, "10.0.0.3:4317")
+	if got, err := next(); err != nil || !slices.Equal(got, []string{"10.0.0.3:4317"}) {
+		t.Errorf("moved collector resolved to %v, %v", got, err)
+	}
+}
+
+func TestSRVResolverThrottlesResolveNow(t *testing.T) {
+	srv := &fakeSRV{}
+	srv.set(nil, "10.0.0.1:4317")
+	cc := &resolverUpdates{updates: make(chan []string, 8), errs: make(chan error, 8)}
+	b := &srvBuilder{refresh: time.Hour, lookup: srv.lookup}
+	r, err := b.Build(resolver.Target{URL: url.URL{Scheme: srvScheme, Path: "/_otlp._tcp.example.com"}}, cc, resolver.BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	<-cc.updates
+
+	for range 10 {
+		r.ResolveNow(resolver.ResolveNowOptions{})
+	}
+	time.Sleep(100 * time.Millisecond)
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.lookups != 1 {
+		t.Errorf("%d lookups within the minimum interval, want 1", srv.lookups)
+	}
+}
+
+func TestSRVResolverConnects(t *testing.T) {
+	srv := &fakeSRV{}
+	srv.set(nil, unreachableAddr(t), startCollector(t))
+	conn, err := grpc.NewClient(srvScheme+":///_otlp._tcp.example.com",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithResolvers(&srvBuilder{lookup: srv.lookup}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn.Connect()
+	for state := conn.GetState(); state != connectivity.Ready; state = conn.GetState() {
+		if !conn.WaitForStateChange(ctx, state) {
+			t.Fatalf("connection not ready: %v", state)
+		}
+	}
+}
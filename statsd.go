@@ -0,0 +1,266 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// statsdMaxPacket is the largest UDP datagram read by the StatsD
+	// listener.
+	statsdMaxPacket = 65535
+	// statsdMaxInstruments bounds the metric names a StatsD listener
+	// creates instruments for.
+	statsdMaxInstruments = 1000
+	// statsdMaxGauges bounds the gauge series whose value a StatsD listener
+	// keeps for relative updates.
+	statsdMaxGauges = 10000
+	// statsdGaugeIdle is how long a gauge series is kept without updates
+	// once statsdMaxGauges is reached.
+	statsdGaugeIdle = 10 * time.Minute
+)
+
+// StatsDListener receives StatsD metrics over UDP and records them on a
+// meter provider, so legacy components emitting StatsD ride the same
+// pipeline. Counters (c) become counters, gauges (g) become gauges, and
+// timers (ms), histograms (h) and distributions (d) become histograms.
+// Sample rates are honored, and DogStatsD tags ("|#key:value,...") become
+// attributes. Sets are not supported and are dropped.
+//
+// As packets can come from anyone able to reach the port, the listener
+// creates instruments for at most 1000 metric names and keeps the values of
+// at most 10000 gauge series for relative updates ("+n" or "-n"), evicting
+// the series not updated for 10 minutes once full. Lines over these limits
+// are dropped and reported to the global error handler.
+type StatsDListener struct {
+	conn      net.PacketConn
+	meter     metric.Meter
+	done      chan struct{}
+	maxGauges int
+
+	mu          sync.Mutex
+	counters    map[string]metric.Float64Counter
+	gauges      map[string]metric.Float64Gauge
+	histograms  map[string]metric.Float64Histogram
+	gaugeValues map[string]statsdGauge
+	lastEvict   time.Time
+}
+
+// statsdGauge is the last value of a gauge series.
+type statsdGauge struct {
+	value   float64
+	updated time.Time
+}
+
+// ListenStatsD starts listening for StatsD packets on the UDP address addr,
+// such as ":8125", and records the received metrics on mp.
+func ListenStatsD(addr string, mp metric.MeterProvider) (*StatsDListener, error) {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: failed to listen for StatsD on %s: %w", addr, err)
+	}
+	l := &StatsDListener{
+		conn:        conn,
+		meter:       mp.Meter(instrumentationName),
+		done:        make(chan struct{}),
+		maxGauges:   statsdMaxGauges,
+		counters:    make(map[string]metric.Float64Counter),
+		gauges:      make(map[string]metric.Float64Gauge),
+		histograms:  make(map[string]metric.Float64Histogram),
+		gaugeValues: make(map[string]statsdGauge),
+	}
+	go l.serve()
+	return l, nil
+}
+
+// Addr returns the address the listener receives packets on.
+func (l *StatsDListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+// Close stops the listener.
+func (l *StatsDListener) Close() error {
+	err := l.conn.Close()
+	<-l.done
+	return err
+}
+
+func (l *StatsDListener) serve() {
+	defer close(l.done)
+	buf := make([]byte, statsdMaxPacket)
+	for {
+		n, _, err := l.conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				otel.Handle(err)
+			}
+			return
+		}
+		for _, line := range strings.Split(string(buf[:n]), "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+			if err := l.record(line); err != nil {
+				otel.Handle(err)
+			}
+		}
+	}
+}
+
+// record parses a single "name:value|type[|@rate][|#tags]" line and records
+// it.
+func (l *StatsDListener) record(line string) error {
+	name, rest, ok := strings.Cut(line, ":")
+	if !ok || name == "" {
+		return fmt.Errorf("telemetry: invalid StatsD line %q", line)
+	}
+	fields := strings.Split(rest, "|")
+	if len(fields) < 2 {
+		return fmt.Errorf("telemetry: invalid StatsD line %q", line)
+	}
+	raw, typ := fields[0], fields[1]
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return fmt.Errorf("telemetry: invalid StatsD value in %q: %w", line, err)
+	}
+
+	rate := 1.0
+	var attrs []attribute.KeyValue
+	for _, f := range fields[2:] {
+		switch {
+		case strings.HasPrefix(f, "@"):
+			if rate, err = strconv.ParseFloat(f[1:], 64); err != nil || rate <= 0 || rate > 1 {
+				return fmt.Errorf("telemetry: invalid StatsD sample rate in %q", line)
+			}
+		case strings.HasPrefix(f, "#"):
+			for _, tag := range strings.Split(f[1:], ",") {
+				k, v, _ := strings.Cut(tag, ":")
+				attrs = append(attrs, attribute.String(k, v))
+			}
+		}
+	}
+	set := attribute.NewSet(attrs...)
+	opt := metric.WithAttributeSet(set)
+	ctx := context.Background()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch typ {
+	case "c":
+		c, err := l.counter(name)
+		if err != nil {
+			return err
+		}
+		c.Add(ctx, value/rate, opt)
+	case "g":
+		g, err := l.gauge(name)
+		if err != nil {
+			return err
+		}
+		key := name + "\x00" + set.Encoded(attribute.DefaultEncoder())
+		now := time.Now()
+		last, ok := l.gaugeValues[key]
+		if !ok && len(l.gaugeValues) >= l.maxGauges && !l.evictGauges(now) {
+			return fmt.Errorf("telemetry: too many StatsD gauge series, dropping %q", line)
+		}
+		if raw[0] == '+' || raw[0] == '-' {
+			value += last.value
+		}
+		l.gaugeValues[key] = statsdGauge{value: value, updated: now}
+		g.Record(ctx, value, opt)
+	case "ms", "h", "d":
+		h, err := l.histogram(name, typ)
+		if err != nil {
+			return err
+		}
+		h.Record(ctx, value, opt)
+	default:
+		return fmt.Errorf("telemetry: unsupported StatsD metric type %q in %q", typ, line)
+	}
+	return nil
+}
+
+// evictGauges removes the gauge series idle for statsdGaugeIdle, at most
+// once per minute so floods of new series don't make every line scan them
+// all, and reports whether room was made. It must be called with mu held.
+func (l *StatsDListener) evictGauges(now time.Time) bool {
+	if now.Sub(l.lastEvict) >= time.Minute {
+		l.lastEvict = now
+		for key, g := range l.gaugeValues {
+			if now.Sub(g.updated) >= statsdGaugeIdle {
+				delete(l.gaugeValues, key)
+			}
+		}
+	}
+	return len(l.gaugeValues) < l.maxGauges
+}
+
+// instrumentsFull returns an error if the listener created instruments for
+// statsdMaxInstruments names already. It must be called with mu held.
+func (l *StatsDListener) instrumentsFull(name string) error {
+	if len(l.counters)+len(l.gauges)+len(l.histograms) >= statsdMaxInstruments {
+		return fmt.Errorf("telemetry: too many StatsD metrics, dropping %q", name)
+	}
+	return nil
+}
+
+// counter, gauge and histogram return the instrument for name, creating it
+// on first use. They must be called with mu held.
+func (l *StatsDListener) counter(name string) (metric.Float64Counter, error) {
+	if c, ok := l.counters[name]; ok {
+		return c, nil
+	}
+	if err := l.instrumentsFull(name); err != nil {
+		return nil, err
+	}
+	c, err := l.meter.Float64Counter(name)
+	if err != nil {
+		return nil, err
+	}
+	l.counters[name] = c
+	return c, nil
+}
+
+func (l *StatsDListener) gauge(name string) (metric.Float64Gauge, error) {
+	if g, ok := l.gauges[name]; ok {
+		return g, nil
+	}
+	if err := l.instrumentsFull(name); err != nil {
+		return nil, err
+	}
+	g, err := l.meter.Float64Gauge(name)
+	if err != nil {
+		return nil, err
+	}
+	l.gauges[name] = g
+	return g, nil
+}
+
+func (l *StatsDListener) histogram(name, typ string) (metric.Float64Histogram, error) {
+	if h, ok := l.histograms[name]; ok {
+		return h, nil
+	}
+	if err := l.instrumentsFull(name); err != nil {
+		return nil, err
+	}
+	var opts []metric.Float64HistogramOption
+	if typ == "ms" {
+		opts = append(opts, metric.WithUnit("ms"))
+	}
+	h, err := l.meter.Float64Histogram(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	l.histograms[name] = h
+	return h, nil
+}
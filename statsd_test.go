@@ -0,0 +1,102 @@
+package telemetry
+
+import (
+	"fmt"
+	"testing"
+
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+func TestStatsDGaugeSeries(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []string
+		idle      bool
+		wantErr   []bool
+		wantCount int
+	}{
+		{
+			name:      "relative updates",
+			lines:     []string{"queue:5|g", "queue:+3|g", "queue:-1|g"},
+			wantErr:   []bool{false, false, false},
+			wantCount: 1,
+		},
+		{
+			name:      "new series dropped once full",
+			lines:     []string{"a:1|g", "b:1|g", "a:+1|g", "c:1|g", "b:1|g|#host:x"},
+			wantErr:   []bool{false, false, false, true, true},
+			wantCount: 2,
+		},
+		{
+			name:      "idle series evicted once full",
+			lines:     []string{"a:1|g", "b:1|g", "c:1|g"},
+			idle:      true,
+			wantErr:   []bool{false, false, false},
+			wantCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := ListenStatsD("127.0.0.1:0", noop.NewMeterProvider())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer l.Close()
+			l.maxGauges = 2
+
+			for i, line := range tt.lines {
+				if tt.idle {
+					for key, g := range l.gaugeValues {
+						g.updated = g.updated.Add(-statsdGaugeIdle)
+						l.gaugeValues[key] = g
+					}
+				}
+				if err := l.record(line); (err != nil) != tt.wantErr[i] {
+					t.Errorf("record(%q) = %v, want error %v", line, err, tt.wantErr[i])
+				}
+			}
+			if got := len(l.gaugeValues); got != tt.wantCount {
+				t.Errorf("kept %d series, want %d", got, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestStatsDGaugeValue(t *testing.T) {
+	l, err := ListenStatsD("127.0.0.1:0", noop.NewMeterProvider())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	for _, line := range []string{"queue:5|g", "queue:+3|g", "queue:-1|g"} {
+		if err := l.record(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, g := range l.gaugeValues {
+		if g.value != 7 {
+			t.Errorf("value = %v, want 7", g.value)
+		}
+	}
+}
+
+func TestStatsDInstrumentLimit(t *testing.T) {
+	l, err := ListenStatsD("127.0.0.1:0", noop.NewMeterProvider())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	for i := range statsdMaxInstruments {
+		if _, err := l.counter(fmt.Sprintf("metric.%d", i)); err != nil {
+			t.Fatalf("counter %d: %v", i, err)
+		}
+	}
+	if _, err := l.counter("metric.0"); err != nil {
+		t.Errorf("existing counter: %v", err)
+	}
+	if _, err := l.histogram("one.more", "ms"); err == nil {
+		t.Error("instrument over the limit created")
+	}
+}
@@ -0,0 +1,46 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	stdlog "log"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// RedirectStdLog sends the output of the standard library's default logger to
+// the global logger provider, emitting each line as a record of the given
+// severity. This captures third-party libraries that log through the standard
+// logger. The returned function restores the previous output and flags.
+func RedirectStdLog(severity log.Severity) (restore func()) {
+	prevOutput, prevFlags := stdlog.Writer(), stdlog.Flags()
+
+	stdlog.SetFlags(0)
+	stdlog.SetOutput(&stdLogWriter{
+		logger:   global.GetLoggerProvider().Logger("log"),
+		severity: severity,
+	})
+
+	return func() {
+		stdlog.SetOutput(prevOutput)
+		stdlog.SetFlags(prevFlags)
+	}
+}
+
+// stdLogWriter emits each write of the standard logger as a log record.
+type stdLogWriter struct {
+	logger   log.Logger
+	severity log.Severity
+}
+
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	var rec log.Record
+	rec.SetTimestamp(time.Now())
+	rec.SetSeverity(w.severity)
+	rec.SetSeverityText(w.severity.String())
+	rec.SetBody(log.StringValue(string(bytes.TrimRight(p, "\n"))))
+	w.logger.Emit(context.Background(), rec)
+	return len(p), nil
+}
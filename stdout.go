@@ -0,0 +1,83 @@
+package telemetry
+
+import (
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+)
+
+// WithStdoutWriters makes the exporters of SetupOTelSDKStdout and
+// SetupOTelSDKManualReader write each signal to the given writer instead of
+// stdout, e.g. a buffer in tests. A nil writer keeps stdout.
+func WithStdoutWriters(traces, metrics, logs io.Writer) Option {
+	return func(c *config) {
+		c.stdoutTraces = traces
+		c.stdoutMetrics = metrics
+		c.stdoutLogs = logs
+	}
+}
+
+// WithStdoutFiles makes the exporters of SetupOTelSDKStdout and
+// SetupOTelSDKManualReader append each signal to the file at the given path,
+// created if needed, instead of stdout. An empty path keeps the writer set
+// with WithStdoutWriters, or stdout. The files are closed by the shutdown
+// function. The paths can also be set with the TELEMETRY_STDOUT_TRACES_FILE,
+// TELEMETRY_STDOUT_METRICS_FILE and TELEMETRY_STDOUT_LOGS_FILE environment
+// variables.
+func WithStdoutFiles(traces, metrics, logs string) Option {
+	return func(c *config) {
+		c.stdoutTracesFile = traces
+		c.stdoutMetricsFile = metrics
+		c.stdoutLogsFile = logs
+	}
+}
+
+// WithCompactJSON makes the stdout exporters write each span, like the
+// metric batches and log records, as a single line of JSON instead of
+// pretty-printing it, for line-oriented log pipelines. It can also be
+// enabled with the TELEMETRY_STDOUT_COMPACT environment variable.
+func WithCompactJSON() Option {
+	return func(c *config) {
+		c.stdoutCompact = true
+	}
+}
+
+// stdoutTraceOptions returns the options of the stdout trace exporters
+// writing to w.
+func stdoutTraceOptions(cfg config, w io.Writer) []stdouttrace.Option {
+	opts := []stdouttrace.Option{stdouttrace.WithWriter(w)}
+	if !cfg.stdoutCompact {
+		opts = append(opts, stdouttrace.WithPrettyPrint())
+	}
+	if cfg.stdoutDeterministic {
+		opts = append(opts, stdouttrace.WithoutTimestamps())
+	}
+	return opts
+}
+
+// stdoutWriter returns the writer a stdout exporter writes to: the file at
+// path if set, rotated according to rotation and appended to files,
+// otherwise w or stdout.
+func stdoutWriter(path string, w io.Writer, rotation LogRotation, files *[]io.Closer) (io.Writer, error) {
+	if path != "" && rotation != (LogRotation{}) {
+		f, err := openRotatingFile(path, rotation)
+		if err != nil {
+			return nil, err
+		}
+		*files = append(*files, f)
+		return f, nil
+	}
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		*files = append(*files, f)
+		return f, nil
+	}
+	if w != nil {
+		return w, nil
+	}
+	return os.Stdout, nil
+}
@@ -0,0 +1,129 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// spanStreamBuffer is the number of spans buffered per subscriber. Spans are
+// dropped for subscribers that fall further behind.
+const spanStreamBuffer = 256
+
+// SpanStream is a span processor that streams finished spans as JSON
+// server-sent events to every connected HTTP client. It is meant for local
+// development, to watch traces with curl or a small UI without a collector.
+type SpanStream struct {
+	filter *privacyFilter
+
+	mu   sync.Mutex
+	subs map[chan []byte]struct{}
+}
+
+var (
+	_ trace.SpanProcessor = (*SpanStream)(nil)
+	_ http.Handler        = (*SpanStream)(nil)
+)
+
+// StreamSpans attaches a new SpanStream to the live tracer provider. Mount
+// the result on a development-only route:
+//
+//	stream, err := telemetry.StreamSpans()
+//	mux.Handle("/debug/spans", stream)
+func StreamSpans() (*SpanStream, error) {
+	providers.Lock()
+	tp, cfg := providers.tracer, providers.config
+	providers.Unlock()
+	if tp == nil {
+		return nil, ErrNotInitialized
+	}
+
+	s := &SpanStream{filter: cfg.privacy, subs: make(map[chan []byte]struct{})}
+	tp.RegisterSpanProcessor(s)
+	return s, nil
+}
+
+// OnStart implements trace.SpanProcessor.
+func (s *SpanStream) OnStart(context.Context, trace.ReadWriteSpan) {}
+
+// OnEnd implements trace.SpanProcessor.
+func (s *SpanStream) OnEnd(span trace.ReadOnlySpan) {
+	if !span.SpanContext().IsSampled() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.subs) == 0 {
+		return
+	}
+
+	b, err := json.Marshal(tracetest.SpanStubFromReadOnlySpan(s.filter.span(span)))
+	if err != nil {
+		return
+	}
+	for ch := range s.subs {
+		select {
+		case ch <- b:
+		default:
+		}
+	}
+}
+
+// Shutdown implements trace.SpanProcessor. It disconnects all clients.
+func (s *SpanStream) Shutdown(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subs {
+		close(ch)
+		delete(s.subs, ch)
+	}
+	return nil
+}
+
+// ForceFlush implements trace.SpanProcessor.
+func (s *SpanStream) ForceFlush(context.Context) error {
+	return nil
+}
+
+// ServeHTTP streams spans to the client until it disconnects.
+func (s *SpanStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ch := make(chan []byte, spanStreamBuffer)
+	s.mu.Lock()
+	s.subs[ch] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, ch)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case b, ok := <-ch:
+			if !ok {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", b); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
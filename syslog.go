@@ -0,0 +1,80 @@
+//go:build !windows && !plan9
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"log/syslog"
+	"sync"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// SyslogExporter is a log exporter forwarding records to syslog, with
+// their severity mapped to the syslog one. Pass it to WithLogExporter.
+type SyslogExporter struct {
+	mu     sync.Mutex
+	writer *syslog.Writer
+}
+
+// NewSyslogExporter connects to the syslog daemon at raddr over network,
+// e.g. "udp" and "logs.internal:514", or to the local daemon if network is
+// empty. Records are sent with the user facility and tag as the program
+// name, os.Args[0] if empty.
+func NewSyslogExporter(network, raddr, tag string) (*SyslogExporter, error) {
+	w, err := syslog.Dial(network, raddr, syslog.LOG_USER|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogExporter{writer: w}, nil
+}
+
+// Export sends each record as a line with its body, attributes and trace
+// context.
+func (e *SyslogExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.writer == nil {
+		return errors.New("telemetry: syslog exporter is shut down")
+	}
+
+	var errs []error
+	for i := range records {
+		line := logLine(&records[i])
+		var err error
+		switch syslogPriority(records[i].Severity()) {
+		case 2:
+			err = e.writer.Crit(line)
+		case 3:
+			err = e.writer.Err(line)
+		case 4:
+			err = e.writer.Warning(line)
+		case 6:
+			err = e.writer.Info(line)
+		default:
+			err = e.writer.Debug(line)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown closes the connection to the syslog daemon.
+func (e *SyslogExporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.writer == nil {
+		return nil
+	}
+	err := e.writer.Close()
+	e.writer = nil
+	return err
+}
+
+// ForceFlush does nothing, records are sent as they are exported.
+func (e *SyslogExporter) ForceFlush(context.Context) error {
+	return nil
+}
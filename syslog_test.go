@@ -0,0 +1,80 @@
+//go:build !windows && !plan9
+
+package telemetry
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/log/logtest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSyslogExporter(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	exporter, err := NewSyslogExporter("udp", pc.LocalAddr().String(), "checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exporter.Shutdown(context.Background())
+
+	traced := logtest.RecordFactory{
+		Severity:   log.SeverityInfo,
+		Body:       log.StringValue("charged"),
+		Attributes: []log.KeyValue{log.String("order.id", "o 1")},
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+	}.NewRecord()
+	tests := []struct {
+		name   string
+		record sdklog.Record
+		// wantPriority is the start of the message, with the user
+		// facility.
+		wantPriority string
+		wantLine     string
+	}{
+		{"fatal", logRecord("crashed", log.SeverityFatal), "<10>", "crashed"},
+		{"error", logRecord("failed", log.SeverityError2), "<11>", "failed"},
+		{"warning", logRecord("slow", log.SeverityWarn), "<12>", "slow"},
+		{"info with attributes", traced, "<14>", `charged order.id="o 1" trace_id=01000000000000000000000000000000 span_id=0200000000000000`},
+		{"unset severity", logRecord("started", log.SeverityUndefined), "<14>", "started"},
+		{"debug", logRecord("cache miss", log.SeverityDebug), "<15>", "cache miss"},
+		{"multiline body", logRecord("panic\ngoroutine 1", log.SeverityError), "<11>", `panic\ngoroutine 1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := exporter.Export(context.Background(), []sdklog.Record{tt.record}); err != nil {
+				t.Fatal(err)
+			}
+			buf := make([]byte, 4096)
+			pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+			n, _, err := pc.ReadFrom(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg := strings.TrimSuffix(string(buf[:n]), "\n")
+			if !strings.HasPrefix(msg, tt.wantPriority) {
+				t.Errorf("message %q does not have priority %s", msg, tt.wantPriority)
+			}
+			if !strings.HasSuffix(msg, ": "+tt.wantLine) {
+				t.Errorf("message %q does not end with %q", msg, tt.wantLine)
+			}
+		})
+	}
+
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.Export(context.Background(), []sdklog.Record{traced}); err == nil {
+		t.Error("Export after Shutdown succeeded")
+	}
+}
@@ -3,6 +3,10 @@ package telemetry
 import (
 	"context"
 	"errors"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -10,112 +14,516 @@ import (
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/log/global"
-	"go.opentelemetry.io/otel/propagation"
+	otelmetric "go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/sdk/log"
 	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 )
 
-// setupOTelSDK bootstraps the OpenTelemetry pipeline.
+// providers holds the SDK providers installed by the last setup, for features
+// that act on the live pipeline.
+var providers struct {
+	sync.Mutex
+	tracer   *trace.TracerProvider
+	meter    *metric.MeterProvider
+	logger   *log.LoggerProvider
+	snapshot *metric.ManualReader
+	clock    Clock
+
+	// config, resource and exporters describe the pipeline for DumpConfig.
+	config    *config
+	resource  *resource.Resource
+	exporters exporters
+}
+
+// initialized is set from the start of a setup until its pipeline is shut
+// down, or the setup fails.
+var initialized atomic.Bool
+
+// claimSetup marks the pipeline as initialized, returning
+// ErrAlreadyInitialized if it already is.
+func claimSetup() error {
+	if !initialized.CompareAndSwap(false, true) {
+		return ErrAlreadyInitialized
+	}
+	return nil
+}
+
+// exporters are the per-signal exporters a pipeline is built on.
+type exporters struct {
+	span   trace.SpanExporter
+	metric metric.Exporter
+	log    log.Exporter
+
+	// metricReader replaces the periodic reader of metric when set.
+	metricReader metric.Reader
+	// endpoint is the collector the exporters send to, if any.
+	endpoint string
+	// files are written by the exporters and closed after them.
+	files []io.Closer
+	// instrument registers the metrics of the exporters, if set.
+	instrument func(otelmetric.MeterProvider) error
+}
+
+// SetupOTelSDKStdout bootstraps the OpenTelemetry pipeline with exporters
+// writing to stdout, or to the writers set with WithStdoutWriters or
+// WithStdoutFiles.
+// If it does not return an error, make sure to call shutdown for proper cleanup.
+func SetupOTelSDKStdout(ctx context.Context, opts ...Option) (shutdown func(context.Context) error, err error) {
+	if err := claimSetup(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			initialized.Store(false)
+		}
+	}()
+
+	cfg := newConfig(opts)
+	exp, err := newStdoutExporters(cfg)
+	if err != nil {
+		return nil, stageError(ErrExporter, err)
+	}
+	return setupPipeline(ctx, cfg, exp)
+}
+
+// SetupOTelSDKManualReader bootstraps the pipeline like SetupOTelSDKStdout,
+// except that metrics are only read when collect is called, instead of being
+// exported periodically. It lets tests read metric values deterministically.
 // If it does not return an error, make sure to call shutdown for proper cleanup.
-func setupOTelSDK(ctx context.Context) (shutdown func(context.Context) error, err error) {
-	var shutdownFuncs []func(context.Context) error
+func SetupOTelSDKManualReader(ctx context.Context, opts ...Option) (collect func(context.Context) (metricdata.ResourceMetrics, error), shutdown func(context.Context) error, err error) {
+	if err := claimSetup(); err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			initialized.Store(false)
+		}
+	}()
+
+	cfg := newConfig(opts)
+	exp, err := newStdoutExporters(cfg)
+	if err != nil {
+		return nil, nil, stageError(ErrExporter, err)
+	}
+	reader := metric.NewManualReader()
+	exp.metricReader = reader
+
+	shutdown, err = setupPipeline(ctx, cfg, exp)
+	if err != nil {
+		return nil, nil, err
+	}
+	collect = func(ctx context.Context) (metricdata.ResourceMetrics, error) {
+		var rm metricdata.ResourceMetrics
+		err := reader.Collect(ctx, &rm)
+		if cfg.clock != nil {
+			stampMetrics(&rm, cfg.clock.Now())
+		}
+		return rm, err
+	}
+	return collect, shutdown, nil
+}
+
+// newStdoutExporters creates exporters writing each signal to stdout, or to
+// the writers and files of cfg.
+func newStdoutExporters(cfg config) (exp exporters, err error) {
+	defer func() {
+		if err != nil {
+			closeFiles(exp.files)
+		}
+	}()
 
-	// shutdown calls cleanup functions registered via shutdownFuncs.
+	traceWriter, err := stdoutWriter(cfg.stdoutTracesFile, cfg.stdoutTraces, LogRotation{}, &exp.files)
+	if err != nil {
+		return exp, err
+	}
+	exp.span, err = stdouttrace.New(stdoutTraceOptions(cfg, traceWriter)...)
+	if err != nil {
+		return exp, err
+	}
+	metricWriter, err := stdoutWriter(cfg.stdoutMetricsFile, cfg.stdoutMetrics, LogRotation{}, &exp.files)
+	if err != nil {
+		return exp, err
+	}
+	metricOpts := []stdoutmetric.Option{stdoutmetric.WithWriter(metricWriter)}
+	if cfg.stdoutDeterministic {
+		metricOpts = append(metricOpts, stdoutmetric.WithoutTimestamps())
+	}
+	exp.metric, err = stdoutmetric.New(metricOpts...)
+	if err != nil {
+		return exp, err
+	}
+	logWriter, err := stdoutWriter(cfg.stdoutLogsFile, cfg.stdoutLogs, cfg.logRotation, &exp.files)
+	if err != nil {
+		return exp, err
+	}
+	logOpts := []stdoutlog.Option{stdoutlog.WithWriter(logWriter)}
+	if cfg.stdoutDeterministic {
+		logOpts = append(logOpts, stdoutlog.WithoutTimestamps())
+	}
+	exp.log, err = stdoutlog.New(logOpts...)
+	if err != nil {
+		return exp, err
+	}
+
+	if cfg.stdoutDeterministic {
+		ids := newIDRenumberer()
+		exp.span = &deterministicSpanExporter{SpanExporter: exp.span, ids: ids}
+		exp.log = &deterministicLogExporter{Exporter: exp.log, ids: ids}
+	}
+	return exp, nil
+}
+
+// closeFiles closes files, returning the errors joined.
+func closeFiles(files []io.Closer) error {
+	var err error
+	for _, f := range files {
+		err = errors.Join(err, f.Close())
+	}
+	return err
+}
+
+// setupPipeline builds the providers exporting to exp and installs them as
+// the globals. On failure, it shuts down exp and returns the failures of every
+// stage, wrapped in the stage errors.
+func setupPipeline(ctx context.Context, cfg config, exp exporters) (shutdown func(context.Context) error, err error) {
+	var (
+		shutdownFuncs []func(context.Context) error
+		shutDown      bool
+	)
+
+	// cleanup calls cleanup functions registered via shutdownFuncs.
 	// The errors from the calls are joined.
-	// Each registered cleanup will be invoked once.
-	shutdown = func(ctx context.Context) error {
+	// Each registered cleanup will be invoked once, after which another
+	// pipeline can be set up.
+	cleanup := func(ctx context.Context) error {
+		// The features acting on the live pipeline return ErrNotInitialized
+		// from here on, rather than using providers being shut down.
+		if !shutDown {
+			providers.Lock()
+			providers.config, providers.resource, providers.exporters = nil, nil, exporters{}
+			providers.tracer = nil
+			providers.meter = nil
+			providers.snapshot = nil
+			providers.clock = nil
+			providers.logger = nil
+			providers.Unlock()
+			auditLogger.Store(nil)
+			debugExporter.Lock()
+			debugExporter.processor = nil
+			debugExporter.Unlock()
+		}
 		var err error
 		for _, fn := range shutdownFuncs {
 			err = errors.Join(err, fn(ctx))
 		}
 		shutdownFuncs = nil
+		err = errors.Join(err, closeFiles(exp.files))
+		exp.files = nil
+		if !shutDown {
+			shutDown = true
+			initialized.Store(false)
+		}
 		return err
 	}
 
-	// handleErr calls shutdown for cleanup and makes sure that all errors are returned.
-	handleErr := func(inErr error) {
-		err = errors.Join(inErr, shutdown(ctx))
+	// The providers are all built before any is installed, collecting the
+	// failures of every stage. If any failed, what was built is shut down and
+	// the globals are left untouched.
+	var errs []error
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, cleanup(ctx))
+		}
+	}()
+
+	// Set up resource.
+	res, err := newResource(ctx, cfg)
+	if err != nil {
+		shutdownFuncs = append(shutdownFuncs, exp.span.Shutdown, exp.log.Shutdown)
+		if exp.metric != nil {
+			shutdownFuncs = append(shutdownFuncs, exp.metric.Shutdown)
+		}
+		return nil, stageError(ErrResource, err)
 	}
 
-	// Set up propagator.
-	prop := newPropagator()
-	otel.SetTextMapPropagator(prop)
+	dumped := exp
+	if cfg.breakerFailures > 0 {
+		exp = withBreakers(cfg, exp)
+	}
+	if toggled, err := withToggles(cfg, exp); err != nil {
+		errs = append(errs, stageError(ErrExporter, err))
+	} else {
+		exp = toggled
+	}
 
 	// Set up trace provider.
-	tracerProvider, err := newTraceProvider()
+	tracerProvider, err := newTraceProvider(cfg, res, exp.span)
 	if err != nil {
-		handleErr(err)
-		return
+		errs = append(errs, stageError(ErrTracerProvider, err))
+		shutdownFuncs = append(shutdownFuncs, exp.span.Shutdown)
+	} else {
+		shutdownFuncs = append(shutdownFuncs, withShutdownTimeout("tracer", cfg.traceShutdownTimeout, tracerProvider.Shutdown))
 	}
-	shutdownFuncs = append(shutdownFuncs, tracerProvider.Shutdown)
-	otel.SetTracerProvider(tracerProvider)
 
-	// Set up meter provider.
-	meterProvider, err := newMeterProvider()
+	// Set up meter provider, with the heartbeat and uptime metrics.
+	snapshotReader := metric.NewManualReader()
+	meterProvider, err := newMeterProvider(cfg, res, exp, snapshotReader)
 	if err != nil {
-		handleErr(err)
-		return
+		errs = append(errs, stageError(ErrMeterProvider, err))
+		if exp.metric != nil {
+			shutdownFuncs = append(shutdownFuncs, exp.metric.Shutdown)
+		}
+	} else {
+		if cfg.pushgatewayURL != "" {
+			shutdownFuncs = append(shutdownFuncs, func(ctx context.Context) error {
+				return pushToGateway(ctx, cfg.pushgatewayURL, cfg.pushgatewayJob, snapshotReader)
+			})
+		}
+		shutdownFuncs = append(shutdownFuncs, withShutdownTimeout("meter", cfg.metricShutdownTimeout, meterProvider.Shutdown))
+		if err := registerHeartbeat(meterProvider); err != nil {
+			errs = append(errs, stageError(ErrMeterProvider, err))
+		}
+		if exp.instrument != nil {
+			if err := exp.instrument(meterProvider); err != nil {
+				errs = append(errs, stageError(ErrMeterProvider, err))
+			}
+		}
 	}
-	shutdownFuncs = append(shutdownFuncs, meterProvider.Shutdown)
-	otel.SetMeterProvider(meterProvider)
 
 	// Set up logger provider.
-	loggerProvider, err := newLoggerProvider()
+	loggerProvider, err := newLoggerProvider(cfg, res, exp.log)
 	if err != nil {
-		handleErr(err)
-		return
+		errs = append(errs, stageError(ErrLoggerProvider, err))
+		shutdownFuncs = append(shutdownFuncs, exp.log.Shutdown)
+	} else {
+		shutdownFuncs = append(shutdownFuncs, withShutdownTimeout("logger", cfg.logShutdownTimeout, loggerProvider.Shutdown))
+	}
+
+	// Set up audit logger provider.
+	auditProvider, err := newAuditLoggerProvider(cfg, dumped.log, res)
+	if err != nil {
+		errs = append(errs, stageError(ErrLoggerProvider, err))
+	} else {
+		shutdownFuncs = append(shutdownFuncs, auditProvider.Shutdown)
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	// Install the pipeline.
+	pprofLabels.Store(cfg.pprofLabels)
+	verbosity.Store(&cfg.verbosity)
+	httpErrorStatus.Store(&httpStatusClassifier{server: cfg.httpServerError, client: cfg.httpClientError})
+	grpcErrorCodes.Store(&grpcCodeClassifier{server: cfg.grpcServerError, client: cfg.grpcClientError})
+	SetLogMinSeverity(cfg.logMinSeverity)
+
+	otel.SetTextMapPropagator(newPropagator(cfg.propagators))
+	if cfg.clock != nil {
+		otel.SetTracerProvider(clockTracerProvider{TracerProvider: tracerProvider, clock: cfg.clock})
+	} else {
+		otel.SetTracerProvider(tracerProvider)
 	}
-	shutdownFuncs = append(shutdownFuncs, loggerProvider.Shutdown)
+	otel.SetMeterProvider(meterProvider)
 	global.SetLoggerProvider(loggerProvider)
+	auditLog := auditProvider.Logger(instrumentationName)
+	auditLogger.Store(&auditLog)
 
-	return
-}
+	providers.Lock()
+	providers.config, providers.resource, providers.exporters = &cfg, res, dumped
+	providers.tracer = tracerProvider
+	providers.meter = meterProvider
+	providers.snapshot = snapshotReader
+	providers.clock = cfg.clock
+	providers.logger = loggerProvider
+	providers.Unlock()
 
-func newPropagator() propagation.TextMapPropagator {
-	return propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	)
+	// Set up continuous profiling.
+	if cfg.profilingURL != "" {
+		profiler := startProfiler(cfg.profilingURL, cfg.profilingInterval, res)
+		shutdownFuncs = append(shutdownFuncs, profiler.shutdown)
+	}
+	return cleanup, nil
 }
 
-func newTraceProvider() (*trace.TracerProvider, error) {
-	traceExporter, err := stdouttrace.New(
-		stdouttrace.WithPrettyPrint())
-	if err != nil {
-		return nil, err
+func newTraceProvider(cfg config, res *resource.Resource, traceExporter trace.SpanExporter) (*trace.TracerProvider, error) {
+	if len(cfg.tenantExporters) > 0 {
+		traceExporter = &tenantRouter{fallback: traceExporter, tenants: cfg.tenantExporters}
 	}
+	traceExporter = wrapSpanExporter(cfg, traceExporter)
 
-	traceProvider := trace.NewTracerProvider(
-		trace.WithBatcher(traceExporter,
+	recentSpans.reset(max(cfg.recentSpans, 0), cfg.privacy)
+	sampler := newSampler(cfg)
+	var opts []trace.TracerProviderOption
+	if cfg.simpleSpanProcessor {
+		opts = append(opts, trace.WithSyncer(traceExporter))
+	} else if cfg.memoryLimit > 0 {
+		processor := newMemoryLimitedSpanProcessor(traceExporter, cfg.memoryLimit, cfg.memoryPolicy, time.Second, cfg.traceExportTimeout)
+		opts = append(opts, trace.WithSpanProcessor(processor))
+		if cfg.memoryPolicy == DegradeSampling {
+			// The samplers the SDK selects from OTEL_TRACES_SAMPLER can't be
+			// wrapped, so the policy then only drops new spans.
+			if sampler == nil && os.Getenv("OTEL_TRACES_SAMPLER") == "" {
+				sampler = trace.ParentBased(trace.AlwaysSample())
+			}
+			if sampler != nil {
+				sampler = memoryPressureSampler{base: sampler, queue: processor.queue}
+			}
+		}
+	} else {
+		batchOpts := []trace.BatchSpanProcessorOption{
 			// Default is 5s. Set to 1s for demonstrative purposes.
-			trace.WithBatchTimeout(time.Second)),
+			trace.WithBatchTimeout(time.Second),
+		}
+		if cfg.traceExportTimeout > 0 {
+			batchOpts = append(batchOpts, trace.WithExportTimeout(cfg.traceExportTimeout))
+		}
+		opts = append(opts, trace.WithBatcher(traceExporter, batchOpts...))
+	}
+	spanExporters := cfg.spanExporters
+	if len(cfg.kafkaBrokers) > 0 {
+		spanExporters = append(spanExporters, NewKafkaExporter(KafkaConfig{Brokers: cfg.kafkaBrokers, Topic: cfg.kafkaTopic}))
+	}
+	for _, exporter := range spanExporters {
+		var batchOpts []trace.BatchSpanProcessorOption
+		if cfg.traceExportTimeout > 0 {
+			batchOpts = append(batchOpts, trace.WithExportTimeout(cfg.traceExportTimeout))
+		}
+		opts = append(opts, trace.WithBatcher(wrapSpanExporter(cfg, exporter), batchOpts...))
+	}
+	opts = append(opts,
+		trace.WithResource(res),
+		trace.WithSpanProcessor(tenantStamper{}),
+		trace.WithSpanProcessor(probabilityStamper{}),
+		trace.WithSpanProcessor(recentSpans),
 	)
+	if len(cfg.peerServices) > 0 {
+		opts = append(opts, trace.WithSpanProcessor(newPeerServiceMapper(cfg.peerServices)))
+	}
+	if cfg.recentSpans > 0 {
+		sampler = newRecordingSampler(sampler)
+	}
+	if sampler != nil {
+		opts = append(opts, trace.WithSampler(sampler))
+	}
+
+	traceProvider := trace.NewTracerProvider(opts...)
 	return traceProvider, nil
 }
 
-func newMeterProvider() (*metric.MeterProvider, error) {
-	metricExporter, err := stdoutmetric.New()
+// wrapSpanExporter wraps exporter with the span rewrites configured by cfg.
+func wrapSpanExporter(cfg config, exporter trace.SpanExporter) trace.SpanExporter {
+	if cfg.spanNameNormalizer != nil {
+		exporter = &spanNameNormalizer{SpanExporter: exporter, normalize: cfg.spanNameNormalizer}
+	}
+	if cfg.attributeValueLimit > 0 {
+		exporter = &truncatingSpanExporter{SpanExporter: exporter, limit: cfg.attributeValueLimit}
+	}
+	if cfg.privacy != nil {
+		exporter = &privacySpanExporter{SpanExporter: exporter, filter: cfg.privacy}
+	}
+	return exporter
+}
+
+func newMeterProvider(cfg config, res *resource.Resource, exp exporters, snapshot metric.Reader) (*metric.MeterProvider, error) {
+	views, err := newMetricViews(cfg.metricRules)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.privacy != nil {
+		views = cfg.privacy.views(views)
+	}
 
-	meterProvider := metric.NewMeterProvider(
-		metric.WithReader(metric.NewPeriodicReader(metricExporter,
+	reader := exp.metricReader
+	if reader == nil {
+		metricExporter := exp.metric
+		if cfg.clock != nil {
+			metricExporter = clockMetricExporter{Exporter: metricExporter, clock: cfg.clock}
+		}
+		readerOpts := []metric.PeriodicReaderOption{
 			// Default is 1m. Set to 3s for demonstrative purposes.
-			metric.WithInterval(3*time.Second))),
-	)
+			metric.WithInterval(3 * time.Second),
+		}
+		if cfg.metricExportTimeout > 0 {
+			readerOpts = append(readerOpts, metric.WithTimeout(cfg.metricExportTimeout))
+		}
+		reader = metric.NewPeriodicReader(metricExporter, readerOpts...)
+	}
+	opts := []metric.Option{
+		metric.WithResource(res),
+		metric.WithReader(reader),
+		metric.WithReader(snapshot),
+		metric.WithView(views...),
+	}
+	metricExporters := cfg.metricExporters
+	if cfg.remoteWriteURL != "" {
+		metricExporters = append(metricExporters, NewRemoteWriteExporter(cfg.remoteWriteURL))
+	}
+	for _, exporter := range metricExporters {
+		var readerOpts []metric.PeriodicReaderOption
+		if cfg.metricExportTimeout > 0 {
+			readerOpts = append(readerOpts, metric.WithTimeout(cfg.metricExportTimeout))
+		}
+		opts = append(opts, metric.WithReader(metric.NewPeriodicReader(exporter, readerOpts...)))
+	}
+	meterProvider := metric.NewMeterProvider(opts...)
 	return meterProvider, nil
 }
 
-func newLoggerProvider() (*log.LoggerProvider, error) {
-	logExporter, err := stdoutlog.New()
-	if err != nil {
-		return nil, err
+func newLoggerProvider(cfg config, res *resource.Resource, logExporter log.Exporter) (*log.LoggerProvider, error) {
+	var batchOpts []log.BatchProcessorOption
+	if cfg.logExportTimeout > 0 {
+		batchOpts = append(batchOpts, log.WithExportTimeout(cfg.logExportTimeout))
 	}
-
-	loggerProvider := log.NewLoggerProvider(
-		log.WithProcessor(log.NewBatchProcessor(logExporter)),
-	)
+	var processor log.Processor
+	if cfg.memoryLimit > 0 {
+		processor = newMemoryLimitedLogProcessor(logExporter, cfg.memoryLimit, cfg.memoryPolicy, cfg.logExportTimeout)
+	} else {
+		processor = log.NewBatchProcessor(logExporter, batchOpts...)
+	}
+	opts := []log.LoggerProviderOption{log.WithResource(res)}
+	if cfg.privacy != nil {
+		opts = append(opts, log.WithProcessor(privacyLogProcessor{filter: cfg.privacy}))
+	}
+	if cfg.attributeValueLimit > 0 {
+		opts = append(opts, log.WithProcessor(truncatingLogProcessor{limit: cfg.attributeValueLimit}))
+	}
+	if cfg.logMaxBodyBytes > 0 {
+		opts = append(opts, log.WithProcessor(logBodyLimiter{limit: cfg.logMaxBodyBytes}))
+	}
+	if cfg.logMaxAttributes > 0 {
+		opts = append(opts, log.WithAttributeCountLimit(cfg.logMaxAttributes))
+	}
+	if cfg.clock != nil {
+		opts = append(opts, log.WithProcessor(clockStamper{clock: cfg.clock}))
+	}
+	opts = append(opts, log.WithProcessor(severityFilter{processor}))
+	logExporters := cfg.logExporters
+	if cfg.lokiURL != "" {
+		logExporters = append(logExporters, NewLokiExporter(LokiConfig{URL: cfg.lokiURL}))
+	}
+	for _, exporter := range logExporters {
+		opts = append(opts, log.WithProcessor(severityFilter{log.NewBatchProcessor(exporter, batchOpts...)}))
+	}
+	loggerProvider := log.NewLoggerProvider(opts...)
 	return loggerProvider, nil
 }
+
+// ForceFlush exports all telemetry buffered by the global providers.
+// Providers that do not buffer are skipped.
+func ForceFlush(ctx context.Context) error {
+	type flusher interface {
+		ForceFlush(context.Context) error
+	}
+
+	var err error
+	for _, p := range []any{otel.GetTracerProvider(), otel.GetMeterProvider(), global.GetLoggerProvider()} {
+		if f, ok := p.(flusher); ok {
+			err = errors.Join(err, f.ForceFlush(ctx))
+		}
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestShutdownResetsProviders(t *testing.T) {
+	ctx := context.Background()
+	shutdown, err := SetupOTelSDKStdout(ctx, WithStdoutWriters(io.Discard, io.Discard, io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := DumpConfig(io.Discard); err != nil {
+		t.Fatalf("DumpConfig on the live pipeline: %v", err)
+	}
+	if err := shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"EnableDebugExporter", func() error { return EnableDebugExporter(io.Discard) }},
+		{"DisableDebugExporter", DisableDebugExporter},
+		{"StreamSpans", func() error { _, err := StreamSpans(); return err }},
+		{"SnapshotMetrics", func() error { _, err := SnapshotMetrics(ctx); return err }},
+		{"DumpConfig", func() error { return DumpConfig(io.Discard) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, ErrNotInitialized) {
+				t.Errorf("got %v, want ErrNotInitialized", err)
+			}
+		})
+	}
+}
+
+func TestDegradeSamplingKeepsEnvSampler(t *testing.T) {
+	t.Setenv("OTEL_TRACES_SAMPLER", "always_off")
+	ctx := context.Background()
+	shutdown, err := SetupOTelSDKStdout(ctx,
+		WithStdoutWriters(io.Discard, io.Discard, io.Discard),
+		WithMemoryLimit(1<<20, DegradeSampling))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(ctx)
+
+	_, span := otel.Tracer("test").Start(ctx, "work")
+	span.End()
+	if span.SpanContext().IsSampled() {
+		t.Error("span sampled with OTEL_TRACES_SAMPLER=always_off")
+	}
+}
@@ -0,0 +1,43 @@
+package telemetryaws
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Store uploads the files of the telemetry archive exporters to an S3
+// bucket. It implements telemetry.ObjectStore:
+//
+//	store := telemetryaws.NewS3Store(s3.NewFromConfig(cfg), "telemetry-archive")
+//	telemetry.WithSpanExporter(telemetry.NewArchiveSpanExporter(telemetry.ArchiveConfig{Store: store}))
+//
+// Leave the client uninstrumented, or the uploads are traced and archived in
+// turn.
+type S3Store struct {
+	client *s3.Client
+	bucket string
+}
+
+// NewS3Store returns a store uploading to bucket with client.
+func NewS3Store(client *s3.Client, bucket string) *S3Store {
+	return &S3Store{client: client, bucket: bucket}
+}
+
+// Upload puts data as the object key of the bucket.
+func (s *S3Store) Upload(ctx context.Context, key string, data []byte) error {
+	contentType := "application/gzip"
+	if strings.HasSuffix(key, ".parquet") {
+		contentType = "application/vnd.apache.parquet"
+	}
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
@@ -0,0 +1,79 @@
+// Package telemetryaws instruments AWS SDK for Go v2 clients so that every
+// API call (DynamoDB, S3, SQS, ...) is recorded as a client span using the
+// tracer provider configured by the telemetry package.
+package telemetryaws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/luciano-personal-org/telemetry/telemetryaws"
+
+// Instrument appends the tracing middleware to cfg, so that every client
+// created from it is traced:
+//
+//	cfg, err := config.LoadDefaultConfig(ctx)
+//	telemetryaws.Instrument(&cfg)
+func Instrument(cfg *aws.Config) {
+	AppendMiddlewares(&cfg.APIOptions)
+}
+
+// AppendMiddlewares appends the tracing middleware to apiOptions. It can be
+// used with per-client or per-operation options.
+func AppendMiddlewares(apiOptions *[]func(*middleware.Stack) error) {
+	*apiOptions = append(*apiOptions, addMiddlewares)
+}
+
+func addMiddlewares(stack *middleware.Stack) error {
+	if err := stack.Initialize.Add(middleware.InitializeMiddlewareFunc("OTelInitializeMiddleware", startSpan), middleware.Before); err != nil {
+		return err
+	}
+	return stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("OTelDeserializeMiddleware", recordResponse), middleware.Before)
+}
+
+// startSpan wraps the whole operation, retries included, in a client span.
+func startSpan(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+	service := awsmiddleware.GetServiceID(ctx)
+	operation := awsmiddleware.GetOperationName(ctx)
+
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, service+"."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.RPCSystemKey.String("aws-api"),
+			semconv.RPCService(service),
+			semconv.RPCMethod(operation),
+			semconv.CloudRegion(awsmiddleware.GetRegion(ctx)),
+		))
+	defer span.End()
+
+	out, metadata, err := next.HandleInitialize(ctx, in)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return out, metadata, err
+}
+
+// recordResponse annotates the span with the HTTP status and request ID of
+// each attempt.
+func recordResponse(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (middleware.DeserializeOutput, middleware.Metadata, error) {
+	out, metadata, err := next.HandleDeserialize(ctx, in)
+
+	span := trace.SpanFromContext(ctx)
+	if resp, ok := out.RawResponse.(*smithyhttp.Response); ok {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	}
+	if requestID, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+		span.SetAttributes(semconv.AWSRequestID(requestID))
+	}
+	return out, metadata, err
+}
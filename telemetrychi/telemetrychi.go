@@ -0,0 +1,100 @@
+// Package telemetrychi provides chi middleware that traces requests and
+// records the standard HTTP server metrics of the telemetry package, using
+// the matched route pattern for low-cardinality span names.
+package telemetrychi
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/luciano-personal-org/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/luciano-personal-org/telemetry/telemetrychi"
+
+// Middleware returns a chi middleware that starts a server span per request
+// and records the HTTP server metrics through the global meter provider.
+// Installed with Use on a chi router, the route pattern is looked up before
+// the span starts, so the span is named after it and sampling rules can
+// match its http.route attribute. Otherwise the span is renamed once chi has
+// routed the request.
+func Middleware(next http.Handler) http.Handler {
+	tracer := otel.Tracer(instrumentationName)
+	metrics, err := telemetry.NewHTTPMetrics(otel.GetMeterProvider())
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.Method
+		attrs := []attribute.KeyValue{
+			semconv.HTTPRequestMethodKey.String(r.Method),
+			semconv.URLPath(r.URL.Path),
+		}
+		start := findRoute(r)
+		if start != "" {
+			name += " " + start
+			attrs = append(attrs, semconv.HTTPRoute(start))
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attrs...))
+		defer span.End()
+
+		var done func(string, int)
+		if metrics != nil {
+			done = metrics.Begin(ctx, r.Method)
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		route := routePattern(r)
+		if done != nil {
+			done(route, status)
+		}
+		if route != "" && route != start {
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		telemetry.SetHTTPServerStatus(span, status)
+	})
+}
+
+// routePattern returns the route pattern chi matched for r, if any.
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return ""
+	}
+	return rctx.RoutePattern()
+}
+
+// findRoute looks up the route pattern the router serving r will match, when
+// the middleware runs before the routing, or returns "".
+func findRoute(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return ""
+	}
+	path := rctx.RoutePath
+	if path == "" {
+		path = r.URL.RawPath
+		if path == "" {
+			path = r.URL.Path
+		}
+	}
+	return rctx.Routes.Find(chi.NewRouteContext(), r.Method, path)
+}
@@ -0,0 +1,58 @@
+package telemetrychi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/luciano-personal-org/telemetry"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestMiddlewareRouteAtStart(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(telemetry.RuleBasedSampler(sdktrace.AlwaysSample(),
+			telemetry.SamplingRule{Key: "http.route", Pattern: "/healthz", Ratio: 0})),
+		sdktrace.WithSpanProcessor(sr))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(prev)
+
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Get("/healthz", func(http.ResponseWriter, *http.Request) {})
+	r.Route("/orders", func(r chi.Router) {
+		r.Get("/{id}", func(http.ResponseWriter, *http.Request) {})
+	})
+
+	tests := []struct {
+		path     string
+		wantName string
+	}{
+		{"/healthz", ""},
+		{"/orders/42", "GET /orders/{id}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			sr.Reset()
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+			spans := sr.Ended()
+			if tt.wantName == "" {
+				if len(spans) != 0 {
+					t.Errorf("got %d sampled spans, want none", len(spans))
+				}
+				return
+			}
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			if got := spans[0].Name(); got != tt.wantName {
+				t.Errorf("got name %q, want %q", got, tt.wantName)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Package telemetryecho provides Echo middleware that traces requests and
+// records the standard HTTP server metrics of the telemetry package.
+package telemetryecho
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/luciano-personal-org/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/luciano-personal-org/telemetry/telemetryecho"
+
+// Middleware returns an Echo middleware that starts a server span per request,
+// named after the matched route template rather than the raw URL, and records
+// the HTTP server metrics through the global meter provider.
+func Middleware() echo.MiddlewareFunc {
+	tracer := otel.Tracer(instrumentationName)
+	metrics, err := telemetry.NewHTTPMetrics(otel.GetMeterProvider())
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+			route := c.Path()
+			name := r.Method
+			if route != "" {
+				name += " " + route
+			}
+
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.URLPath(r.URL.Path),
+					semconv.HTTPRoute(route),
+				))
+			defer span.End()
+			c.SetRequest(r.WithContext(ctx))
+
+			var done func(string, int)
+			if metrics != nil {
+				done = metrics.Begin(ctx, r.Method)
+			}
+
+			err := next(c)
+			if err != nil {
+				span.RecordError(err)
+				// Let Echo write the error response so the final status is
+				// known. The error is handled here, so it isn't returned for
+				// the error handler to run a second time.
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			if done != nil {
+				done(route, status)
+			}
+			telemetry.SetHTTPServerStatus(span, status)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package telemetryecho
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestMiddlewareHandlesErrorsOnce(t *testing.T) {
+	e := echo.New()
+	var handled int
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		handled++
+		c.NoContent(http.StatusTeapot)
+	}
+	e.Use(Middleware())
+	e.GET("/fail", func(echo.Context) error { return errors.New("boom") })
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	if handled != 1 {
+		t.Errorf("error handler ran %d times, want 1", handled)
+	}
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusTeapot)
+	}
+}
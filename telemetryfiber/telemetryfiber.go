@@ -0,0 +1,103 @@
+// Package telemetryfiber provides Fiber middleware that traces requests and
+// records the standard HTTP server metrics of the telemetry package. Fiber is
+// built on fasthttp, so the net/http middleware cannot be used with it.
+package telemetryfiber
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/luciano-personal-org/telemetry"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/luciano-personal-org/telemetry/telemetryfiber"
+
+// HeaderCarrier adapts fasthttp request headers to a
+// propagation.TextMapCarrier.
+type HeaderCarrier struct {
+	*fasthttp.RequestHeader
+}
+
+// Compile time check that HeaderCarrier implements the TextMapCarrier.
+var _ propagation.TextMapCarrier = HeaderCarrier{}
+
+// Get returns the value associated with the passed key.
+func (c HeaderCarrier) Get(key string) string {
+	return string(c.Peek(key))
+}
+
+// Set stores the key-value pair.
+func (c HeaderCarrier) Set(key, value string) {
+	c.RequestHeader.Set(key, value)
+}
+
+// Keys lists the keys stored in this carrier.
+func (c HeaderCarrier) Keys() []string {
+	var keys []string
+	c.VisitAll(func(k, _ []byte) {
+		keys = append(keys, string(k))
+	})
+	return keys
+}
+
+// Middleware returns a Fiber middleware that starts a server span per
+// request, named after the matched route template, and records the HTTP
+// server metrics through the global meter provider. The span context is
+// available to handlers through c.UserContext(). The route is only known
+// once the router has run, so sampling rules can't match its http.route
+// attribute; match url.path instead.
+func Middleware() fiber.Handler {
+	tracer := otel.Tracer(instrumentationName)
+	metrics, err := telemetry.NewHTTPMetrics(otel.GetMeterProvider())
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(c *fiber.Ctx) error {
+		method := c.Method()
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), HeaderCarrier{&c.Request().Header})
+		ctx, span := tracer.Start(ctx, method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(c.Path()),
+			))
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		var done func(string, int)
+		if metrics != nil {
+			done = metrics.Begin(ctx, method)
+		}
+
+		self := c.Route()
+		err := c.Next()
+		if err != nil {
+			span.RecordError(err)
+			// Run the error handler so the final status is known.
+			if herr := c.App().Config().ErrorHandler(c, err); herr != nil {
+				_ = c.SendStatus(http.StatusInternalServerError)
+			}
+		}
+
+		// The matched route is only known once the router has run. It is
+		// still the route of the middleware if no handler matched.
+		var route string
+		if r := c.Route(); r != self {
+			route = r.Path
+			span.SetName(method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		status := c.Response().StatusCode()
+		if done != nil {
+			done(route, status)
+		}
+		telemetry.SetHTTPServerStatus(span, status)
+		return nil
+	}
+}
@@ -0,0 +1,35 @@
+// Package telemetryfx provides an Uber fx module installing the telemetry
+// pipeline, with its shutdown bound to the application lifecycle.
+package telemetryfx
+
+import (
+	"context"
+
+	"github.com/luciano-personal-org/telemetry"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+)
+
+// Module returns an fx module that sets up the pipeline with opts when the
+// application is constructed and shuts it down when the application stops.
+// It provides the *telemetry.Telemetry handle, the trace.TracerProvider and
+// metric.MeterProvider, and a trace.Tracer and metric.Meter named scope.
+func Module(scope string, opts ...telemetry.Option) fx.Option {
+	return fx.Module("telemetry",
+		fx.Provide(
+			func(lc fx.Lifecycle) (*telemetry.Telemetry, error) {
+				t, err := telemetry.New(context.Background(), opts...)
+				if err != nil {
+					return nil, err
+				}
+				lc.Append(fx.StopHook(t.Shutdown))
+				return t, nil
+			},
+			func(t *telemetry.Telemetry) trace.TracerProvider { return t.TracerProvider },
+			func(t *telemetry.Telemetry) metric.MeterProvider { return t.MeterProvider },
+			func(tp trace.TracerProvider) trace.Tracer { return tp.Tracer(scope) },
+			func(mp metric.MeterProvider) metric.Meter { return mp.Meter(scope) },
+		),
+	)
+}
@@ -0,0 +1,46 @@
+package telemetryfx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/luciano-personal-org/telemetry"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestModule(t *testing.T) {
+	var (
+		handle *telemetry.Telemetry
+		tracer trace.Tracer
+		meter  metric.Meter
+	)
+	app := fxtest.New(t,
+		Module("orders", telemetry.WithStdoutWriters(io.Discard, io.Discard, io.Discard)),
+		fx.Populate(&handle, &tracer, &meter))
+	app.RequireStart()
+
+	ctx := context.Background()
+	if _, err := telemetry.SetupOTelSDKStdout(ctx); !errors.Is(err, telemetry.ErrAlreadyInitialized) {
+		t.Errorf("setup while the module runs: got %v, want ErrAlreadyInitialized", err)
+	}
+	_, span := tracer.Start(ctx, "work")
+	span.End()
+	if !span.SpanContext().IsValid() {
+		t.Error("provided tracer is not recording")
+	}
+	if _, err := meter.Int64Counter("orders.placed"); err != nil {
+		t.Errorf("provided meter: %v", err)
+	}
+
+	app.RequireStop()
+	shutdown, err := telemetry.SetupOTelSDKStdout(ctx, telemetry.WithStdoutWriters(io.Discard, io.Discard, io.Discard))
+	if err != nil {
+		t.Fatalf("setup after the application stopped: %v", err)
+	}
+	shutdown(ctx)
+}
@@ -0,0 +1,60 @@
+// Package telemetrygin provides Gin middleware that traces requests and
+// records the standard HTTP server metrics of the telemetry package.
+package telemetrygin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/luciano-personal-org/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/luciano-personal-org/telemetry/telemetrygin"
+
+// Middleware returns a Gin middleware that starts a server span per request,
+// named after the matched route template rather than the raw URL, and records
+// the HTTP server metrics through the global meter provider.
+func Middleware() gin.HandlerFunc {
+	tracer := otel.Tracer(instrumentationName)
+	metrics, err := telemetry.NewHTTPMetrics(otel.GetMeterProvider())
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(c *gin.Context) {
+		r := c.Request
+		route := c.FullPath()
+		name := r.Method
+		if route != "" {
+			name += " " + route
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+				semconv.HTTPRoute(route),
+			))
+		defer span.End()
+		c.Request = r.WithContext(ctx)
+
+		var done func(string, int)
+		if metrics != nil {
+			done = metrics.Begin(ctx, r.Method)
+		}
+		c.Next()
+
+		status := c.Writer.Status()
+		if done != nil {
+			done(route, status)
+		}
+		if err := c.Errors.Last(); err != nil {
+			span.RecordError(err.Err)
+		}
+		telemetry.SetHTTPServerStatus(span, status)
+	}
+}
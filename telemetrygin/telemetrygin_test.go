@@ -0,0 +1,115 @@
+package telemetrygin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func TestMiddleware(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	prevTP, prevMP, prevProp := otel.GetTracerProvider(), otel.GetMeterProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetMeterProvider(prevMP)
+		otel.SetTextMapPropagator(prevProp)
+	}()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/orders/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/fail", func(c *gin.Context) {
+		c.Error(errors.New("boom"))
+		c.Status(http.StatusInternalServerError)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	tests := []struct {
+		path        string
+		traceparent string
+		wantName    string
+		wantRoute   string
+		wantStatus  int
+		wantError   bool
+	}{
+		{"/orders/42", "00-" + traceID + "-00f067aa0ba902b7-01", "GET /orders/:id", "/orders/:id", http.StatusOK, false},
+		{"/fail", "", "GET /fail", "/fail", http.StatusInternalServerError, true},
+		{"/missing", "", "GET", "", http.StatusNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			sr.Reset()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := sr.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != tt.wantName {
+				t.Errorf("got name %q, want %q", span.Name(), tt.wantName)
+			}
+			if tt.traceparent != "" && span.SpanContext().TraceID().String() != traceID {
+				t.Errorf("span not in the propagated trace: %s", span.SpanContext().TraceID())
+			}
+			attrs := attribute.NewSet(span.Attributes()...)
+			if v, _ := attrs.Value(semconv.HTTPRouteKey); v.AsString() != tt.wantRoute {
+				t.Errorf("got http.route %q, want %q", v.AsString(), tt.wantRoute)
+			}
+			if v, _ := attrs.Value(semconv.HTTPResponseStatusCodeKey); v.AsInt64() != int64(tt.wantStatus) {
+				t.Errorf("got status %d, want %d", v.AsInt64(), tt.wantStatus)
+			}
+			if got := span.Status().Code == codes.Error; got != tt.wantError {
+				t.Errorf("got error status %v, want %v", got, tt.wantError)
+			}
+			if got := len(span.Events()) > 0; got != tt.wantError {
+				t.Errorf("got recorded error %v, want %v", got, tt.wantError)
+			}
+		})
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]uint64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != semconv.HTTPServerRequestDurationName {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				route, _ := dp.Attributes.Value(semconv.HTTPRouteKey)
+				counts[route.AsString()] += dp.Count
+			}
+		}
+	}
+	want := map[string]uint64{"/orders/:id": 1, "/fail": 1, "": 1}
+	for route, n := range want {
+		if counts[route] != n {
+			t.Errorf("got %d requests recorded for route %q, want %d", counts[route], route, n)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Package telemetrygql instruments gqlgen servers with the tracer and meter
+// providers configured by the telemetry package.
+package telemetrygql
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/luciano-personal-org/telemetry/telemetrygql"
+
+// Tracer is a gqlgen handler extension that creates a span per operation and
+// per resolver, and records operation duration and error metrics.
+//
+//	srv.Use(telemetrygql.Tracer{})
+type Tracer struct {
+	// SkipTrivialFields disables spans for fields that are not backed by a
+	// resolver method, which keeps traces small for wide objects.
+	SkipTrivialFields bool
+}
+
+var (
+	_ graphql.HandlerExtension     = Tracer{}
+	_ graphql.ResponseInterceptor  = Tracer{}
+	_ graphql.FieldInterceptor     = Tracer{}
+	_ graphql.OperationInterceptor = Tracer{}
+)
+
+// ExtensionName implements graphql.HandlerExtension.
+func (Tracer) ExtensionName() string {
+	return "OpenTelemetry"
+}
+
+// Validate implements graphql.HandlerExtension.
+func (Tracer) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation implements graphql.OperationInterceptor.
+func (Tracer) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	return next(ctx)
+}
+
+// InterceptResponse implements graphql.ResponseInterceptor. It wraps the whole
+// operation in a server span named after the operation.
+func (t Tracer) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	oc := graphql.GetOperationContext(ctx)
+
+	opType := "query"
+	if oc.Operation != nil {
+		opType = string(oc.Operation.Operation)
+	}
+	name := oc.OperationName
+	if name == "" {
+		name = "anonymous"
+	}
+	attrs := []attribute.KeyValue{
+		semconv.GraphqlOperationName(name),
+		semconv.GraphqlOperationTypeKey.String(opType),
+	}
+
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, opType+" "+name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(semconv.GraphqlDocument(oc.RawQuery)))
+	defer span.End()
+	start := time.Now()
+
+	resp := next(ctx)
+
+	failed := resp != nil && len(resp.Errors) > 0
+	if failed {
+		span.SetStatus(codes.Error, resp.Errors.Error())
+		for _, gqlErr := range resp.Errors {
+			span.RecordError(gqlErr)
+		}
+	}
+	recordOperation(ctx, time.Since(start), failed, attrs)
+	return resp
+}
+
+// InterceptField implements graphql.FieldInterceptor. It creates a span for
+// each resolved field and records resolver errors on it.
+func (t Tracer) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || (t.SkipTrivialFields && !fc.IsResolver) {
+		return next(ctx)
+	}
+
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, fc.Object+"."+fc.Field.Name,
+		trace.WithAttributes(
+			attribute.String("graphql.field.path", fc.Path().String()),
+			attribute.String("graphql.field.name", fc.Field.Name),
+			attribute.String("graphql.field.parent", fc.Object),
+		))
+	defer span.End()
+
+	res, err := next(ctx)
+	if errs := graphql.GetFieldErrors(ctx, fc); len(errs) > 0 {
+		span.SetStatus(codes.Error, errs.Error())
+		for _, gqlErr := range errs {
+			span.RecordError(gqlErr)
+		}
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return res, err
+}
+
+// operationMetrics holds the instruments of the operations.
+type operationMetrics struct {
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+}
+
+// instruments returns the operation instruments of the current meter
+// provider. They are not cached, so operations after the pipeline is set up
+// again are recorded by the new one.
+func instruments() operationMetrics {
+	meter := otel.Meter(instrumentationName)
+	duration, err := meter.Float64Histogram("graphql.server.operation.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of GraphQL operations."))
+	if err != nil {
+		otel.Handle(err)
+	}
+	errors, err := meter.Int64Counter("graphql.server.operation.errors",
+		metric.WithUnit("{operation}"),
+		metric.WithDescription("Number of GraphQL operations that returned errors."))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return operationMetrics{duration: duration, errors: errors}
+}
+
+// recordOperation records the duration and outcome of an operation.
+func recordOperation(ctx context.Context, d time.Duration, failed bool, attrs []attribute.KeyValue) {
+	m := instruments()
+	opt := metric.WithAttributes(attrs...)
+	m.duration.Record(ctx, d.Seconds(), opt)
+	if failed {
+		m.errors.Add(ctx, 1, opt)
+	}
+}
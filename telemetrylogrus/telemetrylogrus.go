@@ -0,0 +1,86 @@
+// Package telemetrylogrus provides a logrus hook that forwards entries to the
+// OpenTelemetry logger provider configured by the telemetry package.
+package telemetrylogrus
+
+import (
+	"context"
+
+	"github.com/luciano-personal-org/telemetry/internal/logconv"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// Hook is a logrus.Hook emitting entries as OpenTelemetry log records. Entries
+// created with WithContext are correlated with the span in their context.
+type Hook struct {
+	logger log.Logger
+	levels []logrus.Level
+}
+
+var _ logrus.Hook = (*Hook)(nil)
+
+// NewHook returns a Hook emitting through a logger called name obtained from
+// the global logger provider. Only entries at the given levels are forwarded;
+// all levels are forwarded if none are given.
+//
+//	logrus.AddHook(telemetrylogrus.NewHook("orders"))
+func NewHook(name string, levels ...logrus.Level) *Hook {
+	if len(levels) == 0 {
+		levels = logrus.AllLevels
+	}
+	return &Hook{
+		logger: global.GetLoggerProvider().Logger(name),
+		levels: levels,
+	}
+}
+
+// Levels implements logrus.Hook.
+func (h *Hook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Fire implements logrus.Hook.
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var rec log.Record
+	rec.SetTimestamp(entry.Time)
+	rec.SetBody(log.StringValue(entry.Message))
+	rec.SetSeverity(severity(entry.Level))
+	rec.SetSeverityText(entry.Level.String())
+	rec.AddAttributes(logconv.KeyValues(entry.Data)...)
+	if entry.Caller != nil {
+		rec.AddAttributes(
+			log.String("code.filepath", entry.Caller.File),
+			log.Int("code.lineno", entry.Caller.Line),
+			log.String("code.function", entry.Caller.Function),
+		)
+	}
+
+	h.logger.Emit(ctx, rec)
+	return nil
+}
+
+func severity(level logrus.Level) log.Severity {
+	switch level {
+	case logrus.TraceLevel:
+		return log.SeverityTrace
+	case logrus.DebugLevel:
+		return log.SeverityDebug
+	case logrus.InfoLevel:
+		return log.SeverityInfo
+	case logrus.WarnLevel:
+		return log.SeverityWarn
+	case logrus.ErrorLevel:
+		return log.SeverityError
+	case logrus.FatalLevel:
+		return log.SeverityFatal
+	case logrus.PanicLevel:
+		return log.SeverityFatal2
+	}
+	return log.SeverityUndefined
+}
@@ -0,0 +1,102 @@
+// Package telemetrymongo traces MongoDB commands issued through the official
+// Go driver using the tracer provider configured by the telemetry package.
+package telemetrymongo
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/luciano-personal-org/telemetry/telemetrymongo"
+
+// spanKey identifies an in-flight command.
+type spanKey struct {
+	connectionID string
+	requestID    int64
+}
+
+type monitor struct {
+	tracer trace.Tracer
+
+	mu    sync.Mutex
+	spans map[spanKey]trace.Span
+}
+
+// NewMonitor returns a CommandMonitor that creates a client span per command,
+// with the database, collection and operation as attributes:
+//
+//	opts := options.Client().ApplyURI(uri).SetMonitor(telemetrymongo.NewMonitor())
+func NewMonitor() *event.CommandMonitor {
+	m := &monitor{
+		tracer: otel.Tracer(instrumentationName),
+		spans:  make(map[spanKey]trace.Span),
+	}
+	return &event.CommandMonitor{
+		Started:   m.started,
+		Succeeded: m.succeeded,
+		Failed:    m.failed,
+	}
+}
+
+func (m *monitor) started(ctx context.Context, evt *event.CommandStartedEvent) {
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemMongoDB,
+		semconv.DBNamespace(evt.DatabaseName),
+		semconv.DBOperationName(evt.CommandName),
+		attribute.String("db.mongodb.connection_id", evt.ConnectionID),
+	}
+	name := evt.CommandName
+	if collection, ok := collectionName(evt); ok {
+		attrs = append(attrs, semconv.DBCollectionName(collection))
+		name = collection + "." + evt.CommandName
+	}
+
+	_, span := m.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+
+	m.mu.Lock()
+	m.spans[spanKey{evt.ConnectionID, evt.RequestID}] = span
+	m.mu.Unlock()
+}
+
+func (m *monitor) succeeded(_ context.Context, evt *event.CommandSucceededEvent) {
+	if span, ok := m.finish(evt.CommandFinishedEvent); ok {
+		span.End()
+	}
+}
+
+func (m *monitor) failed(_ context.Context, evt *event.CommandFailedEvent) {
+	if span, ok := m.finish(evt.CommandFinishedEvent); ok {
+		span.SetStatus(codes.Error, evt.Failure)
+		span.End()
+	}
+}
+
+// finish removes and returns the span of a completed command.
+func (m *monitor) finish(evt event.CommandFinishedEvent) (trace.Span, bool) {
+	key := spanKey{evt.ConnectionID, evt.RequestID}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	span, ok := m.spans[key]
+	delete(m.spans, key)
+	return span, ok
+}
+
+// collectionName returns the collection a command targets, which by
+// convention is the string value of the command's first element.
+func collectionName(evt *event.CommandStartedEvent) (string, bool) {
+	elem, err := evt.Command.IndexErr(0)
+	if err != nil {
+		return "", false
+	}
+	return elem.Value().StringValueOK()
+}
@@ -0,0 +1,160 @@
+// Package telemetrynats propagates trace context through NATS message headers
+// and records messaging spans and throughput metrics for publishers and
+// subscribers.
+package telemetrynats
+
+import (
+	"context"
+	"time"
+
+	"github.com/luciano-personal-org/telemetry"
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	instrumentationName = "github.com/luciano-personal-org/telemetry/telemetrynats"
+	system              = "nats"
+)
+
+// HeaderCarrier adapts nats.Header to a propagation.TextMapCarrier. Unlike
+// propagation.HeaderCarrier it keeps keys as-is, since NATS headers are case
+// sensitive.
+type HeaderCarrier nats.Header
+
+// Compile time check that HeaderCarrier implements the TextMapCarrier.
+var _ propagation.TextMapCarrier = HeaderCarrier{}
+
+// Get returns the first value associated with the passed key.
+func (c HeaderCarrier) Get(key string) string {
+	if v := c[key]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// Set stores the key-value pair.
+func (c HeaderCarrier) Set(key, value string) {
+	c[key] = []string{value}
+}
+
+// Keys lists the keys stored in this carrier.
+func (c HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Publish sends data to subject in a producer span, carrying the trace
+// context in the message headers.
+func Publish(ctx context.Context, nc *nats.Conn, subject string, data []byte) error {
+	return PublishMsg(ctx, nc, nats.NewMsg(subject), data)
+}
+
+// PublishMsg is like Publish but sends msg, preserving its reply subject and
+// existing headers. If data is not nil it replaces msg.Data.
+func PublishMsg(ctx context.Context, nc *nats.Conn, msg *nats.Msg, data []byte) error {
+	if data != nil {
+		msg.Data = data
+	}
+	if msg.Header == nil {
+		msg.Header = nats.Header{}
+	}
+
+	ctx, span := telemetry.StartProducerSpan(ctx, system, msg.Subject, HeaderCarrier(msg.Header))
+	defer span.End()
+	span.SetAttributes(semconv.MessagingMessageBodySize(len(msg.Data)))
+
+	err := nc.PublishMsg(msg)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	recordPublished(ctx, msg.Subject, err)
+	return err
+}
+
+// MsgHandler processes a message in the context of its consumer span.
+type MsgHandler func(ctx context.Context, msg *nats.Msg)
+
+// Subscribe subscribes to subject and runs handler for every message in a
+// consumer span that continues the publisher's trace.
+func Subscribe(nc *nats.Conn, subject string, handler MsgHandler) (*nats.Subscription, error) {
+	return nc.Subscribe(subject, WrapHandler(handler))
+}
+
+// QueueSubscribe is like Subscribe but joins the queue group queue.
+func QueueSubscribe(nc *nats.Conn, subject, queue string, handler MsgHandler) (*nats.Subscription, error) {
+	return nc.QueueSubscribe(subject, queue, WrapHandler(handler))
+}
+
+// WrapHandler turns handler into a nats.MsgHandler that extracts the trace
+// context from each message and records consumer metrics.
+func WrapHandler(handler MsgHandler) nats.MsgHandler {
+	meter := otel.Meter(instrumentationName)
+	consumed, err := meter.Int64Counter("messaging.client.consumed.messages",
+		metric.WithUnit("{message}"),
+		metric.WithDescription("Number of messages received from NATS."))
+	if err != nil {
+		otel.Handle(err)
+	}
+	duration, err := meter.Float64Histogram("messaging.process.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of processing received NATS messages."))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(msg *nats.Msg) {
+		carrier := HeaderCarrier(msg.Header)
+		if msg.Header == nil {
+			carrier = HeaderCarrier{}
+		}
+		ctx, span := telemetry.StartConsumerSpan(context.Background(), system, msg.Subject, carrier)
+		defer span.End()
+		span.SetAttributes(semconv.MessagingMessageBodySize(len(msg.Data)))
+		if msg.Sub != nil && msg.Sub.Queue != "" {
+			span.SetAttributes(attribute.String("messaging.nats.queue", msg.Sub.Queue))
+		}
+
+		start := time.Now()
+		handler(ctx, msg)
+
+		opt := metric.WithAttributes(
+			semconv.MessagingSystemKey.String(system),
+			semconv.MessagingDestinationName(msg.Subject),
+		)
+		consumed.Add(ctx, 1, opt)
+		duration.Record(ctx, time.Since(start).Seconds(), opt)
+	}
+}
+
+// published returns the counter of the messages sent by PublishMsg. It is
+// looked up on each publish to follow the global meter provider.
+func published() metric.Int64Counter {
+	counter, err := otel.Meter(instrumentationName).Int64Counter("messaging.client.published.messages",
+		metric.WithUnit("{message}"),
+		metric.WithDescription("Number of messages published to NATS."))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return counter
+}
+
+func recordPublished(ctx context.Context, subject string, err error) {
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKey.String(system),
+		semconv.MessagingDestinationName(subject),
+	}
+	if err != nil {
+		attrs = append(attrs, semconv.ErrorTypeOther)
+	}
+	published().Add(ctx, 1, metric.WithAttributes(attrs...))
+}
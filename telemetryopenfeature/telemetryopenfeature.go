@@ -0,0 +1,130 @@
+// Package telemetryopenfeature drives the runtime toggles of the telemetry
+// package from OpenFeature flags, so sampling and export can be changed
+// through the feature flag system instead of redeploying.
+package telemetryopenfeature
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/luciano-personal-org/telemetry"
+	"github.com/open-feature/go-sdk/openfeature"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// refreshInterval is how long an evaluated flag value is reused before the
+// provider is asked again, unless it reports a configuration change.
+const refreshInterval = time.Second
+
+// Flags names the flags controlling the telemetry. An empty key leaves the
+// corresponding setting alone.
+type Flags struct {
+	// SamplingRatio is a float flag with the fraction of traces to sample,
+	// 1 when it can't be evaluated. Child spans follow their parent.
+	SamplingRatio string
+	// DebugExporter is a boolean flag writing spans and log records to
+	// stdout while true, false when it can't be evaluated.
+	DebugExporter string
+	// Traces, Metrics and Logs are boolean flags enabling the export of
+	// each signal, true when they can't be evaluated.
+	Traces  string
+	Metrics string
+	Logs    string
+}
+
+// Options returns the telemetry options applying the flags of client, to be
+// passed to the setup function, and a function removing the event handler
+// the options register on client, to be called once the pipeline is shut
+// down:
+//
+//	client := openfeature.NewClient("telemetry")
+//	opts, unregister := telemetryopenfeature.Options(client, telemetryopenfeature.Flags{
+//		SamplingRatio: "telemetry-sampling-ratio",
+//		Logs:          "telemetry-export-logs",
+//	})
+//	defer unregister()
+//	shutdown, err := telemetry.SetupOTelSDKGrpc(ctx, opts...)
+//
+// Flag values are cached for a second and refreshed as soon as the provider
+// reports a configuration change.
+func Options(client *openfeature.Client, flags Flags) ([]telemetry.Option, func()) {
+	w := &watcher{}
+	w.callback = func(openfeature.EventDetails) { w.generation.Add(1) }
+	client.AddHandler(openfeature.ProviderConfigChange, &w.callback)
+	var once sync.Once
+	unregister := func() {
+		once.Do(func() { client.RemoveHandler(openfeature.ProviderConfigChange, &w.callback) })
+	}
+
+	var opts []telemetry.Option
+	if flags.SamplingRatio != "" {
+		ratio := newFloatFlag(w, client, flags.SamplingRatio, 1)
+		opts = append(opts, telemetry.WithSampler(trace.ParentBased(telemetry.DynamicRatioSampler(ratio))))
+	}
+	if flags.DebugExporter != "" {
+		opts = append(opts, telemetry.WithDebugExporter(newBoolFlag(w, client, flags.DebugExporter, false)))
+	}
+	if flags.Traces != "" || flags.Metrics != "" || flags.Logs != "" {
+		opts = append(opts, telemetry.WithExportToggles(
+			newBoolFlag(w, client, flags.Traces, true),
+			newBoolFlag(w, client, flags.Metrics, true),
+			newBoolFlag(w, client, flags.Logs, true)))
+	}
+	return opts, unregister
+}
+
+// watcher tracks the configuration changes reported by the provider.
+type watcher struct {
+	callback   func(openfeature.EventDetails)
+	generation atomic.Int64
+}
+
+// cachedFlag is a flag value reevaluated at most once per refreshInterval,
+// or after a configuration change.
+type cachedFlag[T any] struct {
+	watcher *watcher
+	eval    func() T
+
+	mu         sync.Mutex
+	value      T
+	at         time.Time
+	generation int64
+}
+
+func (f *cachedFlag[T]) get() T {
+	generation := f.watcher.generation.Load()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.at.IsZero() || time.Since(f.at) >= refreshInterval || generation != f.generation {
+		f.value = f.eval()
+		f.at = time.Now()
+		f.generation = generation
+	}
+	return f.value
+}
+
+// newBoolFlag returns a function evaluating the boolean flag key, or nil if
+// key is empty.
+func newBoolFlag(w *watcher, client *openfeature.Client, key string, def bool) func() bool {
+	if key == "" {
+		return nil
+	}
+	f := &cachedFlag[bool]{watcher: w, eval: func() bool {
+		// On error the default value is returned.
+		v, _ := client.BooleanValue(context.Background(), key, def, openfeature.EvaluationContext{})
+		return v
+	}}
+	return f.get
+}
+
+// newFloatFlag returns a function evaluating the float flag key.
+func newFloatFlag(w *watcher, client *openfeature.Client, key string, def float64) func() float64 {
+	f := &cachedFlag[float64]{watcher: w, eval: func() float64 {
+		// On error the default value is returned.
+		v, _ := client.FloatValue(context.Background(), key, def, openfeature.EvaluationContext{})
+		return v
+	}}
+	return f.get
+}
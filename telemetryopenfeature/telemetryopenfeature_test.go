@@ -0,0 +1,146 @@
+package telemetryopenfeature
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luciano-personal-org/telemetry"
+	"github.com/open-feature/go-sdk/openfeature"
+	"go.opentelemetry.io/otel"
+)
+
+// flagProvider serves flags that can be changed, reporting each change as a
+// configuration change event.
+type flagProvider struct {
+	openfeature.NoopProvider
+	events chan openfeature.Event
+
+	mu    sync.Mutex
+	flags map[string]any
+}
+
+func newFlagProvider(flags map[string]any) *flagProvider {
+	return &flagProvider{events: make(chan openfeature.Event, 1), flags: flags}
+}
+
+func (p *flagProvider) EventChannel() <-chan openfeature.Event {
+	return p.events
+}
+
+// set changes flag, reporting the change if notify is set.
+func (p *flagProvider) set(flag string, value any, notify bool) {
+	p.mu.Lock()
+	p.flags[flag] = value
+	p.mu.Unlock()
+	if notify {
+		p.events <- openfeature.Event{ProviderName: "flags", EventType: openfeature.ProviderConfigChange}
+	}
+}
+
+func (p *flagProvider) value(flag string) (any, openfeature.ProviderResolutionDetail) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	v, ok := p.flags[flag]
+	if !ok {
+		return nil, openfeature.ProviderResolutionDetail{ResolutionError: openfeature.NewFlagNotFoundResolutionError(flag)}
+	}
+	return v, openfeature.ProviderResolutionDetail{Reason: openfeature.StaticReason}
+}
+
+func (p *flagProvider) BooleanEvaluation(_ context.Context, flag string, def bool, _ openfeature.FlattenedContext) openfeature.BoolResolutionDetail {
+	v, detail := p.value(flag)
+	b, ok := v.(bool)
+	if !ok {
+		b = def
+	}
+	return openfeature.BoolResolutionDetail{Value: b, ProviderResolutionDetail: detail}
+}
+
+func (p *flagProvider) FloatEvaluation(_ context.Context, flag string, def float64, _ openfeature.FlattenedContext) openfeature.FloatResolutionDetail {
+	v, detail := p.value(flag)
+	f, ok := v.(float64)
+	if !ok {
+		f = def
+	}
+	return openfeature.FloatResolutionDetail{Value: f, ProviderResolutionDetail: detail}
+}
+
+// newClient returns a client of provider, in a domain of its own.
+func newClient(t *testing.T, provider *flagProvider) *openfeature.Client {
+	t.Helper()
+	if err := openfeature.SetNamedProviderAndWait(t.Name(), provider); err != nil {
+		t.Fatal(err)
+	}
+	return openfeature.NewClient(t.Name())
+}
+
+func TestOptionsSamplingRatio(t *testing.T) {
+	ctx := context.Background()
+	provider := newFlagProvider(map[string]any{"sampling-ratio": 0.0})
+	opts, unregister := Options(newClient(t, provider), Flags{SamplingRatio: "sampling-ratio"})
+	defer unregister()
+	shutdown, err := telemetry.SetupOTelSDKStdout(ctx, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(ctx)
+
+	sampled := func() bool {
+		_, span := otel.Tracer("test").Start(ctx, "work")
+		defer span.End()
+		return span.SpanContext().IsSampled()
+	}
+	if sampled() {
+		t.Error("span sampled with a ratio of 0")
+	}
+
+	// The value is cached until the provider reports the change.
+	provider.set("sampling-ratio", 1.0, false)
+	if sampled() {
+		t.Error("span sampled before the change was reported")
+	}
+	provider.set("sampling-ratio", 1.0, true)
+	deadline := time.Now().Add(5 * time.Second)
+	for !sampled() {
+		if time.Now().After(deadline) {
+			t.Fatal("span not sampled after the ratio changed to 1")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestOptionsWithoutFlags(t *testing.T) {
+	opts, unregister := Options(newClient(t, newFlagProvider(map[string]any{})), Flags{})
+	defer unregister()
+	if len(opts) != 0 {
+		t.Errorf("got %d options, want none", len(opts))
+	}
+}
+
+func TestBoolFlag(t *testing.T) {
+	provider := newFlagProvider(map[string]any{"debug": true})
+	client := newClient(t, provider)
+	w := &watcher{}
+
+	if f := newBoolFlag(w, client, "", true); f != nil {
+		t.Error("got a function for an empty key")
+	}
+	if f := newBoolFlag(w, client, "missing", true); !f() {
+		t.Error("missing flag: got false, want the default true")
+	}
+
+	debug := newBoolFlag(w, client, "debug", false)
+	if !debug() {
+		t.Fatal("got false, want true")
+	}
+	provider.set("debug", false, false)
+	if !debug() {
+		t.Error("value not cached")
+	}
+	w.generation.Add(1)
+	if debug() {
+		t.Error("value not reevaluated after a configuration change")
+	}
+}
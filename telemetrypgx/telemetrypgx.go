@@ -0,0 +1,189 @@
+// Package telemetrypgx traces pgx queries and reports pgxpool connection
+// metrics using the providers configured by the telemetry package.
+package telemetrypgx
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/luciano-personal-org/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/luciano-personal-org/telemetry/telemetrypgx"
+
+// Tracer implements pgx.QueryTracer and pgxpool.AcquireTracer. Set it as the
+// Tracer of a pgx.ConnConfig (or pgxpool.Config.ConnConfig) to get a client
+// span per query and a histogram of pool acquire wait times.
+type Tracer struct {
+	tracer     trace.Tracer
+	waitTime   metric.Float64Histogram
+	statements StatementMode
+}
+
+// StatementMode selects how queries are recorded in the db.query.text span
+// attribute.
+type StatementMode int
+
+const (
+	// StatementSanitized records queries with their literals replaced by
+	// placeholders, see telemetry.SanitizeSQL.
+	StatementSanitized StatementMode = iota
+	// StatementRaw records queries as they were sent.
+	StatementRaw
+	// StatementOmitted doesn't record queries.
+	StatementOmitted
+)
+
+// Option configures a Tracer.
+type Option func(*Tracer)
+
+// WithStatementMode selects how queries are recorded on spans. They are
+// sanitized by default, to avoid leaking the data they carry into traces.
+func WithStatementMode(mode StatementMode) Option {
+	return func(t *Tracer) {
+		t.statements = mode
+	}
+}
+
+var (
+	_ pgx.QueryTracer       = (*Tracer)(nil)
+	_ pgxpool.AcquireTracer = (*Tracer)(nil)
+)
+
+// NewTracer creates a Tracer backed by the global tracer and meter providers.
+func NewTracer(opts ...Option) (*Tracer, error) {
+	waitTime, err := otel.GetMeterProvider().Meter(instrumentationName).Float64Histogram(
+		"db.client.connection.wait_time",
+		metric.WithUnit("s"),
+		metric.WithDescription("Time it took to obtain a connection from the pool."))
+	if err != nil {
+		return nil, err
+	}
+	t := &Tracer{
+		tracer:   otel.Tracer(instrumentationName),
+		waitTime: waitTime,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t, nil
+}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (t *Tracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := operationName(data.SQL)
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationName(operation),
+	}
+	switch t.statements {
+	case StatementSanitized:
+		attrs = append(attrs, semconv.DBQueryText(telemetry.SanitizeSQL(data.SQL)))
+	case StatementRaw:
+		attrs = append(attrs, semconv.DBQueryText(data.SQL))
+	}
+	if conn != nil {
+		cfg := conn.Config()
+		attrs = append(attrs,
+			semconv.DBNamespace(cfg.Database),
+			semconv.ServerAddress(cfg.Host),
+			semconv.ServerPort(int(cfg.Port)),
+		)
+	}
+
+	ctx, _ = t.tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+	return ctx
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (t *Tracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+		return
+	}
+	span.SetAttributes(attribute.Int64("db.response.rows_affected", data.CommandTag.RowsAffected()))
+}
+
+type acquireStartKey struct{}
+
+// TraceAcquireStart implements pgxpool.AcquireTracer.
+func (t *Tracer) TraceAcquireStart(ctx context.Context, _ *pgxpool.Pool, _ pgxpool.TraceAcquireStartData) context.Context {
+	return context.WithValue(ctx, acquireStartKey{}, time.Now())
+}
+
+// TraceAcquireEnd implements pgxpool.AcquireTracer.
+func (t *Tracer) TraceAcquireEnd(ctx context.Context, _ *pgxpool.Pool, data pgxpool.TraceAcquireEndData) {
+	start, ok := ctx.Value(acquireStartKey{}).(time.Time)
+	if !ok {
+		return
+	}
+	attrs := []attribute.KeyValue{semconv.DBSystemPostgreSQL}
+	if data.Err != nil {
+		attrs = append(attrs, semconv.ErrorTypeOther)
+	}
+	t.waitTime.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+}
+
+// RecordPoolStats reports the connection counts of pool as gauges on every
+// metric collection. Call Unregister on the result when the pool is closed.
+func RecordPoolStats(pool *pgxpool.Pool) (metric.Registration, error) {
+	meter := otel.GetMeterProvider().Meter(instrumentationName)
+
+	usage, err := meter.Int64ObservableUpDownCounter("db.client.connection.count",
+		metric.WithUnit("{connection}"),
+		metric.WithDescription("Number of connections in the pool by state."))
+	if err != nil {
+		return nil, err
+	}
+	maxConns, err := meter.Int64ObservableUpDownCounter("db.client.connection.max",
+		metric.WithUnit("{connection}"),
+		metric.WithDescription("Maximum number of connections allowed in the pool."))
+	if err != nil {
+		return nil, err
+	}
+	acquires, err := meter.Int64ObservableCounter("db.client.connection.acquires",
+		metric.WithUnit("{acquire}"),
+		metric.WithDescription("Number of successful connection acquires from the pool."))
+	if err != nil {
+		return nil, err
+	}
+
+	name := pool.Config().ConnConfig.Database
+	poolAttr := attribute.String("db.client.connection.pool.name", name)
+	used := metric.WithAttributes(poolAttr, attribute.String("db.client.connection.state", "used"))
+	idle := metric.WithAttributes(poolAttr, attribute.String("db.client.connection.state", "idle"))
+	pooled := metric.WithAttributes(poolAttr)
+
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		stat := pool.Stat()
+		o.ObserveInt64(usage, int64(stat.AcquiredConns()), used)
+		o.ObserveInt64(usage, int64(stat.IdleConns()), idle)
+		o.ObserveInt64(maxConns, int64(stat.MaxConns()), pooled)
+		o.ObserveInt64(acquires, stat.AcquireCount(), pooled)
+		return nil
+	}, usage, maxConns, acquires)
+}
+
+// operationName returns the leading SQL keyword of query, e.g. SELECT.
+func operationName(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "query"
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,215 @@
+// Package telemetrytest records the spans emitted by instrumented code in
+// memory and provides assertions on them, so telemetry can be part of
+// contract tests.
+//
+//	func TestGetOrders(t *testing.T) {
+//		telemetrytest.Setup(t)
+//		// ... exercise the handler ...
+//		telemetrytest.AssertSpan(t, "GET /orders").
+//			WithAttr("http.response.status_code", 200).
+//			WithParent("handler")
+//	}
+package telemetrytest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// current is the recorder installed by the last call to Setup.
+var current struct {
+	sync.Mutex
+	recorder *Recorder
+}
+
+// Recorder keeps the spans ended through its tracer provider in memory.
+type Recorder struct {
+	*tracetest.SpanRecorder
+	TracerProvider *sdktrace.TracerProvider
+}
+
+// NewRecorder returns a recorder with its own tracer provider, sampling and
+// synchronously recording every span.
+func NewRecorder() *Recorder {
+	sr := tracetest.NewSpanRecorder()
+	return &Recorder{
+		SpanRecorder: sr,
+		TracerProvider: sdktrace.NewTracerProvider(
+			sdktrace.WithSampler(sdktrace.AlwaysSample()),
+			sdktrace.WithSpanProcessor(sr),
+		),
+	}
+}
+
+// Setup installs a new recorder's tracer provider as the global one for the
+// duration of the test and makes it the recorder used by AssertSpan. The
+// previous global tracer provider is restored when the test ends.
+func Setup(t testing.TB) *Recorder {
+	t.Helper()
+	r := NewRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(r.TracerProvider)
+
+	current.Lock()
+	prevRecorder := current.recorder
+	current.recorder = r
+	current.Unlock()
+
+	t.Cleanup(func() {
+		_ = r.TracerProvider.Shutdown(context.Background())
+		otel.SetTracerProvider(prev)
+		current.Lock()
+		current.recorder = prevRecorder
+		current.Unlock()
+	})
+	return r
+}
+
+// AssertSpan asserts that the recorder installed by Setup recorded a span
+// named name. See Recorder.AssertSpan.
+func AssertSpan(t testing.TB, name string) *SpanAssertion {
+	t.Helper()
+	current.Lock()
+	r := current.recorder
+	current.Unlock()
+	if r == nil {
+		t.Fatal("telemetrytest: AssertSpan called without Setup")
+	}
+	return r.AssertSpan(t, name)
+}
+
+// AssertSpan asserts that r recorded an ended span named name. The returned
+// assertion narrows down the matching spans with each condition chained to
+// it, and reports a test error as soon as none is left.
+func (r *Recorder) AssertSpan(t testing.TB, name string) *SpanAssertion {
+	t.Helper()
+	a := &SpanAssertion{t: t, recorder: r, desc: fmt.Sprintf("span %q", name)}
+	for _, s := range r.Ended() {
+		if s.Name() == name {
+			a.spans = append(a.spans, s)
+		}
+	}
+	if len(a.spans) == 0 {
+		a.fail(fmt.Sprintf("recorded spans: %s", spanNames(r.Ended())))
+	}
+	return a
+}
+
+// SpanAssertion is a set of conditions on a recorded span.
+type SpanAssertion struct {
+	t        testing.TB
+	recorder *Recorder
+	desc     string
+	spans    []sdktrace.ReadOnlySpan
+	failed   bool
+}
+
+// WithAttr asserts that the span has the attribute key with the given value.
+// Values are compared by their string representation, so
+// WithAttr("http.response.status_code", 200) matches an int64 attribute.
+func (a *SpanAssertion) WithAttr(key string, value any) *SpanAssertion {
+	a.t.Helper()
+	want := fmt.Sprint(value)
+	return a.filter(fmt.Sprintf("with %s=%s", key, want), func(s sdktrace.ReadOnlySpan) bool {
+		for _, kv := range s.Attributes() {
+			if string(kv.Key) == key {
+				return kv.Value.Emit() == want
+			}
+		}
+		return false
+	})
+}
+
+// WithParent asserts that the span is a child of a recorded span named name.
+func (a *SpanAssertion) WithParent(name string) *SpanAssertion {
+	a.t.Helper()
+	return a.filter(fmt.Sprintf("with parent %q", name), func(s sdktrace.ReadOnlySpan) bool {
+		for _, p := range a.recorder.Ended() {
+			if p.SpanContext().SpanID() == s.Parent().SpanID() {
+				return p.Name() == name
+			}
+		}
+		return false
+	})
+}
+
+// WithKind asserts that the span has the given kind.
+func (a *SpanAssertion) WithKind(kind trace.SpanKind) *SpanAssertion {
+	a.t.Helper()
+	return a.filter(fmt.Sprintf("of kind %v", kind), func(s sdktrace.ReadOnlySpan) bool {
+		return s.SpanKind() == kind
+	})
+}
+
+// WithStatus asserts that the span has the given status code.
+func (a *SpanAssertion) WithStatus(code codes.Code) *SpanAssertion {
+	a.t.Helper()
+	return a.filter(fmt.Sprintf("with status %v", code), func(s sdktrace.ReadOnlySpan) bool {
+		return s.Status().Code == code
+	})
+}
+
+// WithEvent asserts that the span has an event named name.
+func (a *SpanAssertion) WithEvent(name string) *SpanAssertion {
+	a.t.Helper()
+	return a.filter(fmt.Sprintf("with event %q", name), func(s sdktrace.ReadOnlySpan) bool {
+		for _, e := range s.Events() {
+			if e.Name == name {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Span returns the first span matching every condition, or nil if none does.
+func (a *SpanAssertion) Span() sdktrace.ReadOnlySpan {
+	if len(a.spans) == 0 {
+		return nil
+	}
+	return a.spans[0]
+}
+
+// filter keeps the spans for which match returns true and reports an error
+// if none is left.
+func (a *SpanAssertion) filter(cond string, match func(sdktrace.ReadOnlySpan) bool) *SpanAssertion {
+	a.t.Helper()
+	if a.failed {
+		return a
+	}
+	candidates := a.spans
+	a.spans = nil
+	for _, s := range candidates {
+		if match(s) {
+			a.spans = append(a.spans, s)
+		}
+	}
+	a.desc += " " + cond
+	if len(a.spans) == 0 {
+		a.fail(fmt.Sprintf("%d span(s) matched the previous conditions", len(candidates)))
+	}
+	return a
+}
+
+func (a *SpanAssertion) fail(detail string) {
+	a.t.Helper()
+	a.failed = true
+	a.t.Errorf("telemetrytest: no %s; %s", a.desc, detail)
+}
+
+func spanNames(spans []sdktrace.ReadOnlySpan) string {
+	names := make([]string, len(spans))
+	for i, s := range spans {
+		names[i] = fmt.Sprintf("%q", s.Name())
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
@@ -0,0 +1,135 @@
+package telemetrytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordingT records the errors reported by the assertions instead of
+// failing the test.
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertSpan(t *testing.T) {
+	r := NewRecorder()
+	tracer := r.TracerProvider.Tracer("test")
+	ctx, handler := tracer.Start(context.Background(), "handler")
+	_, span := tracer.Start(ctx, "GET /orders", trace.WithSpanKind(trace.SpanKindServer))
+	span.SetAttributes(attribute.Int("http.response.status_code", 200))
+	span.AddEvent("cache miss")
+	span.SetStatus(codes.Error, "timeout")
+	span.End()
+	handler.End()
+
+	tests := []struct {
+		name     string
+		assert   func(t testing.TB) *SpanAssertion
+		wantFail bool
+	}{
+		{
+			name: "all conditions",
+			assert: func(t testing.TB) *SpanAssertion {
+				return r.AssertSpan(t, "GET /orders").
+					WithAttr("http.response.status_code", 200).
+					WithParent("handler").
+					WithKind(trace.SpanKindServer).
+					WithStatus(codes.Error).
+					WithEvent("cache miss")
+			},
+		},
+		{
+			name:     "unknown name",
+			assert:   func(t testing.TB) *SpanAssertion { return r.AssertSpan(t, "GET /users") },
+			wantFail: true,
+		},
+		{
+			name: "attribute value",
+			assert: func(t testing.TB) *SpanAssertion {
+				return r.AssertSpan(t, "GET /orders").WithAttr("http.response.status_code", 500)
+			},
+			wantFail: true,
+		},
+		{
+			name: "missing attribute",
+			assert: func(t testing.TB) *SpanAssertion {
+				return r.AssertSpan(t, "GET /orders").WithAttr("http.route", "/orders")
+			},
+			wantFail: true,
+		},
+		{
+			name: "root span has no parent",
+			assert: func(t testing.TB) *SpanAssertion {
+				return r.AssertSpan(t, "handler").WithParent("GET /orders")
+			},
+			wantFail: true,
+		},
+		{
+			name: "kind",
+			assert: func(t testing.TB) *SpanAssertion {
+				return r.AssertSpan(t, "handler").WithKind(trace.SpanKindServer)
+			},
+			wantFail: true,
+		},
+		{
+			name: "event",
+			assert: func(t testing.TB) *SpanAssertion {
+				return r.AssertSpan(t, "GET /orders").WithEvent("retry")
+			},
+			wantFail: true,
+		},
+		{
+			name: "reported once",
+			assert: func(t testing.TB) *SpanAssertion {
+				return r.AssertSpan(t, "GET /orders").WithStatus(codes.Ok).WithEvent("retry")
+			},
+			wantFail: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &recordingT{TB: t}
+			a := tt.assert(rt)
+			if failed := len(rt.errors) > 0; failed != tt.wantFail {
+				t.Errorf("failed = %v, want %v: %v", failed, tt.wantFail, rt.errors)
+			}
+			if len(rt.errors) > 1 {
+				t.Errorf("reported %d errors, want 1: %v", len(rt.errors), rt.errors)
+			}
+			if (a.Span() == nil) != tt.wantFail {
+				t.Errorf("Span() = %v", a.Span())
+			}
+		})
+	}
+}
+
+func TestSetup(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	t.Run("installed", func(t *testing.T) {
+		r := Setup(t)
+		if otel.GetTracerProvider() != r.TracerProvider {
+			t.Fatal("the recorder's tracer provider is not the global one")
+		}
+		_, span := otel.Tracer("test").Start(context.Background(), "checkout")
+		span.RecordError(errors.New("declined"))
+		span.End()
+		AssertSpan(t, "checkout").WithEvent("exception")
+	})
+	if otel.GetTracerProvider() != prev {
+		t.Error("the global tracer provider was not restored")
+	}
+}
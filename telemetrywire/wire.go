@@ -0,0 +1,63 @@
+// Package telemetrywire provides a Google wire provider set installing the
+// telemetry pipeline, with its shutdown bound to the injector's cleanup.
+package telemetrywire
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/wire"
+	"github.com/luciano-personal-org/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// shutdownTimeout bounds the pipeline shutdown run by the cleanup function.
+const shutdownTimeout = 5 * time.Second
+
+// Scope is the instrumentation scope name of the provided Tracer and Meter.
+// Injectors bind it with wire.Value.
+type Scope string
+
+// ProviderSet provides the *telemetry.Telemetry handle, the
+// trace.TracerProvider and metric.MeterProvider, and a trace.Tracer and
+// metric.Meter named after the injected Scope. It requires a
+// context.Context and a []telemetry.Option.
+var ProviderSet = wire.NewSet(New, TracerProvider, MeterProvider, Tracer, Meter)
+
+// New sets up the pipeline and returns a cleanup function shutting it down.
+func New(ctx context.Context, opts []telemetry.Option) (*telemetry.Telemetry, func(), error) {
+	t, err := telemetry.New(ctx, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := t.Shutdown(ctx); err != nil {
+			otel.Handle(err)
+		}
+	}
+	return t, cleanup, nil
+}
+
+// TracerProvider returns the tracer provider of t.
+func TracerProvider(t *telemetry.Telemetry) trace.TracerProvider {
+	return t.TracerProvider
+}
+
+// MeterProvider returns the meter provider of t.
+func MeterProvider(t *telemetry.Telemetry) metric.MeterProvider {
+	return t.MeterProvider
+}
+
+// Tracer returns a tracer named scope.
+func Tracer(tp trace.TracerProvider, scope Scope) trace.Tracer {
+	return tp.Tracer(string(scope))
+}
+
+// Meter returns a meter named scope.
+func Meter(mp metric.MeterProvider, scope Scope) metric.Meter {
+	return mp.Meter(string(scope))
+}
@@ -0,0 +1,37 @@
+package telemetrywire
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/luciano-personal-org/telemetry"
+)
+
+func TestProviders(t *testing.T) {
+	ctx := context.Background()
+	handle, cleanup, err := New(ctx, []telemetry.Option{telemetry.WithStdoutWriters(io.Discard, io.Discard, io.Discard)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := telemetry.SetupOTelSDKStdout(ctx); !errors.Is(err, telemetry.ErrAlreadyInitialized) {
+		t.Errorf("setup before cleanup: got %v, want ErrAlreadyInitialized", err)
+	}
+
+	_, span := Tracer(TracerProvider(handle), "orders").Start(ctx, "work")
+	span.End()
+	if !span.SpanContext().IsValid() {
+		t.Error("provided tracer is not recording")
+	}
+	if _, err := Meter(MeterProvider(handle), "orders").Int64Counter("orders.placed"); err != nil {
+		t.Errorf("provided meter: %v", err)
+	}
+
+	cleanup()
+	shutdown, err := telemetry.SetupOTelSDKStdout(ctx, telemetry.WithStdoutWriters(io.Discard, io.Discard, io.Discard))
+	if err != nil {
+		t.Fatalf("setup after cleanup: %v", err)
+	}
+	shutdown(ctx)
+}
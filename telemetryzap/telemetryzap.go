@@ -0,0 +1,190 @@
+// Package telemetryzap provides a zapcore.Core that writes zap entries to the
+// OpenTelemetry logger provider configured by the telemetry package.
+package telemetryzap
+
+import (
+	"context"
+
+	"github.com/luciano-personal-org/telemetry/internal/logconv"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// contextKey is the key of the field created by Context.
+const contextKey = "context"
+
+// Context returns a field carrying ctx, so that the entry is correlated with
+// the span in ctx:
+//
+//	logger.Info("order placed", telemetryzap.Context(ctx))
+func Context(ctx context.Context) zap.Field {
+	return zap.Field{Key: contextKey, Type: zapcore.SkipType, Interface: ctx}
+}
+
+// Core is a zapcore.Core that emits entries as OpenTelemetry log records.
+type Core struct {
+	zapcore.LevelEnabler
+	logger log.Logger
+	attrs  []log.KeyValue
+	ctx    context.Context
+}
+
+var _ zapcore.Core = (*Core)(nil)
+
+// NewCore returns a Core emitting through a logger called name obtained from
+// the global logger provider, for entries enabled by level.
+func NewCore(name string, level zapcore.LevelEnabler) *Core {
+	return &Core{
+		LevelEnabler: level,
+		logger:       global.GetLoggerProvider().Logger(name),
+		ctx:          context.Background(),
+	}
+}
+
+// Tee returns a core that writes to both original and a new Core called name.
+// Entries written to original get trace_id and span_id fields when they carry
+// a Context field, so local logs stay correlated with traces too.
+func Tee(original zapcore.Core, name string) zapcore.Core {
+	return zapcore.NewTee(&traceCore{Core: original}, NewCore(name, original))
+}
+
+// With implements zapcore.Core.
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	attrs, ctx := convertFields(fields)
+	clone.attrs = append(c.attrs[:len(c.attrs):len(c.attrs)], attrs...)
+	if ctx != nil {
+		clone.ctx = ctx
+	}
+	return &clone
+}
+
+// Check implements zapcore.Core.
+func (c *Core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write implements zapcore.Core.
+func (c *Core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	attrs, ctx := convertFields(fields)
+	if ctx == nil {
+		ctx = c.ctx
+	}
+
+	var rec log.Record
+	rec.SetTimestamp(ent.Time)
+	rec.SetBody(log.StringValue(ent.Message))
+	rec.SetSeverity(severity(ent.Level))
+	rec.SetSeverityText(ent.Level.String())
+	rec.AddAttributes(c.attrs...)
+	rec.AddAttributes(attrs...)
+	if ent.LoggerName != "" {
+		rec.AddAttributes(log.String("logger", ent.LoggerName))
+	}
+	if ent.Caller.Defined {
+		rec.AddAttributes(
+			log.String("code.filepath", ent.Caller.File),
+			log.Int("code.lineno", ent.Caller.Line),
+			log.String("code.function", ent.Caller.Function),
+		)
+	}
+	if ent.Stack != "" {
+		rec.AddAttributes(log.String("exception.stacktrace", ent.Stack))
+	}
+
+	c.logger.Emit(ctx, rec)
+	return nil
+}
+
+// Sync implements zapcore.Core. Records are flushed by the logger provider.
+func (c *Core) Sync() error {
+	return nil
+}
+
+// convertFields encodes zap fields as log attributes and extracts the
+// context carried by a Context field, if any.
+func convertFields(fields []zapcore.Field) ([]log.KeyValue, context.Context) {
+	var ctx context.Context
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		if c, ok := contextFromField(f); ok {
+			ctx = c
+			continue
+		}
+		f.AddTo(enc)
+	}
+	return logconv.KeyValues(enc.Fields), ctx
+}
+
+func contextFromField(f zapcore.Field) (context.Context, bool) {
+	if f.Key != contextKey || f.Type != zapcore.SkipType {
+		return nil, false
+	}
+	ctx, ok := f.Interface.(context.Context)
+	return ctx, ok
+}
+
+func severity(level zapcore.Level) log.Severity {
+	switch level {
+	case zapcore.DebugLevel:
+		return log.SeverityDebug
+	case zapcore.InfoLevel:
+		return log.SeverityInfo
+	case zapcore.WarnLevel:
+		return log.SeverityWarn
+	case zapcore.ErrorLevel:
+		return log.SeverityError
+	case zapcore.DPanicLevel:
+		return log.SeverityFatal1
+	case zapcore.PanicLevel:
+		return log.SeverityFatal2
+	case zapcore.FatalLevel:
+		return log.SeverityFatal3
+	}
+	return log.SeverityUndefined
+}
+
+// traceCore wraps a core and adds trace correlation fields to entries that
+// carry a Context field.
+type traceCore struct {
+	zapcore.Core
+}
+
+func (c *traceCore) With(fields []zapcore.Field) zapcore.Core {
+	return &traceCore{Core: c.Core.With(withTraceFields(fields))}
+}
+
+func (c *traceCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *traceCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, withTraceFields(fields))
+}
+
+// withTraceFields replaces a Context field with trace_id and span_id fields.
+func withTraceFields(fields []zapcore.Field) []zapcore.Field {
+	for i, f := range fields {
+		ctx, ok := contextFromField(f)
+		if !ok {
+			continue
+		}
+		out := append(fields[:i:i], fields[i+1:]...)
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			out = append(out,
+				zap.String("trace_id", sc.TraceID().String()),
+				zap.String("span_id", sc.SpanID().String()))
+		}
+		return out
+	}
+	return fields
+}
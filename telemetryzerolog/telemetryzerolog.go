@@ -0,0 +1,181 @@
+// Package telemetryzerolog forwards zerolog events to the OpenTelemetry logger
+// provider configured by the telemetry package.
+//
+// Writer decodes the JSON events written by zerolog and emits them as log
+// records; Hook adds trace_id, span_id and trace_flags fields to events
+// created with a context, which Writer uses to correlate records with their
+// span:
+//
+//	logger := zerolog.New(telemetryzerolog.NewWriter("orders")).Hook(telemetryzerolog.Hook{})
+//	logger.Info().Ctx(ctx).Msg("order placed")
+package telemetryzerolog
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/luciano-personal-org/telemetry/internal/logconv"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	traceIDField    = "trace_id"
+	spanIDField     = "span_id"
+	traceFlagsField = "trace_flags"
+)
+
+// Hook is a zerolog.Hook adding the trace and span IDs and the trace flags of
+// the span in the event's context as fields.
+type Hook struct{}
+
+var _ zerolog.Hook = Hook{}
+
+// Run implements zerolog.Hook.
+func (Hook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	sc := trace.SpanContextFromContext(e.GetCtx())
+	if !sc.IsValid() {
+		return
+	}
+	e.Str(traceIDField, sc.TraceID().String()).
+		Str(spanIDField, sc.SpanID().String()).
+		Str(traceFlagsField, sc.TraceFlags().String())
+}
+
+// Writer is a zerolog.LevelWriter emitting each JSON event as a log record.
+type Writer struct {
+	logger log.Logger
+}
+
+var _ zerolog.LevelWriter = (*Writer)(nil)
+
+// NewWriter returns a Writer emitting through a logger called name obtained
+// from the global logger provider. Use zerolog.MultiLevelWriter to keep
+// writing to the original output as well.
+func NewWriter(name string) *Writer {
+	return &Writer{logger: global.GetLoggerProvider().Logger(name)}
+}
+
+// Write implements io.Writer. The level is read from the event itself.
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil {
+		return 0, err
+	}
+
+	var rec log.Record
+	if msg, ok := fields[zerolog.MessageFieldName].(string); ok {
+		rec.SetBody(log.StringValue(msg))
+		delete(fields, zerolog.MessageFieldName)
+	}
+	if lvl, ok := fields[zerolog.LevelFieldName].(string); ok {
+		if parsed, err := zerolog.ParseLevel(lvl); err == nil && level == zerolog.NoLevel {
+			level = parsed
+		}
+		delete(fields, zerolog.LevelFieldName)
+	}
+	if ts, ok := fields[zerolog.TimestampFieldName].(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+			rec.SetTimestamp(t)
+			delete(fields, zerolog.TimestampFieldName)
+		}
+	}
+	rec.SetSeverity(severity(level))
+	rec.SetSeverityText(level.String())
+
+	ctx := spanContext(fields)
+	for k, v := range fields {
+		rec.AddAttributes(log.KeyValue{Key: k, Value: value(v)})
+	}
+
+	w.logger.Emit(ctx, rec)
+	return len(p), nil
+}
+
+// spanContext rebuilds the span context recorded by Hook, removing its fields.
+func spanContext(fields map[string]any) context.Context {
+	ctx := context.Background()
+	tid, _ := fields[traceIDField].(string)
+	sid, _ := fields[spanIDField].(string)
+	traceID, err := trace.TraceIDFromHex(tid)
+	if err != nil {
+		return ctx
+	}
+	spanID, err := trace.SpanIDFromHex(sid)
+	if err != nil {
+		return ctx
+	}
+	var flags trace.TraceFlags
+	if f, ok := fields[traceFlagsField].(string); ok {
+		if b, err := hex.DecodeString(f); err == nil && len(b) == 1 {
+			flags = trace.TraceFlags(b[0])
+		}
+	}
+	delete(fields, traceIDField)
+	delete(fields, spanIDField)
+	delete(fields, traceFlagsField)
+	return trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags,
+	}))
+}
+
+// value converts a decoded JSON value, keeping numbers numeric.
+func value(v any) log.Value {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return log.Int64Value(i)
+		}
+		if f, err := v.Float64(); err == nil {
+			return log.Float64Value(f)
+		}
+		return log.StringValue(v.String())
+	case map[string]any:
+		kvs := make([]log.KeyValue, 0, len(v))
+		for k, val := range v {
+			kvs = append(kvs, log.KeyValue{Key: k, Value: value(val)})
+		}
+		return log.MapValue(kvs...)
+	case []any:
+		vals := make([]log.Value, 0, len(v))
+		for _, val := range v {
+			vals = append(vals, value(val))
+		}
+		return log.SliceValue(vals...)
+	}
+	return logconv.Value(v)
+}
+
+func severity(level zerolog.Level) log.Severity {
+	switch level {
+	case zerolog.TraceLevel:
+		return log.SeverityTrace
+	case zerolog.DebugLevel:
+		return log.SeverityDebug
+	case zerolog.InfoLevel:
+		return log.SeverityInfo
+	case zerolog.WarnLevel:
+		return log.SeverityWarn
+	case zerolog.ErrorLevel:
+		return log.SeverityError
+	case zerolog.FatalLevel:
+		return log.SeverityFatal
+	case zerolog.PanicLevel:
+		return log.SeverityFatal2
+	}
+	return log.SeverityUndefined
+}
@@ -0,0 +1,47 @@
+package telemetryzerolog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSpanContextKeepsTraceFlags(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags trace.TraceFlags
+	}{
+		{"sampled", trace.FlagsSampled},
+		{"unsampled", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    trace.TraceID{1, 2, 3},
+				SpanID:     trace.SpanID{4, 5, 6},
+				TraceFlags: tt.flags,
+			})
+			var buf bytes.Buffer
+			logger := zerolog.New(&buf).Hook(Hook{})
+			logger.Info().Ctx(trace.ContextWithSpanContext(context.Background(), want)).Msg("hello")
+
+			dec := json.NewDecoder(&buf)
+			dec.UseNumber()
+			var fields map[string]any
+			if err := dec.Decode(&fields); err != nil {
+				t.Fatal(err)
+			}
+			got := trace.SpanContextFromContext(spanContext(fields))
+			if !got.Equal(want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+			if _, ok := fields[traceFlagsField]; ok {
+				t.Error("the trace_flags field was kept as an attribute")
+			}
+		})
+	}
+}
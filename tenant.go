@@ -0,0 +1,103 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// TenantKey is the baggage member and span attribute identifying the tenant
+// telemetry belongs to.
+const TenantKey = "tenant.id"
+
+// tenantKey is the context key of the tenant set by WithTenant.
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx bound to tenant. Spans started from it
+// are stamped with the tenant.id attribute, and the tenant is propagated to
+// downstream services through the baggage. Call it from trusted code, once
+// the caller is authenticated: the tenant.id baggage received from other
+// services is only informational, as callers can set it to anything, and is
+// never used to stamp or route spans.
+func WithTenant(ctx context.Context, tenant string) (context.Context, error) {
+	ctx = context.WithValue(ctx, tenantKey{}, tenant)
+	return SetBaggage(ctx, TenantKey, tenant)
+}
+
+// Tenant returns the tenant ctx was bound to by WithTenant, or "" if there
+// is none.
+func Tenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// WithTenantExporter routes the spans of tenant to exporter instead of the
+// default exporter, so each customer's telemetry can be sent to its own
+// endpoint. Exporters are shut down with the pipeline.
+func WithTenantExporter(tenant string, exporter trace.SpanExporter) Option {
+	return func(c *config) {
+		if c.tenantExporters == nil {
+			c.tenantExporters = make(map[string]trace.SpanExporter)
+		}
+		c.tenantExporters[tenant] = exporter
+	}
+}
+
+// tenantStamper is a span processor adding the tenant of the parent context
+// as a span attribute.
+type tenantStamper struct{}
+
+func (tenantStamper) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	if tenant := Tenant(parent); tenant != "" {
+		s.SetAttributes(attribute.String(TenantKey, tenant))
+	}
+}
+
+func (tenantStamper) OnEnd(trace.ReadOnlySpan)         {}
+func (tenantStamper) Shutdown(context.Context) error   { return nil }
+func (tenantStamper) ForceFlush(context.Context) error { return nil }
+
+// tenantRouter is a span exporter sending each span to the exporter of its
+// tenant, or to fallback for spans without a registered tenant.
+type tenantRouter struct {
+	fallback trace.SpanExporter
+	tenants  map[string]trace.SpanExporter
+}
+
+func (r *tenantRouter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	batches := make(map[trace.SpanExporter][]trace.ReadOnlySpan)
+	for _, s := range spans {
+		exporter := r.fallback
+		if tenant, ok := spanTenant(s); ok {
+			if e, ok := r.tenants[tenant]; ok {
+				exporter = e
+			}
+		}
+		batches[exporter] = append(batches[exporter], s)
+	}
+
+	var err error
+	for exporter, batch := range batches {
+		err = errors.Join(err, exporter.ExportSpans(ctx, batch))
+	}
+	return err
+}
+
+func (r *tenantRouter) Shutdown(ctx context.Context) error {
+	err := r.fallback.Shutdown(ctx)
+	for _, e := range r.tenants {
+		err = errors.Join(err, e.Shutdown(ctx))
+	}
+	return err
+}
+
+func spanTenant(s trace.ReadOnlySpan) (string, bool) {
+	for _, kv := range s.Attributes() {
+		if kv.Key == TenantKey {
+			return kv.Value.AsString(), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,54 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTenantRouting(t *testing.T) {
+	inbound := func() context.Context {
+		header := http.Header{"Baggage": {"tenant.id=acme"}}
+		return propagation.Baggage{}.Extract(context.Background(), propagation.HeaderCarrier(header))
+	}
+	trusted := func() context.Context {
+		ctx, err := WithTenant(context.Background(), "acme")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ctx
+	}
+	tests := []struct {
+		name     string
+		ctx      func() context.Context
+		wantAcme bool
+	}{
+		{"no tenant", context.Background, false},
+		{"inbound baggage", inbound, false},
+		{"WithTenant", trusted, true},
+		{"detached WithTenant", func() context.Context { return Detach(trusted()) }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallback, acme := tracetest.NewInMemoryExporter(), tracetest.NewInMemoryExporter()
+			router := &tenantRouter{fallback: fallback, tenants: map[string]sdktrace.SpanExporter{"acme": acme}}
+			tp := sdktrace.NewTracerProvider(
+				sdktrace.WithSpanProcessor(tenantStamper{}),
+				sdktrace.WithSyncer(router))
+
+			_, span := tp.Tracer("test").Start(tt.ctx(), "work")
+			span.End()
+
+			if got := len(acme.GetSpans()) == 1; got != tt.wantAcme {
+				t.Errorf("routed to the tenant: got %v, want %v", got, tt.wantAcme)
+			}
+			if got := len(fallback.GetSpans()) == 1; got == tt.wantAcme {
+				t.Errorf("routed to the fallback: got %v, want %v", got, !tt.wantAcme)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WithExportTimeouts bounds each export of the trace, metric and log
+// exporters separately, so that a collector brownout on one signal doesn't
+// stall the others or shutdown for the default timeouts. Zero keeps the
+// default of a signal, which the batch processors also read in milliseconds
+// from the OTEL_BSP_EXPORT_TIMEOUT, OTEL_METRIC_EXPORT_TIMEOUT and
+// OTEL_BLRP_EXPORT_TIMEOUT environment variables.
+func WithExportTimeouts(traces, metrics, logs time.Duration) Option {
+	return func(c *config) {
+		c.traceExportTimeout = traces
+		c.metricExportTimeout = metrics
+		c.logExportTimeout = logs
+	}
+}
+
+// WithShutdownTimeouts gives the tracer, meter and logger providers their own
+// deadline to flush and shut down, so a wedged metrics flush can't consume
+// the whole termination grace period. Providers are shut down in turn, each
+// within the earlier of its deadline and the one of the context passed to
+// shutdown, and the returned error names the providers that timed out. Zero
+// only bounds a provider by the shutdown context.
+func WithShutdownTimeouts(traces, metrics, logs time.Duration) Option {
+	return func(c *config) {
+		c.traceShutdownTimeout = traces
+		c.metricShutdownTimeout = metrics
+		c.logShutdownTimeout = logs
+	}
+}
+
+// withShutdownTimeout bounds the shutdown function fn of the named provider
+// by timeout, and names the provider in its error.
+func withShutdownTimeout(name string, timeout time.Duration, fn func(context.Context) error) func(context.Context) error {
+	return func(ctx context.Context) error {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		err := fn(ctx)
+		switch {
+		case err == nil:
+			return nil
+		case errors.Is(err, context.DeadlineExceeded):
+			return fmt.Errorf("telemetry: %s provider shutdown timed out: %w", name, err)
+		default:
+			return fmt.Errorf("telemetry: %s provider shutdown: %w", name, err)
+		}
+	}
+}
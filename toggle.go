@@ -0,0 +1,137 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// WithExportToggles makes the export of each signal depend on a function
+// called before every batch, e.g. backed by a feature flag, so a signal can
+// be switched off at runtime without redeploying. The batches of a
+// disabled signal are dropped; a nil function keeps the signal enabled.
+func WithExportToggles(traces, metrics, logs func() bool) Option {
+	return func(c *config) {
+		c.exportTraces = traces
+		c.exportMetrics = metrics
+		c.exportLogs = logs
+	}
+}
+
+// WithDebugExporter additionally writes the spans and log records to stdout
+// whenever enabled returns true, to inspect the telemetry of a running
+// service.
+func WithDebugExporter(enabled func() bool) Option {
+	return func(c *config) {
+		c.debugExporter = enabled
+	}
+}
+
+// withToggles wraps the exporters of exp with the toggles of cfg.
+func withToggles(cfg config, exp exporters) (exporters, error) {
+	if cfg.exportTraces != nil {
+		exp.span = &toggledSpanExporter{SpanExporter: exp.span, enabled: cfg.exportTraces}
+	}
+	if cfg.exportMetrics != nil && exp.metric != nil {
+		exp.metric = &toggledMetricExporter{Exporter: exp.metric, enabled: cfg.exportMetrics}
+	}
+	if cfg.exportLogs != nil {
+		exp.log = &toggledLogExporter{Exporter: exp.log, enabled: cfg.exportLogs}
+	}
+	if cfg.debugExporter == nil {
+		return exp, nil
+	}
+
+	debugSpans, err := stdouttrace.New(stdoutTraceOptions(cfg, os.Stdout)...)
+	if err != nil {
+		return exporters{}, err
+	}
+	debugLogs, err := stdoutlog.New()
+	if err != nil {
+		return exporters{}, err
+	}
+	exp.span = &debugSpanExporter{SpanExporter: exp.span, debug: debugSpans, enabled: cfg.debugExporter}
+	exp.log = &debugLogExporter{Exporter: exp.log, debug: debugLogs, enabled: cfg.debugExporter}
+	return exp, nil
+}
+
+type toggledSpanExporter struct {
+	trace.SpanExporter
+	enabled func() bool
+}
+
+func (e *toggledSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	if !e.enabled() {
+		return nil
+	}
+	return e.SpanExporter.ExportSpans(ctx, spans)
+}
+
+type toggledMetricExporter struct {
+	sdkmetric.Exporter
+	enabled func() bool
+}
+
+func (e *toggledMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	if !e.enabled() {
+		return nil
+	}
+	return e.Exporter.Export(ctx, rm)
+}
+
+type toggledLogExporter struct {
+	sdklog.Exporter
+	enabled func() bool
+}
+
+func (e *toggledLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	if !e.enabled() {
+		return nil
+	}
+	return e.Exporter.Export(ctx, records)
+}
+
+// debugSpanExporter copies the spans to debug while enabled.
+type debugSpanExporter struct {
+	trace.SpanExporter
+	debug   trace.SpanExporter
+	enabled func() bool
+}
+
+func (e *debugSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	var err error
+	if e.enabled() {
+		err = e.debug.ExportSpans(ctx, spans)
+	}
+	return errors.Join(err, e.SpanExporter.ExportSpans(ctx, spans))
+}
+
+func (e *debugSpanExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.SpanExporter.Shutdown(ctx), e.debug.Shutdown(ctx))
+}
+
+// debugLogExporter copies the log records to debug while enabled.
+type debugLogExporter struct {
+	sdklog.Exporter
+	debug   sdklog.Exporter
+	enabled func() bool
+}
+
+func (e *debugLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	var err error
+	if e.enabled() {
+		err = e.debug.Export(ctx, records)
+	}
+	return errors.Join(err, e.Exporter.Export(ctx, records))
+}
+
+func (e *debugLogExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.Exporter.Shutdown(ctx), e.debug.Shutdown(ctx))
+}
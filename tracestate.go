@@ -0,0 +1,62 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SetTraceState returns a copy of ctx whose span context has the tracestate
+// member key set to value, moving it to the front as required by the W3C
+// spec. The key and value are validated, so malformed vendor entries are
+// rejected here rather than dropped by the propagator. Spans started from
+// the returned context inherit the trace state and requests propagated from
+// it carry it downstream; the current span can still be ended through it.
+func SetTraceState(ctx context.Context, key, value string) (context.Context, error) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ctx, fmt.Errorf("telemetry: setting tracestate member %q: no span context", key)
+	}
+	ts, err := sc.TraceState().Insert(key, value)
+	if err != nil {
+		return ctx, fmt.Errorf("telemetry: invalid tracestate member %q: %w", key, err)
+	}
+	return withTraceState(ctx, sc, ts), nil
+}
+
+// GetTraceState returns the value of the tracestate member key in ctx, or ""
+// if it is not set.
+func GetTraceState(ctx context.Context, key string) string {
+	return trace.SpanContextFromContext(ctx).TraceState().Get(key)
+}
+
+// DeleteTraceState returns a copy of ctx without the tracestate member key.
+func DeleteTraceState(ctx context.Context, key string) context.Context {
+	sc := trace.SpanContextFromContext(ctx)
+	if sc.TraceState().Get(key) == "" {
+		return ctx
+	}
+	return withTraceState(ctx, sc, sc.TraceState().Delete(key))
+}
+
+// withTraceState returns a copy of ctx whose span reports sc with ts.
+func withTraceState(ctx context.Context, sc trace.SpanContext, ts trace.TraceState) context.Context {
+	sc = sc.WithTraceState(ts)
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return trace.ContextWithSpanContext(ctx, sc)
+	}
+	return trace.ContextWithSpan(ctx, traceStateSpan{Span: span, sc: sc})
+}
+
+// traceStateSpan is a span reporting an updated span context, so the trace
+// state can be changed without losing the ability to end the span.
+type traceStateSpan struct {
+	trace.Span
+	sc trace.SpanContext
+}
+
+func (s traceStateSpan) SpanContext() trace.SpanContext {
+	return s.sc
+}
@@ -0,0 +1,82 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transport wraps base, or http.DefaultTransport if nil, so that every
+// request it sends gets a client span and carries the trace context using
+// the global propagator:
+//
+//	client := &http.Client{Transport: telemetry.Transport(nil)}
+//
+// Responses with a status configured as an error, 4xx and 5xx by default,
+// mark the span as failed, see WithHTTPErrorStatuses.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base, tracer: otel.Tracer(instrumentationName)}
+}
+
+type transport struct {
+	base   http.RoundTripper
+	tracer trace.Tracer
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	u := *r.URL
+	u.User = nil
+	attrs := getAttrs()
+	*attrs = append(*attrs,
+		lookupMethod(r.Method).attr,
+		semconv.URLFull(u.String()),
+		semconv.ServerAddress(r.URL.Hostname()),
+	)
+	if port := urlPort(r.URL); port > 0 {
+		*attrs = append(*attrs, semconv.ServerPort(port))
+	}
+	ctx, span := t.tracer.Start(r.Context(), r.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(*attrs...))
+	putAttrs(attrs)
+	defer span.End()
+
+	// A RoundTripper must not modify the request it is given.
+	r = r.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
+
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if isHTTPClientError(resp.StatusCode) {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+// urlPort returns the port of u, or the default port of its scheme.
+func urlPort(u *url.URL) int {
+	if port, err := strconv.Atoi(u.Port()); err == nil {
+		return port
+	}
+	switch u.Scheme {
+	case "http":
+		return 80
+	case "https":
+		return 443
+	}
+	return 0
+}
@@ -0,0 +1,152 @@
+package telemetry
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// truncationMarker is appended to the values shortened by
+// WithAttributeValueLimit, so truncated values can't be mistaken for
+// complete ones.
+const truncationMarker = "...[truncated]"
+
+// WithAttributeValueLimit truncates string attribute values of spans, span
+// events and log records longer than limit bytes before export, appending
+// "...[truncated]", so SQL statements or payload dumps don't blow up export
+// sizes. Values are cut at a UTF-8 character boundary. It can also be set
+// with the TELEMETRY_ATTRIBUTE_VALUE_LIMIT environment variable. Metric
+// attributes aren't truncated, since long values there are a cardinality
+// problem to fix at the source.
+func WithAttributeValueLimit(limit int) Option {
+	return func(c *config) {
+		c.attributeValueLimit = limit
+	}
+}
+
+// truncate returns s cut to at most limit bytes followed by the marker, or s
+// itself if it fits.
+func truncate(s string, limit int) (string, bool) {
+	if len(s) <= limit {
+		return s, false
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit] + truncationMarker, true
+}
+
+// truncateAttributes returns attrs with long string values truncated, or
+// attrs itself if none is.
+func truncateAttributes(attrs []attribute.KeyValue, limit int) []attribute.KeyValue {
+	var out []attribute.KeyValue
+	for i, kv := range attrs {
+		if v, ok := truncateAttrValue(kv.Value, limit); ok {
+			if out == nil {
+				out = append([]attribute.KeyValue(nil), attrs...)
+			}
+			out[i] = attribute.KeyValue{Key: kv.Key, Value: v}
+		}
+	}
+	if out == nil {
+		return attrs
+	}
+	return out
+}
+
+func truncateAttrValue(v attribute.Value, limit int) (attribute.Value, bool) {
+	switch v.Type() {
+	case attribute.STRING:
+		if s, ok := truncate(v.AsString(), limit); ok {
+			return attribute.StringValue(s), true
+		}
+	case attribute.STRINGSLICE:
+		values := v.AsStringSlice()
+		changed := false
+		for i, s := range values {
+			if t, ok := truncate(s, limit); ok {
+				values[i], changed = t, true
+			}
+		}
+		if changed {
+			return attribute.StringSliceValue(values), true
+		}
+	}
+	return v, false
+}
+
+// truncatingSpanExporter passes spans to the wrapped exporter with long
+// attribute values of the spans and their events truncated.
+type truncatingSpanExporter struct {
+	trace.SpanExporter
+	limit int
+}
+
+func (e *truncatingSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	truncated := make([]trace.ReadOnlySpan, len(spans))
+	for i, s := range spans {
+		truncated[i] = truncatedSpan{ReadOnlySpan: s, limit: e.limit}
+	}
+	return e.SpanExporter.ExportSpans(ctx, truncated)
+}
+
+// truncatedSpan truncates the attribute values of a finished span.
+type truncatedSpan struct {
+	trace.ReadOnlySpan
+	limit int
+}
+
+func (s truncatedSpan) Attributes() []attribute.KeyValue {
+	return truncateAttributes(s.ReadOnlySpan.Attributes(), s.limit)
+}
+
+func (s truncatedSpan) Events() []trace.Event {
+	events := s.ReadOnlySpan.Events()
+	out := make([]trace.Event, len(events))
+	for i, e := range events {
+		e.Attributes = truncateAttributes(e.Attributes, s.limit)
+		out[i] = e
+	}
+	return out
+}
+
+func (s truncatedSpan) Links() []trace.Link {
+	links := s.ReadOnlySpan.Links()
+	out := make([]trace.Link, len(links))
+	for i, l := range links {
+		l.Attributes = truncateAttributes(l.Attributes, s.limit)
+		out[i] = l
+	}
+	return out
+}
+
+// truncatingLogProcessor truncates long string attribute values of records
+// before they reach the next processors.
+type truncatingLogProcessor struct {
+	limit int
+}
+
+func (p truncatingLogProcessor) OnEmit(_ context.Context, r *sdklog.Record) error {
+	var attrs []log.KeyValue
+	changed := false
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		if kv.Value.Kind() == log.KindString {
+			if s, ok := truncate(kv.Value.AsString(), p.limit); ok {
+				kv.Value, changed = log.StringValue(s), true
+			}
+		}
+		attrs = append(attrs, kv)
+		return true
+	})
+	if changed {
+		r.SetAttributes(attrs...)
+	}
+	return nil
+}
+
+func (truncatingLogProcessor) Shutdown(context.Context) error   { return nil }
+func (truncatingLogProcessor) ForceFlush(context.Context) error { return nil }
@@ -0,0 +1,61 @@
+package telemetry
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in    string
+		limit int
+		want  string
+	}{
+		{"short", 8, "short"},
+		{"exactly8", 8, "exactly8"},
+		{"too long", 3, "too" + truncationMarker},
+		{"héllo", 2, "h" + truncationMarker},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, truncated := truncate(tt.in, tt.limit)
+			if got != tt.want || truncated != (got != tt.in) {
+				t.Errorf("got %q (truncated %v), want %q", got, truncated, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncatedSpan(t *testing.T) {
+	long := attribute.String("db.statement", strings.Repeat("x", 32))
+	want := strings.Repeat("x", 8) + truncationMarker
+	sr := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)).Tracer("test")
+	_, linked := tracer.Start(context.Background(), "linked")
+	_, span := tracer.Start(context.Background(), "work",
+		oteltrace.WithAttributes(long),
+		oteltrace.WithLinks(oteltrace.Link{SpanContext: linked.SpanContext(), Attributes: []attribute.KeyValue{long}}))
+	span.AddEvent("query", oteltrace.WithAttributes(long))
+	span.End()
+
+	s := truncatedSpan{ReadOnlySpan: sr.Ended()[0], limit: 8}
+	values := map[string]attribute.KeyValue{
+		"span":  s.Attributes()[0],
+		"event": s.Events()[0].Attributes[0],
+		"link":  s.Links()[0].Attributes[0],
+	}
+	for name, kv := range values {
+		if got := kv.Value.AsString(); got != want {
+			t.Errorf("%s attribute = %q, want %q", name, got, want)
+		}
+	}
+	if got := sr.Ended()[0].Links()[0].Attributes[0]; got != long {
+		t.Errorf("the recorded link was modified: %v", got)
+	}
+}
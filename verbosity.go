@@ -0,0 +1,84 @@
+package telemetry
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Verbosity selects how much of the costlier instrumentation of this package
+// runs, so that a single setting controls the overhead in production.
+type Verbosity int
+
+const (
+	// VerbosityOff disables events, body and header capture and the
+	// enrichment of spans with derived attributes. Spans, metrics and logs
+	// are still recorded.
+	VerbosityOff Verbosity = iota
+	// VerbosityBasic records the events added with AddEvent but nothing
+	// else of VerbosityDetailed.
+	VerbosityBasic
+	// VerbosityDetailed runs all the instrumentation: events, CaptureBodies,
+	// CaptureHeaders and the peer.service mapping of WithPeerServices. It
+	// is the default.
+	VerbosityDetailed
+)
+
+// verbosity holds the level installed by the setup.
+var verbosity atomic.Pointer[Verbosity]
+
+func (v Verbosity) String() string {
+	switch v {
+	case VerbosityOff:
+		return "off"
+	case VerbosityBasic:
+		return "basic"
+	case VerbosityDetailed:
+		return "detailed"
+	}
+	return fmt.Sprintf("Verbosity(%d)", int(v))
+}
+
+// ParseVerbosity parses a level name as returned by Verbosity.String.
+func ParseVerbosity(s string) (Verbosity, error) {
+	for _, v := range []Verbosity{VerbosityOff, VerbosityBasic, VerbosityDetailed} {
+		if strings.EqualFold(s, v.String()) {
+			return v, nil
+		}
+	}
+	return VerbosityDetailed, fmt.Errorf("telemetry: unknown verbosity %q", s)
+}
+
+// WithVerbosity sets the verbosity of the instrumentation, VerbosityDetailed
+// by default. It can also be set with the TELEMETRY_VERBOSITY environment
+// variable to off, basic or detailed.
+func WithVerbosity(v Verbosity) Option {
+	return func(c *config) {
+		c.verbosity = v
+	}
+}
+
+// envVerbosity reads the verbosity from the environment variable key,
+// returning VerbosityDetailed if it is unset or invalid.
+func envVerbosity(key string) Verbosity {
+	s, ok := os.LookupEnv(key)
+	if !ok {
+		return VerbosityDetailed
+	}
+	v, err := ParseVerbosity(s)
+	if err != nil {
+		otel.Handle(err)
+	}
+	return v
+}
+
+// verbose reports whether the configured verbosity is at least v.
+func verbose(v Verbosity) bool {
+	if current := verbosity.Load(); current != nil {
+		return *current >= v
+	}
+	return true
+}
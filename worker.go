@@ -0,0 +1,100 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WorkerPool instruments a queue drained by a pool of workers. It records how
+// long tasks wait in the queue, how many are queued and how many workers are
+// busy, and traces each task in a span linked to the span that produced it.
+type WorkerPool struct {
+	name   string
+	tracer trace.Tracer
+	attrs  metric.MeasurementOption
+
+	wait   metric.Float64Histogram
+	queued metric.Int64UpDownCounter
+	active metric.Int64UpDownCounter
+}
+
+// QueuedTask carries the producer's span context and enqueue time to the
+// worker that eventually processes the task.
+type QueuedTask struct {
+	link     trace.Link
+	enqueued time.Time
+}
+
+// NewWorkerPool creates the instruments for the pool called name using the
+// global tracer and meter providers.
+func NewWorkerPool(name string) (*WorkerPool, error) {
+	meter := otel.GetMeterProvider().Meter(instrumentationName)
+	p := &WorkerPool{
+		name:   name,
+		tracer: otel.Tracer(instrumentationName),
+		attrs:  metric.WithAttributes(attribute.String("pool.name", name)),
+	}
+
+	var err, e error
+	p.wait, e = meter.Float64Histogram("pool.task.wait_time",
+		metric.WithUnit("s"),
+		metric.WithDescription("Time tasks spend queued before a worker picks them up."))
+	err = errors.Join(err, e)
+	p.queued, e = meter.Int64UpDownCounter("pool.tasks.queued",
+		metric.WithUnit("{task}"),
+		metric.WithDescription("Number of tasks waiting for a worker."))
+	err = errors.Join(err, e)
+	p.active, e = meter.Int64UpDownCounter("pool.workers.active",
+		metric.WithUnit("{worker}"),
+		metric.WithDescription("Number of workers currently processing a task."))
+	err = errors.Join(err, e)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Enqueue must be called by the producer when a task is queued. The returned
+// value should travel with the task to the worker.
+func (p *WorkerPool) Enqueue(ctx context.Context) QueuedTask {
+	p.queued.Add(ctx, 1, p.attrs)
+	return QueuedTask{
+		link:     trace.LinkFromContext(ctx),
+		enqueued: time.Now(),
+	}
+}
+
+// Process runs fn for task in a new span linked to the producer span,
+// recording the queue wait time and the number of active workers.
+func (p *WorkerPool) Process(ctx context.Context, task QueuedTask, fn func(context.Context) error) error {
+	p.queued.Add(ctx, -1, p.attrs)
+	p.wait.Record(ctx, time.Since(task.enqueued).Seconds(), p.attrs)
+
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attribute.String("pool.name", p.name)),
+	}
+	if task.link.SpanContext.IsValid() {
+		opts = append(opts, trace.WithLinks(task.link))
+	}
+	ctx, span := p.tracer.Start(ctx, p.name+" process", opts...)
+	defer span.End()
+
+	p.active.Add(ctx, 1, p.attrs)
+	defer p.active.Add(ctx, -1, p.attrs)
+
+	var err error
+	profile(ctx, true, func(ctx context.Context) { err = fn(ctx) })
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}